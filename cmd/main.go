@@ -2,16 +2,53 @@ package main
 
 import (
 	"baseball_report/internal/api"
+	db "baseball_report/internal/config"
+	"baseball_report/internal/feed"
+	"baseball_report/internal/fetcher"
+	"baseball_report/internal/migrate"
 	"baseball_report/internal/scheduler"
+	"baseball_report/internal/source"
+	"baseball_report/internal/standings"
+	"baseball_report/internal/teams"
+	"baseball_report/internal/webhook"
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/robfig/cron/v3"
 )
 
+var connect db.DBHandler = &db.DBService{}
+
 func Run() error {
 
+	//スキーマが最新でなければ起動しない
+	if err := checkSchema(); err != nil {
+		return err
+	}
+
+	//チームと球場の定義をDBから読み込む
+	if err := teams.LoadFromDB(connect); err != nil {
+		return err
+	}
+
+	//取得元の設定が不正なら起動しない
+	if _, err := source.FromEnv(nil); err != nil {
+		return err
+	}
+
+	//セレクタ定義を読み込み、変更を監視する
+	if err := fetcher.StartSelectors(context.Background()); err != nil {
+		return err
+	}
+
 	//スケジューラ起動
 	location, _ := time.LoadLocation("Asia/Tokyo")
 	c := cron.New(cron.WithLocation(location))
@@ -41,9 +78,25 @@ func Run() error {
 		// 次回実行時刻を取得してログに出力
 		entry = c.Entry(id)
 		log.Printf("Cron job registered! Next scheduled run GetScore: %s (JST)", entry.Next)
+
+		id, err = scheduler.StartPlayersFetch(c)
+		if err != nil {
+			log.Println("Failed to register cron job:", err)
+			return
+		}
+
+		// 次回実行時刻を取得してログに出力
+		entry = c.Entry(id)
+		log.Printf("Cron job registered! Next scheduled run GetPlayerStats: %s (JST)", entry.Next)
 		select {}
 	}()
 
+	//試合イベントのWebhook通知を開始
+	go webhook.NewDispatcher(webhook.NewDBStore()).Run(context.Background(), feed.Default)
+
+	//試合終了ごとに順位表の試合結果を登録
+	go standings.NewUpdater().Run(context.Background(), feed.Default)
+
 	//APIルータを取得しサーバ起動
 	router := api.SetupRouter()
 	log.Println("API Server running")
@@ -52,7 +105,172 @@ func Run() error {
 	return http.ListenAndServe(":8080", router)
 }
 
+// DBのスキーマが最新か確認
+func checkSchema() error {
+	conn, err := connect.ConnectOnly()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return migrate.Check(conn)
+}
+
+// migrate up|down|status を実行
+func runMigrate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: main migrate up|down|status")
+	}
+
+	conn, err := connect.ConnectOnly()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch args[0] {
+	case "up":
+		done, err := migrate.Up(conn)
+		if err != nil {
+			return err
+		}
+		log.Println("Applied migrations:", len(done))
+	case "down":
+		m, err := migrate.Down(conn)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			log.Println("No migration to revert")
+		}
+	case "status":
+		statuses, err := migrate.GetStatus(conn)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command: %s (usage: main migrate up|down|status)", args[0])
+	}
+	return nil
+}
+
+// selectors validate schedule|score|batting|pitching <page.html> [selectors.json] を実行
+// 保存したページに対して一致しなかったセレクタを報告する（定義を省略した場合はSELECTORS_FILEまたは既定の定義）
+func runSelectors(args []string) error {
+	const usage = "usage: main selectors validate schedule|score|batting|pitching <page.html> [selectors.json]"
+	if len(args) < 3 || len(args) > 4 || args[0] != "validate" {
+		return fmt.Errorf(usage)
+	}
+	path := os.Getenv("SELECTORS_FILE")
+	if len(args) == 4 {
+		path = args[3]
+	}
+	sel, err := fetcher.LoadSelectors(path)
+	if err != nil {
+		return err
+	}
+
+	page, err := os.Open(args[2])
+	if err != nil {
+		return err
+	}
+	defer page.Close()
+	doc, err := goquery.NewDocumentFromReader(page)
+	if err != nil {
+		return fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	results, err := fetcher.ValidateSelectors(doc, sel, args[1])
+	if err != nil {
+		return err
+	}
+	missing := 0
+	for _, r := range results {
+		state := "ok"
+		switch {
+		case r.Missing():
+			state = "MISSING"
+			missing++
+		case r.Matches == 0:
+			state = "none (optional)"
+		}
+		fmt.Printf("%s\t%s\t%q\t%d\n", state, r.Name, r.Selector, r.Matches)
+	}
+	if missing != 0 {
+		return fmt.Errorf("%d selectors matched nothing", missing)
+	}
+	return nil
+}
+
+// backfill --from YYYY-MM-DD --to YYYY-MM-DD [--interval 3s] を実行
+// 過去の日程と最終スコアを取り込み、取り込んだ試合の結果を順位表に登録する（中断した場合は再実行で続きから）
+func runBackfill(args []string) error {
+	const usage = "usage: main backfill --from YYYY-MM-DD --to YYYY-MM-DD [--interval 3s]"
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	from := flags.String("from", "", "first date (YYYY-MM-DD)")
+	to := flags.String("to", "", "last date (YYYY-MM-DD)")
+	interval := flags.Duration("interval", scheduler.DefaultBackfillInterval, "interval between requests (at least 1s)")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || *from == "" || *to == "" {
+		return fmt.Errorf(usage)
+	}
+	location, _ := time.LoadLocation("Asia/Tokyo")
+	fromDate, err := time.ParseInLocation("2006-01-02", *from, location)
+	if err != nil {
+		return fmt.Errorf("invalid --from %q: %s", *from, usage)
+	}
+	toDate, err := time.ParseInLocation("2006-01-02", *to, location)
+	if err != nil {
+		return fmt.Errorf("invalid --to %q: %s", *to, usage)
+	}
+
+	if err := checkSchema(); err != nil {
+		return err
+	}
+	if err := teams.LoadFromDB(connect); err != nil {
+		return err
+	}
+	if err := fetcher.StartSelectors(context.Background()); err != nil {
+		return err
+	}
+
+	//Ctrl+Cで中断しても完了した日は記録済み
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = scheduler.Backfill(ctx, fromDate, toDate, *interval)
+
+	//サーバ起動時にも登録されるが、集計をすぐ反映するためここで登録する
+	if rerr := standings.NewUpdater().Record(0); rerr != nil {
+		log.Println(fmt.Errorf("failed to record game results: %w", rerr))
+	}
+	return err
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "selectors" {
+		if err := runSelectors(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := runBackfill(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if err := Run(); err != nil {
 
 		log.Fatal(err)
@@ -8,6 +8,8 @@ go build -o main .
 
 # mainが存在するか確認してから実行
 if [ -f "./main" ]; then
+    # スキーマを最新にしてから起動
+    ./main migrate up || exit 1
     exec ./main
 else
     echo "mainバイナリが存在しません。ビルドに失敗しました。"
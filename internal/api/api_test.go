@@ -1,11 +1,15 @@
 package api
 
 import (
+	"baseball_report/internal/models"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
 	"testing"
 	"time"
 
@@ -30,17 +34,17 @@ func (m *MockDBHandler) ConnectOnly() (*sql.DB, error) {
 func TestGetMatchesHandler_Success(t *testing.T) {
 	// 1リーグ2ゲーム
 	t.Run("Get 1league2games", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime"}).
-					AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00").
-					AddRow(2, todate, "Dodgers", "Giants", "セ・リーグ", "Dodger Stadium", "18:30")
+				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"}).
+					AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00", "scheduled", nil).
+					AddRow(2, todate, "Dodgers", "Giants", "セ・リーグ", "Dodger Stadium", "18:30", "scheduled", nil)
 
-				mock.ExpectQuery(query).WillReturnRows(rows)
+				mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnRows(rows)
 				return db, nil
 			},
 		}
@@ -55,7 +59,9 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 				"away": "Red Sox",
 				"league": "セ・リーグ",
 				"stadium": "Yankee Stadium",
-				"starttime": "19:00"
+				"starttime": "19:00",
+				"status": "scheduled",
+				"original_date": null
 			},
 			{
 				"id": 2,
@@ -64,7 +70,9 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 				"away": "Giants",
 				"league": "セ・リーグ",
 				"stadium": "Dodger Stadium",
-				"starttime": "18:30"
+				"starttime": "18:30",
+				"status": "scheduled",
+				"original_date": null
 			}
 			]
 		}`
@@ -86,19 +94,19 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 
 	// 2リーグ4ゲーム
 	t.Run("Get 2league2games", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime"}).
-					AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00").
-					AddRow(2, todate, "Dodgers", "Giants", "セ・リーグ", "Dodger Stadium", "18:30").
-					AddRow(3, todate, "SoftBank", "Rakuten", "パ・リーグ", "PayPayドーム", "18:00").
-					AddRow(4, todate, "Lotte", "Seibu", "パ・リーグ", "ZOZOマリン", "18:00")
+				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"}).
+					AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00", "scheduled", nil).
+					AddRow(2, todate, "Dodgers", "Giants", "セ・リーグ", "Dodger Stadium", "18:30", "scheduled", nil).
+					AddRow(3, todate, "SoftBank", "Rakuten", "パ・リーグ", "PayPayドーム", "18:00", "scheduled", nil).
+					AddRow(4, todate, "Lotte", "Seibu", "パ・リーグ", "ZOZOマリン", "18:00", "scheduled", nil)
 
-				mock.ExpectQuery(query).WillReturnRows(rows)
+				mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnRows(rows)
 				return db, nil
 			},
 		}
@@ -115,7 +123,9 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 				"away": "Red Sox",
 				"league": "セ・リーグ",
 				"stadium": "Yankee Stadium",
-				"starttime": "19:00"
+				"starttime": "19:00",
+				"status": "scheduled",
+				"original_date": null
 			},
 			{
 				"id": 2,
@@ -124,7 +134,9 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 				"away": "Giants",
 				"league": "セ・リーグ",
 				"stadium": "Dodger Stadium",
-				"starttime": "18:30"
+				"starttime": "18:30",
+				"status": "scheduled",
+				"original_date": null
 			}
 			],
 			"パ・リーグ": [
@@ -135,7 +147,9 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 				"away": "Rakuten",
 				"league": "パ・リーグ",
 				"stadium": "PayPayドーム",
-				"starttime": "18:00"
+				"starttime": "18:00",
+				"status": "scheduled",
+				"original_date": null
 			},
 			{
 				"id": 4,
@@ -144,7 +158,9 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 				"away": "Seibu",
 				"league": "パ・リーグ",
 				"stadium": "ZOZOマリン",
-				"starttime": "18:00"
+				"starttime": "18:00",
+				"status": "scheduled",
+				"original_date": null
 			}
 			]
 		}`
@@ -166,14 +182,14 @@ func TestGetMatchesHandler_Success(t *testing.T) {
 
 	// 1試合もない
 	t.Run("Get Nogames", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime"})
-				mock.ExpectQuery(query).WillReturnRows(rows)
+				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"})
+				mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnRows(rows)
 				return db, nil
 			},
 		}
@@ -223,13 +239,13 @@ func TestGetMatchesHandler_Failes(t *testing.T) {
 
 	// クエリ実行失敗
 	t.Run("Failed to execute query", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				mock.ExpectQuery(query).WillReturnError(errors.New("クエリエラー"))
+				mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnError(errors.New("クエリエラー"))
 				return db, nil
 			},
 		}
@@ -245,22 +261,127 @@ func TestGetMatchesHandler_Failes(t *testing.T) {
 	})
 }
 
+// GetMatchesHandler:日付・リーグ指定のパターン
+func TestGetMatchesHandler_Params(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed'")
+
+	// 日付指定
+	t.Run("Get by date", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"}).
+					AddRow(1, "2025-04-05", "ヤクルト", "中日", "セ・リーグ", "神宮", "18:00:00", "scheduled", nil)
+				mock.ExpectQuery(query+" ORDER BY").WithArgs("2025-04-05", "2025-04-05").WillReturnRows(rows)
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches?date=2025-04-05", nil)
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(GetMatchesHandler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "2025-04-05")
+	})
+
+	// 期間とリーグ指定
+	t.Run("Get by range and league", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"}).
+					AddRow(3, "2025-04-01", "ソフトバンク", "ロッテ", "パ・リーグ", "みずほPayPay", "18:00:00", "scheduled", nil).
+					AddRow(9, "2025-04-02", "ソフトバンク", "ロッテ", "パ・リーグ", "みずほPayPay", "18:00:00", "scheduled", nil)
+				mock.ExpectQuery(query+" AND league = ?").WithArgs("2025-04-01", "2025-04-07", "パ・リーグ").WillReturnRows(rows)
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches?from=2025-04-01&to=2025-04-07&league=%E3%83%91%E3%83%BB%E3%83%AA%E3%83%BC%E3%82%B0", nil)
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(GetMatchesHandler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		expected := `{
+			"パ・リーグ": [
+			{"id": 3, "date": "2025-04-01", "home": "ソフトバンク", "away": "ロッテ", "league": "パ・リーグ", "stadium": "みずほPayPay", "starttime": "18:00:00", "status": "scheduled", "original_date": null},
+			{"id": 9, "date": "2025-04-02", "home": "ソフトバンク", "away": "ロッテ", "league": "パ・リーグ", "stadium": "みずほPayPay", "starttime": "18:00:00", "status": "scheduled", "original_date": null}
+			]
+		}`
+		assert.JSONEq(t, expected, rr.Body.String(), "JSON does not match")
+	})
+
+	// 不正なパラメータは400とエラー情報を返す
+	tests := []struct {
+		name  string
+		url   string
+		code  string
+		field string
+	}{
+		{"Invalid date format", "/matches?date=2025/04/05", "invalid_parameter", "date"},
+		{"Non-existent date", "/matches?date=2025-02-30", "invalid_parameter", "date"},
+		{"Not zero padded date", "/matches?date=2025-4-5", "invalid_parameter", "date"},
+		{"Empty date", "/matches?date=", "invalid_parameter", "date"},
+		{"Date with range", "/matches?date=2025-04-05&from=2025-04-01&to=2025-04-07", "invalid_parameter", "date"},
+		{"From only", "/matches?from=2025-04-01", "invalid_parameter", "to"},
+		{"To only", "/matches?to=2025-04-01", "invalid_parameter", "from"},
+		{"Reversed range", "/matches?from=2025-04-07&to=2025-04-01", "invalid_parameter", "to"},
+		{"Too long range", "/matches?from=2025-04-01&to=2025-05-31", "invalid_parameter", "to"},
+		{"Empty league", "/matches?league=", "invalid_parameter", "league"},
+		{"Duplicate parameter", "/matches?date=2025-04-05&date=2025-04-06", "invalid_parameter", "date"},
+		{"Unknown parameter", "/matches?day=2025-04-05", "unknown_parameter", "day"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connect = &MockDBHandler{
+				MockConnectOnly: func() (*sql.DB, error) {
+					t.Fatal("database must not be accessed for invalid parameters")
+					return nil, nil
+				},
+			}
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(GetMatchesHandler).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+			var body struct {
+				Error struct {
+					Status  int    `json:"status"`
+					Code    string `json:"code"`
+					Field   string `json:"field"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, http.StatusBadRequest, body.Error.Status)
+			assert.Equal(t, tt.code, body.Error.Code)
+			assert.Equal(t, tt.field, body.Error.Field)
+			assert.NotEmpty(t, body.Error.Message)
+		})
+	}
+}
+
 // SetupRouter:正常パターン
 func TestSetupRouter_Success(t *testing.T) {
 	router := SetupRouter()
 
 	t.Run("GET /matches returns match data", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime"}).
-					AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00").
-					AddRow(2, todate, "Dodgers", "Giants", "セ・リーグ", "Dodger Stadium", "18:30")
+				rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"}).
+					AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00", "scheduled", nil).
+					AddRow(2, todate, "Dodgers", "Giants", "セ・リーグ", "Dodger Stadium", "18:30", "scheduled", nil)
 
-				mock.ExpectQuery(query).WillReturnRows(rows)
+				mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnRows(rows)
 				return db, nil
 			},
 		}
@@ -278,15 +399,15 @@ func TestSetupRouter_Success(t *testing.T) {
 	})
 
 	t.Run("GET /scores returns score data", func(t *testing.T) {
-		query := "SELECT home_score, away_score, batter, inning, result, match_id FROM scores WHERE match_id ='7'"
+		query := regexp.QuoteMeta("SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id"}).
-					AddRow("2", "1", "山田", "3回裏", "ホームラン", 7)
+				rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id", "pitcher", "balls", "strikes", "outs", "on_first", "on_second", "on_third"}).
+					AddRow("2", "1", "山田", "3回裏", "ホームラン", 7, "佐藤", 2, 1, 1, true, false, true)
 
-				mock.ExpectQuery(query).WillReturnRows(rows)
+				mock.ExpectQuery(query).WithArgs(7).WillReturnRows(rows)
 				return db, nil
 			},
 		}
@@ -317,20 +438,29 @@ func TestSetupRouter_Success(t *testing.T) {
 			t.Errorf("expected body OK, got %s", rr.Body.String())
 		}
 	})
+	t.Run("GET /metrics returns counters", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "# TYPE baseball_report_parse_failures_total counter")
+	})
 }
 
 func TestGetScoreHandler_Success(t *testing.T) {
 	// 取得成功
 	t.Run("Success get score", func(t *testing.T) {
-		query := "SELECT home_score, away_score, batter, inning, result, match_id FROM scores WHERE match_id ='7'"
+		query := regexp.QuoteMeta("SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id"}).
-					AddRow("2", "1", "山田", "3回裏", "ホームラン", 7)
+				rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id", "pitcher", "balls", "strikes", "outs", "on_first", "on_second", "on_third"}).
+					AddRow("2", "1", "山田", "3回裏", "ホームラン", 7, "佐藤", 2, 1, 1, true, false, true)
 
-				mock.ExpectQuery(query).WillReturnRows(rows)
+				mock.ExpectQuery(query).WithArgs(7).WillReturnRows(rows)
 				return db, nil
 			},
 		}
@@ -343,7 +473,12 @@ func TestGetScoreHandler_Success(t *testing.T) {
 					"batter": "山田",
 					"inning": "3回裏",
 					"result": "ホームラン",
-					"match_id": 7
+					"match_id": 7,
+					"pitcher": "佐藤",
+					"balls": 2,
+					"strikes": 1,
+					"outs": 1,
+					"bases": {"first": true, "second": false, "third": true}
 				}
 				]`
 
@@ -364,14 +499,14 @@ func TestGetScoreHandler_Success(t *testing.T) {
 	})
 
 	t.Run("Success no score", func(t *testing.T) {
-		query := "SELECT home_score, away_score, batter, inning, result, match_id FROM scores WHERE match_id ='7'"
+		query := regexp.QuoteMeta("SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id"})
+				rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id", "pitcher", "balls", "strikes", "outs", "on_first", "on_second", "on_third"})
 
-				mock.ExpectQuery(query).WillReturnRows(rows)
+				mock.ExpectQuery(query).WithArgs(7).WillReturnRows(rows)
 				return db, nil
 			},
 		}
@@ -408,6 +543,7 @@ func TestGetScoreHandler_Failes(t *testing.T) {
 		}
 
 		req, _ := http.NewRequest("GET", "/scores/7", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "7"})
 		rr := httptest.NewRecorder()
 		handler := http.HandlerFunc(GetScoreHandler)
 
@@ -419,18 +555,18 @@ func TestGetScoreHandler_Failes(t *testing.T) {
 
 	// クエリ実行失敗
 	t.Run("Failed to execute query", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		query := regexp.QuoteMeta("SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?")
 
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New()
-				mock.ExpectQuery(query).WillReturnError(errors.New("クエリエラー"))
+				mock.ExpectQuery(query).WithArgs(7).WillReturnError(errors.New("クエリエラー"))
 				return db, nil
 			},
 		}
 
 		req, _ := http.NewRequest("GET", "/scores/7", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "7"})
 		rr := httptest.NewRecorder()
 		handler := http.HandlerFunc(GetScoreHandler)
 
@@ -439,4 +575,504 @@ func TestGetScoreHandler_Failes(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 		assert.Contains(t, rr.Body.String(), "Error executing query:")
 	})
+
+	// 不正なid（SQLインジェクションを含む）はDBに問い合わせずに拒否
+	t.Run("Reject malicious id", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				t.Error("database must not be queried")
+				return nil, errors.New("unexpected")
+			},
+		}
+
+		ids := []string{
+			"7'%20OR%20'1'='1",
+			"7;DROP%20TABLE%20scores",
+			"7%20UNION%20SELECT%201,2,3,4,5,6",
+			"-1",
+			"abc",
+		}
+		for _, id := range ids {
+			req := httptest.NewRequest("GET", "/scores/"+id, nil)
+			rr := httptest.NewRecorder()
+			SetupRouter().ServeHTTP(rr, req)
+
+			//ルーターで数値以外は404となる
+			assert.Equal(t, http.StatusNotFound, rr.Code, id)
+		}
+	})
+
+	// 数値だがintに収まらないid
+	t.Run("Reject out of range id", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				t.Error("database must not be queried")
+				return nil, errors.New("unexpected")
+			},
+		}
+
+		for _, id := range []string{"0", "99999999999999999999"} {
+			req := httptest.NewRequest("GET", "/scores/"+id, nil)
+			rr := httptest.NewRecorder()
+			SetupRouter().ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, id)
+			assert.Contains(t, rr.Body.String(), `"code":"invalid_parameter"`)
+		}
+	})
+}
+
+// GetMatchHandler:試合詳細取得のパターン
+func TestGetMatchHandler(t *testing.T) {
+	query := regexp.QuoteMeta("FROM") + `\s+matches m\s+LEFT JOIN\s+scores s ON m.id = s.match_id\s+WHERE\s+m.id = \?`
+	columns := []string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}
+
+	// 試合中の試合
+	t.Run("Success get match detail", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				rows := sqlmock.NewRows(columns).
+					AddRow(7, "2025-04-06", "広島", "DeNA", "セ・リーグ", "マツダスタジアム", "13:00:00", "3回裏", "1", "2", "渡部 聖弥", "左2塁打")
+				mock.ExpectQuery(query).WithArgs(7).WillReturnRows(rows)
+				return db, nil
+			},
+		}
+
+		expected := `{
+			"id": 7,
+			"date": "2025-04-06",
+			"home": "広島",
+			"away": "DeNA",
+			"league": "セ・リーグ",
+			"stadium": "マツダスタジアム",
+			"starttime": "13:00:00",
+			"inning": "3回裏",
+			"home_score": "1",
+			"away_score": "2",
+			"batter": "渡部 聖弥",
+			"result": "左2塁打"
+		}`
+
+		req := httptest.NewRequest("GET", "/matches/7", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, expected, rr.Body.String(), "JSON does not match")
+	})
+
+	// スコア未登録の試合は空文字で返す
+	t.Run("Success get match without score", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				rows := sqlmock.NewRows(columns).
+					AddRow(8, "2025-04-06", "ヤクルト", "中日", "セ・リーグ", "神宮", "18:00:00", nil, nil, nil, nil, nil)
+				mock.ExpectQuery(query).WithArgs(8).WillReturnRows(rows)
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/8", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"inning":""`)
+	})
+
+	// 存在しない試合は404
+	t.Run("Match not found", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(query).WithArgs(99).WillReturnRows(sqlmock.NewRows(columns))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/99", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"code":"not_found"`)
+	})
+
+	// 数値以外のidはルーティングされない
+	t.Run("Non numeric id", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/matches/abc", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	// 桁あふれするidは400
+	t.Run("Overflow id", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/matches/99999999999999999999", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	// クエリ実行失敗
+	t.Run("Failed to execute query", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(query).WithArgs(7).WillReturnError(errors.New("クエリエラー"))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/7", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Error executing query:")
+	})
+}
+
+// GetMatchTimelineHandler:試合経過取得のパターン
+func TestGetMatchTimelineHandler(t *testing.T) {
+	detailQuery := `LEFT JOIN\s+scores s ON m.id = s.match_id\s+WHERE\s+m.id = \?`
+	eventsQuery := `FROM score_events\s+WHERE match_id = \?\s+ORDER BY id`
+	detailColumns := []string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}
+	eventColumns := []string{"id", "inning", "home_score", "away_score", "batter", "result", "created_at"}
+
+	t.Run("Success get timeline", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(detailQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows(detailColumns).
+					AddRow(1, "2025-04-06", "ヤクルト", "中日", "セ・リーグ", "神宮", "13:00:00", "試合終了", "1", "0", "", ""))
+				mock.ExpectQuery(eventsQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows(eventColumns).
+					AddRow(10, "1回表", "0", "0", "岡林 勇希", "", "2025-04-06 13:01:00").
+					AddRow(11, "1回表", "0", "0", "岡林 勇希", "中安", "2025-04-06 13:02:00").
+					AddRow(25, "試合終了", "1", "0", "", "", "2025-04-06 15:48:00"))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/1/timeline", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var body struct {
+			Match  map[string]interface{}   `json:"match"`
+			Events []map[string]interface{} `json:"events"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, 1.0, body.Match["id"])
+		assert.Len(t, body.Events, 3)
+		assert.Equal(t, "中安", body.Events[1]["result"])
+		assert.Equal(t, "試合終了", body.Events[2]["inning"])
+	})
+
+	t.Run("Success get empty timeline", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(detailQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows(detailColumns).
+					AddRow(2, "2025-04-07", "広島", "DeNA", "セ・リーグ", "マツダスタジアム", "18:00:00", "試合前", "0", "0", "", ""))
+				mock.ExpectQuery(eventsQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows(eventColumns))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/2/timeline", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"events":[]`)
+	})
+
+	t.Run("Match not found", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(detailQuery).WithArgs(99).WillReturnRows(sqlmock.NewRows(detailColumns))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/99/timeline", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGetMatchLineScoreHandler(t *testing.T) {
+	query := `LEFT JOIN line_scores l ON m.id = l.match_id\s+WHERE m.id = \?`
+	columns := []string{"home", "away", "side", "innings", "runs", "hits", "errors"}
+
+	t.Run("Success get line score", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("巨人", "阪神", "away", "0,3,0,0,0,0,0,0,0", "3", "5", "0").
+					AddRow("巨人", "阪神", "home", "4,0,0,0,0,0,0,0,X", "4", "6", "1"))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/1/linescore", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var body models.MatchLineScore
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, 1, body.MatchID)
+		assert.Equal(t, "阪神", body.Away.Team)
+		assert.Len(t, body.Home.Innings, 9)
+		assert.Equal(t, "X", body.Home.Innings[8])
+		assert.Equal(t, "6", body.Home.Hits)
+	})
+
+	t.Run("Match not found", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(query).WithArgs(99).WillReturnRows(sqlmock.NewRows(columns))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/matches/99/linescore", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+// 選手と成績のカラム
+var playerColumns = []string{
+	"id", "name", "team", "number", "position", "season",
+	"bat_games", "bat_plate_appearances", "bat_at_bats", "bat_hits", "bat_home_runs", "bat_rbi", "bat_stolen_bases",
+	"bat_walks", "bat_strikeouts", "bat_avg", "bat_obp", "bat_slg", "bat_ops",
+	"pit_games", "pit_wins", "pit_losses", "pit_saves", "pit_holds", "pit_innings", "pit_strikeouts",
+	"pit_walks", "pit_earned_runs", "pit_era",
+}
+
+func TestGetPlayerHandler(t *testing.T) {
+	t.Run("Success get player", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(`AND s.season = \? WHERE p.id = \?`).WithArgs(2025, 1100001).
+					WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(
+						1100001, "村上 宗隆", "ヤクルト", "55", "野手", 2025,
+						140, 600, 510, 159, 40, 110, 5, 80, 130, 0.312, 0.42, 0.6, 1.02,
+						nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/players/1100001?season=2025", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, "村上 宗隆", body["name"])
+		assert.Equal(t, 40.0, body["batting"].(map[string]interface{})["home_runs"])
+		assert.Nil(t, body["pitching"])
+	})
+
+	t.Run("Player not found", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(`WHERE p.id = \?`).WithArgs(99).WillReturnRows(sqlmock.NewRows(playerColumns))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/players/99", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"code":"not_found"`)
+	})
+
+	t.Run("Invalid season", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/players/1?season=25", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"field":"season"`)
+	})
+}
+
+func TestGetTeamPlayersHandler(t *testing.T) {
+	t.Run("Success sort by era", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(`ORDER BY s.pit_era IS NULL, s.pit_era ASC, p.id`).WithArgs(2025, "阪神").
+					WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(
+						1000001, "投手 一郎", "阪神", "11", "投手", 2025,
+						nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+						20, 8, 5, 0, 0, "108.1", 100, 30, 30, 2.5))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/teams/"+url.PathEscape("阪神")+"/players?season=2025&sort=era", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body models.TeamPlayers
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, "阪神", body.Team)
+		assert.Equal(t, "asc", body.Order)
+		if assert.Len(t, body.Players, 1) {
+			assert.Equal(t, 2.5, *body.Players[0].Pitching.ERA)
+		}
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for query, field := range map[string]string{
+			"sort=war":   "sort",
+			"order=up":   "order",
+			"season=abc": "season",
+			"limit=10":   "limit",
+		} {
+			req := httptest.NewRequest("GET", "/teams/"+url.PathEscape("阪神")+"/players?"+query, nil)
+			rr := httptest.NewRecorder()
+			SetupRouter().ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+			assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`, query)
+		}
+	})
+}
+
+// 省略時は今年の打率の高い順
+func TestParseTeamPlayersQuery(t *testing.T) {
+	cond, perr := parseTeamPlayersQuery(url.Values{}, time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local))
+	assert.Nil(t, perr)
+	assert.Equal(t, &teamPlayersQuery{Season: 2025, Sort: "avg", Order: "desc"}, cond)
+}
+
+func TestGetStandingsHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(`FROM game_results`).WithArgs("2025-01-01", "2025-04-30", "セ・リーグ", "パ・リーグ", "%交流戦%").
+					WillReturnRows(sqlmock.NewRows([]string{"match_id", "date", "team", "opponent", "home", "runs", "opponent_runs", "result"}).
+						AddRow(1, "2025-04-06", "ヤクルト", "中日", true, 3, 2, "W").
+						AddRow(1, "2025-04-06", "中日", "ヤクルト", false, 2, 3, "L"))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/standings?league="+url.QueryEscape("セ・リーグ")+"&date=2025-04-30", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body models.Standings
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, "2025-04-30", body.Date)
+		assert.Len(t, body.Standings, 1)
+		central := body.Standings["セ・リーグ"]
+		if assert.Len(t, central, 6) {
+			assert.Equal(t, "ヤクルト", central[0].Team)
+			assert.Equal(t, models.Record{Wins: 1}, central[0].Home)
+			assert.Equal(t, "中日", central[5].Team)
+			assert.Equal(t, 1.0, central[5].GamesBehind)
+		}
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for query, field := range map[string]string{
+			"league=MLB":      "league",
+			"date=2025-02-30": "date",
+			"team=abc":        "team",
+		} {
+			req := httptest.NewRequest("GET", "/standings?"+query, nil)
+			rr := httptest.NewRecorder()
+			SetupRouter().ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+			assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`, query)
+		}
+	})
+}
+
+func TestGetTeamGamesHandler(t *testing.T) {
+	t.Run("Success by alias", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(`m.date BETWEEN`).
+					WithArgs("DeNA", "横浜DeNAベイスターズ", "横浜DeNA", "横浜", "ベイスターズ",
+						"DeNA", "横浜DeNAベイスターズ", "横浜DeNA", "横浜", "ベイスターズ", "2025-01-01", "2025-12-31").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}).
+						AddRow(1, "2025-04-06", "DeNA", "中日", "セ・リーグ", "横浜", "13:00:00", "試合終了", "3", "2", "", "").
+						AddRow(2, "2025-04-07", "阪神", "DeNA", "セ・リーグ", "甲子園", "18:00:00", nil, nil, nil, nil, nil))
+				return db, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/teams/"+url.PathEscape("横浜DeNAベイスターズ")+"/games?season=2025", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body models.TeamGames
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, "baystars", body.Team.ID)
+		assert.Equal(t, 2025, body.Season)
+		assert.Equal(t, models.Record{Wins: 1}, body.Record)
+		if assert.Len(t, body.Games, 2) {
+			assert.Equal(t, "中日", body.Games[0].Opponent)
+			assert.Equal(t, models.ResultWin, *body.Games[0].Result)
+			assert.Equal(t, "阪神", body.Games[1].Opponent)
+			assert.False(t, body.Games[1].Home)
+			assert.Nil(t, body.Games[1].Result)
+		}
+	})
+
+	t.Run("Unknown team", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/teams/yankees/games", nil)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"field":"team"`)
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for query, field := range map[string]string{
+			"season=25": "season",
+			"date=2025": "date",
+		} {
+			req := httptest.NewRequest("GET", "/teams/tigers/games?"+query, nil)
+			rr := httptest.NewRecorder()
+			SetupRouter().ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+			assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`, query)
+		}
+	})
 }
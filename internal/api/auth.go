@@ -0,0 +1,27 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// 管理用APIの認証
+// Authorization: Bearer <環境変数ADMIN_TOKEN>の場合のみ通す。未設定の場合は管理用APIを使用できない
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := os.Getenv("ADMIN_TOKEN")
+		if token == "" {
+			writeError(w, http.StatusForbidden, codeForbidden, "", "admin API is disabled")
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "", "a valid admin token is required")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// エラーレスポンスの共通フォーマット
+// {"error": {"status": 400, "code": "invalid_parameter", "field": "date", "message": "..."}}
+type errorResponse struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// エラーコード
+const (
+	codeInvalidParameter = "invalid_parameter"
+	codeUnknownParameter = "unknown_parameter"
+	codeNotFound         = "not_found"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+)
+
+// エラーをJSON形式でレスポンスする
+func writeError(w http.ResponseWriter, status int, code, field, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{
+		Error: errorDetail{
+			Status:  status,
+			Code:    code,
+			Field:   field,
+			Message: message,
+		},
+	})
+}
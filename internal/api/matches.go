@@ -2,21 +2,61 @@ package api
 
 import (
 	db "baseball_report/internal/config"
+	"baseball_report/internal/models"
 	"baseball_report/internal/repository"
 	"baseball_report/utils"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strconv"
+
+	"github.com/gorilla/mux"
 
 	"net/http"
+	"net/url"
 	"time"
 )
 
 var connect db.DBHandler = &db.DBService{}
 
-// 当日の試合情報を取得、JSON形式でレスポンスする
+// 日付パラメータのフォーマット
+const dateLayout = "2006-01-02"
+
+// from/toで指定できる最大日数
+const maxRangeDays = 31
+
+// leagueパラメータの最大長（matches.leagueの桁数に合わせる）
+const maxLeagueLength = 50
+
+// /matchesで受け付けるクエリパラメータ
+var matchesParams = map[string]bool{"date": true, "from": true, "to": true, "league": true}
+
+// /matchesの検索条件
+type matchesQuery struct {
+	From   string
+	To     string
+	League string
+}
+
+// パラメータエラー
+type paramError struct {
+	Code    string
+	Field   string
+	Message string
+}
+
+// 試合情報を取得、JSON形式でレスポンスする
+// ?date=YYYY-MM-DD または ?from=YYYY-MM-DD&to=YYYY-MM-DD で日付を指定（省略時は当日）
+// ?league= でリーグを絞り込み
 func GetMatchesHandler(w http.ResponseWriter, r *http.Request) {
 
-	todate := time.Now().Format("2006/01/02")
+	cond, perr := parseMatchesQuery(r, time.Now())
+	if perr != nil {
+		writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+		return
+	}
+
 	db, err := connect.ConnectOnly()
 	if err != nil {
 		http.Error(w, "Database connection error", http.StatusInternalServerError)
@@ -28,7 +68,7 @@ func GetMatchesHandler(w http.ResponseWriter, r *http.Request) {
 	//試合情報を取得
 	repo := &repository.DefaultRepository{}
 
-	matches, err := repo.GetMatchAPI(db, todate)
+	matches, err := repo.GetMatchAPI(db, cond.From, cond.To, cond.League)
 	if err != nil {
 		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -37,7 +77,7 @@ func GetMatchesHandler(w http.ResponseWriter, r *http.Request) {
 
 	if len(matches) != 0 {
 		//リーグをヘッダーとしたJSON形式に変換
-		result, err := utils.ConvertToJSON(matches, "league")
+		result, err := utils.ConvertToJSON(matches, func(m models.Match) string { return m.League })
 		if err != nil {
 			http.Error(w, "Error converting to JSON: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -52,3 +92,191 @@ func GetMatchesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 }
+
+// 試合情報と試合進捗をまとめた試合詳細を取得、JSON形式でレスポンスする
+func GetMatchHandler(w http.ResponseWriter, r *http.Request) {
+	//パスパラメータを取得
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "id", "id must be a positive integer")
+		return
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+
+	match, err := repo.GetMatchDetail(db, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, codeNotFound, "id", fmt.Sprintf("match %d not found", id))
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
+}
+
+// 試合経過（試合進捗の変化の履歴）を取得、JSON形式でレスポンスする
+func GetMatchTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "id", "id must be a positive integer")
+		return
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+
+	match, err := repo.GetMatchDetail(db, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, codeNotFound, "id", fmt.Sprintf("match %d not found", id))
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	events, err := repo.GetScoreEvents(db, id)
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"match":  match,
+		"events": events,
+	})
+}
+
+// 試合のラインスコア（イニングごとの得点と安打・失策）を取得、JSON形式でレスポンスする
+func GetMatchLineScoreHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "id", "id must be a positive integer")
+		return
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+
+	lineScore, err := repo.GetLineScore(db, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, codeNotFound, "id", fmt.Sprintf("match %d not found", id))
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lineScore)
+}
+
+// クエリパラメータを検証し検索条件に変換する
+func parseMatchesQuery(r *http.Request, now time.Time) (*matchesQuery, *paramError) {
+	values := r.URL.Query()
+
+	if perr := checkParams(values, matchesParams); perr != nil {
+		return nil, perr
+	}
+
+	cond := &matchesQuery{League: values.Get("league")}
+	if values.Has("league") && (cond.League == "" || len([]rune(cond.League)) > maxLeagueLength) {
+		return nil, &paramError{codeInvalidParameter, "league", fmt.Sprintf("league must be 1 to %d characters", maxLeagueLength)}
+	}
+
+	_, hasDate := values["date"]
+	_, hasFrom := values["from"]
+	_, hasTo := values["to"]
+
+	switch {
+	case hasDate && (hasFrom || hasTo):
+		return nil, &paramError{codeInvalidParameter, "date", "date cannot be combined with from/to"}
+
+	case hasDate:
+		date, perr := parseDateParam(values.Get("date"), "date")
+		if perr != nil {
+			return nil, perr
+		}
+		cond.From, cond.To = date.Format(dateLayout), date.Format(dateLayout)
+
+	case hasFrom || hasTo:
+		if !hasFrom || !hasTo {
+			field := "from"
+			if hasFrom {
+				field = "to"
+			}
+			return nil, &paramError{codeInvalidParameter, field, "from and to must be specified together"}
+		}
+		from, perr := parseDateParam(values.Get("from"), "from")
+		if perr != nil {
+			return nil, perr
+		}
+		to, perr := parseDateParam(values.Get("to"), "to")
+		if perr != nil {
+			return nil, perr
+		}
+		if to.Before(from) {
+			return nil, &paramError{codeInvalidParameter, "to", "to must be on or after from"}
+		}
+		if to.Sub(from) >= maxRangeDays*24*time.Hour {
+			return nil, &paramError{codeInvalidParameter, "to", fmt.Sprintf("date range must be %d days or less", maxRangeDays)}
+		}
+		cond.From, cond.To = from.Format(dateLayout), to.Format(dateLayout)
+
+	default:
+		//指定が無い場合は当日
+		cond.From, cond.To = now.Format(dateLayout), now.Format(dateLayout)
+	}
+
+	return cond, nil
+}
+
+// 未定義・重複したパラメータは受け付けない
+func checkParams(values url.Values, allowed map[string]bool) *paramError {
+	for key, v := range values {
+		if !allowed[key] {
+			return &paramError{codeUnknownParameter, key, fmt.Sprintf("unknown parameter '%s'", key)}
+		}
+		if len(v) > 1 {
+			return &paramError{codeInvalidParameter, key, fmt.Sprintf("parameter '%s' must be specified only once", key)}
+		}
+	}
+	return nil
+}
+
+// YYYY-MM-DD形式の日付を厳密に解析する
+func parseDateParam(value, field string) (time.Time, *paramError) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil || date.Format(dateLayout) != value {
+		return time.Time{}, &paramError{codeInvalidParameter, field, fmt.Sprintf("%s must be a valid date in YYYY-MM-DD format", field)}
+	}
+	return date, nil
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"baseball_report/internal/teams"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// 選手一覧の既定の並び順
+const defaultPlayerSort = "avg"
+
+// teamの最大長（players.teamの桁数に合わせる）
+const maxTeamLength = 50
+
+// 小さいほど良い成績（orderの省略時は昇順）
+var ascendingSorts = map[string]bool{"era": true}
+
+// /players/{id}で受け付けるクエリパラメータ
+var playerParams = map[string]bool{"season": true}
+
+// /teams/{team}/playersで受け付けるクエリパラメータ
+var teamPlayersParams = map[string]bool{"season": true, "sort": true, "order": true}
+
+// 選手情報とシーズン成績を取得、JSON形式でレスポンスする
+// ?season=YYYY でシーズンを指定（省略時は最新のシーズン）
+func GetPlayerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "id", "id must be a positive integer")
+		return
+	}
+	values := r.URL.Query()
+	if perr := checkParams(values, playerParams); perr != nil {
+		writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+		return
+	}
+	season := 0
+	if values.Has("season") {
+		var perr *paramError
+		if season, perr = parseSeasonParam(values.Get("season")); perr != nil {
+			writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+			return
+		}
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+
+	player, err := repo.GetPlayer(db, id, season)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, codeNotFound, "id", fmt.Sprintf("player %d not found", id))
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(player)
+}
+
+// チームの選手とシーズン成績を成績の順に取得、JSON形式でレスポンスする
+// teamはチームIDや正式名称でも指定できる
+// ?season=YYYY でシーズン（省略時は今年）、?sort= で並べる成績（省略時はavg）、?order=asc|desc で並び順を指定
+func GetTeamPlayersHandler(w http.ResponseWriter, r *http.Request) {
+	team := mux.Vars(r)["team"]
+	if team == "" || len([]rune(team)) > maxTeamLength {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "team", fmt.Sprintf("team must be 1 to %d characters", maxTeamLength))
+		return
+	}
+	team = teams.Canonical(team)
+	cond, perr := parseTeamPlayersQuery(r.URL.Query(), time.Now())
+	if perr != nil {
+		writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+		return
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+
+	players, err := repo.GetTeamPlayers(db, team, cond.Season, cond.Sort, cond.Order == "desc")
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TeamPlayers{
+		Team:    team,
+		Season:  cond.Season,
+		Sort:    cond.Sort,
+		Order:   cond.Order,
+		Players: players,
+	})
+}
+
+// /teams/{team}/playersの検索条件
+type teamPlayersQuery struct {
+	Season int
+	Sort   string
+	Order  string
+}
+
+// クエリパラメータを検証し検索条件に変換する
+func parseTeamPlayersQuery(values url.Values, now time.Time) (*teamPlayersQuery, *paramError) {
+	if perr := checkParams(values, teamPlayersParams); perr != nil {
+		return nil, perr
+	}
+
+	cond := &teamPlayersQuery{Season: now.Year(), Sort: defaultPlayerSort}
+	if values.Has("season") {
+		season, perr := parseSeasonParam(values.Get("season"))
+		if perr != nil {
+			return nil, perr
+		}
+		cond.Season = season
+	}
+	if values.Has("sort") {
+		cond.Sort = values.Get("sort")
+		if _, ok := repository.PlayerSortColumns[cond.Sort]; !ok {
+			keys := make([]string, 0, len(repository.PlayerSortColumns))
+			for key := range repository.PlayerSortColumns {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			return nil, &paramError{codeInvalidParameter, "sort", fmt.Sprintf("sort must be one of %s", strings.Join(keys, ", "))}
+		}
+	}
+
+	cond.Order = "desc"
+	if ascendingSorts[cond.Sort] {
+		cond.Order = "asc"
+	}
+	if values.Has("order") {
+		cond.Order = values.Get("order")
+		if cond.Order != "asc" && cond.Order != "desc" {
+			return nil, &paramError{codeInvalidParameter, "order", "order must be asc or desc"}
+		}
+	}
+	return cond, nil
+}
+
+// YYYY形式のシーズンを解析する
+func parseSeasonParam(value string) (int, *paramError) {
+	season, err := strconv.Atoi(value)
+	if err != nil || len(value) != 4 || season < 1936 {
+		return 0, &paramError{codeInvalidParameter, "season", "season must be a year in YYYY format (1936 or later)"}
+	}
+	return season, nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"baseball_report/internal/metrics"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -11,7 +12,23 @@ func SetupRouter() *mux.Router {
 
 	//エンドポイントを設定
 	r.HandleFunc("/matches", GetMatchesHandler).Methods("GET")
-	r.HandleFunc("/scores/{id}", GetScoreHandler).Methods("GET")
+	r.HandleFunc("/matches/{id:[0-9]+}", GetMatchHandler).Methods("GET")
+	r.HandleFunc("/matches/{id:[0-9]+}/timeline", GetMatchTimelineHandler).Methods("GET")
+	r.HandleFunc("/matches/{id:[0-9]+}/linescore", GetMatchLineScoreHandler).Methods("GET")
+	r.HandleFunc("/scores/{id:[0-9]+}", GetScoreHandler).Methods("GET")
+	r.HandleFunc("/players/{id:[0-9]+}", GetPlayerHandler).Methods("GET")
+	r.HandleFunc("/teams/{team}/players", GetTeamPlayersHandler).Methods("GET")
+	r.HandleFunc("/teams/{team}/games", GetTeamGamesHandler).Methods("GET")
+	r.HandleFunc("/standings", GetStandingsHandler).Methods("GET")
+	r.HandleFunc("/stream/scores", StreamScoresHandler).Methods("GET")
+	r.HandleFunc("/ws/scores", ScoresWebSocketHandler).Methods("GET")
+	//Webhookの管理は管理用トークンが必要
+	r.HandleFunc("/webhooks", requireAdmin(CreateWebhookHandler)).Methods("POST")
+	r.HandleFunc("/webhooks", requireAdmin(GetWebhooksHandler)).Methods("GET")
+	r.HandleFunc("/webhooks/{id:[0-9]+}", requireAdmin(DeleteWebhookHandler)).Methods("DELETE")
+
+	//解析の失敗件数などのメトリクス
+	r.HandleFunc("/metrics", metrics.Handler).Methods("GET")
 
 	//ヘルスチェックも追加
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -23,6 +40,6 @@ func SetupRouter() *mux.Router {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
-	
+
 	return r
 }
@@ -3,6 +3,7 @@ package api
 import (
 	"baseball_report/internal/repository"
 	"encoding/json"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
@@ -11,8 +12,11 @@ import (
 
 func GetScoreHandler(w http.ResponseWriter, r *http.Request) {
 	//パスパラメータを取得
-	vars := mux.Vars(r)
-	id := vars["id"]
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "id", "id must be a positive integer")
+		return
+	}
 
 	//DB接続
 	db, err := connect.ConnectOnly()
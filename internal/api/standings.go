@@ -0,0 +1,63 @@
+package api
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"baseball_report/internal/standings"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// /standingsで受け付けるクエリパラメータ
+var standingsParams = map[string]bool{"league": true, "date": true}
+
+// リーグごとの順位表を取得、JSON形式でレスポンスする
+// ?league= でリーグ（省略時はすべて）、?date=YYYY-MM-DD でその日までのシーズン成績（省略時は今日）を指定
+func GetStandingsHandler(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	if perr := checkParams(values, standingsParams); perr != nil {
+		writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+		return
+	}
+	league := values.Get("league")
+	if values.Has("league") && !standings.IsLeague(league) {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "league", fmt.Sprintf("league must be one of %s", strings.Join(standings.Leagues(), ", ")))
+		return
+	}
+	date := time.Now()
+	if values.Has("date") {
+		var perr *paramError
+		if date, perr = parseDateParam(values.Get("date"), "date"); perr != nil {
+			writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+			return
+		}
+	}
+	//シーズンはその年の1月1日から
+	from := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, date.Location())
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+
+	results, err := repo.GetGameResults(db, from.Format(dateLayout), date.Format(dateLayout), standings.RegularSeason())
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.Standings{
+		Date:      date.Format(dateLayout),
+		Standings: standings.Compute(results, league),
+	})
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"baseball_report/internal/feed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 試合進捗の配信元
+var broker = feed.Default
+
+// ハートビートの送信間隔
+const heartbeatInterval = 15 * time.Second
+
+// 購読者ごとのバッファ件数
+const streamBuffer = 64
+
+// /stream/scoresで受け付けるクエリパラメータ
+var streamParams = map[string]bool{"match_id": true, "league": true, "last_event_id": true}
+
+// 配信対象の絞り込み条件
+type streamFilter struct {
+	MatchIDs map[int]bool
+	League   string
+}
+
+// 条件に一致するイベントか判定
+func (f *streamFilter) match(ev feed.ScoreEvent) bool {
+	if len(f.MatchIDs) != 0 && !f.MatchIDs[ev.MatchID] {
+		return false
+	}
+	if f.League != "" && f.League != ev.League {
+		return false
+	}
+	return true
+}
+
+// 試合進捗の変化をServer-Sent Eventsで配信する
+// ?match_id=1,2 で試合、?league= でリーグを絞り込み
+// Last-Event-IDヘッダ（または?last_event_id=）以降の保持中イベントから再開できる
+// 以降のイベントが履歴から消えている場合はresetイベントを送り、クライアントに取得し直させる
+func StreamScoresHandler(w http.ResponseWriter, r *http.Request) {
+	streamScores(w, r, broker, heartbeatInterval)
+}
+
+func streamScores(w http.ResponseWriter, r *http.Request, b *feed.Broker, heartbeatEvery time.Duration) {
+	filter, lastID, perr := parseStreamQuery(r)
+	if perr != nil {
+		writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, missed, latest, complete := b.Resume(lastID, streamBuffer)
+	defer b.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	//再接続までの待機時間を指示
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	if complete {
+		//切断中に発生したイベントを再送
+		for _, ev := range missed {
+			if filter.match(ev) {
+				if err := writeEvent(w, ev); err != nil {
+					return
+				}
+			}
+		}
+	} else {
+		//切断中のイベントが履歴から消えている。クライアントは現在の試合進捗を取得し直す
+		if err := writeReset(w, latest); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatEvery)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				//受信が追いつかず切断された。クライアントはLast-Event-IDで再開する
+				log.Println("stream subscriber closed: too slow")
+				return
+			}
+			if !filter.match(ev) {
+				continue
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// イベントをSSE形式で書き込む
+func writeEvent(w http.ResponseWriter, ev feed.ScoreEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: score\ndata: %s\n\n", ev.ID, data)
+	return err
+}
+
+// 取りこぼしがあるため全件の取得し直しが必要なことをSSE形式で書き込む
+// IDを購読開始時点の最新にし、次の再接続ではここから再開させる
+func writeReset(w http.ResponseWriter, latest uint64) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {\"last_event_id\":%d}\n\n", latest, latest)
+	return err
+}
+
+// クエリパラメータを検証し配信条件に変換する
+func parseStreamQuery(r *http.Request) (*streamFilter, uint64, *paramError) {
+	values := r.URL.Query()
+	for key := range values {
+		if !streamParams[key] {
+			return nil, 0, &paramError{codeUnknownParameter, key, fmt.Sprintf("unknown parameter '%s'", key)}
+		}
+	}
+
+	filter := &streamFilter{MatchIDs: map[int]bool{}}
+	for _, v := range values["match_id"] {
+		for _, s := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(s)
+			if err != nil || id <= 0 {
+				return nil, 0, &paramError{codeInvalidParameter, "match_id", "match_id must be a comma separated list of positive integers"}
+			}
+			filter.MatchIDs[id] = true
+		}
+	}
+
+	if values.Has("league") {
+		filter.League = values.Get("league")
+		if filter.League == "" || len([]rune(filter.League)) > maxLeagueLength {
+			return nil, 0, &paramError{codeInvalidParameter, "league", fmt.Sprintf("league must be 1 to %d characters", maxLeagueLength)}
+		}
+	}
+
+	//再接続時はブラウザがLast-Event-IDヘッダを送る
+	last := r.Header.Get("Last-Event-ID")
+	field := "Last-Event-ID"
+	if last == "" {
+		last = values.Get("last_event_id")
+		field = "last_event_id"
+	}
+	var lastID uint64
+	if last != "" {
+		id, err := strconv.ParseUint(last, 10, 64)
+		if err != nil {
+			return nil, 0, &paramError{codeInvalidParameter, field, "last event id must be a non-negative integer"}
+		}
+		lastID = id
+	}
+
+	return filter, lastID, nil
+}
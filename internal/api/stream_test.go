@@ -0,0 +1,151 @@
+package api
+
+import (
+	"baseball_report/internal/feed"
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// SSEの1イベント分（空行まで）を読み取る
+func readSSE(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+// ストリームに接続する
+func openStream(t *testing.T, ctx context.Context, url string, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect stream: %v", err)
+	}
+	reader := bufio.NewReader(res.Body)
+	// retry指示を読み飛ばす
+	assert.Equal(t, []string{"retry: 3000"}, readSSE(t, reader))
+	return res, reader
+}
+
+// ブローカーを差し替えたテスト用サーバを起動する
+func newStreamServer(b *feed.Broker, heartbeat time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streamScores(w, r, b, heartbeat)
+	}))
+}
+
+func TestStreamScoresHandler(t *testing.T) {
+	t.Run("Stream filtered events", func(t *testing.T) {
+		b := feed.NewBroker(10)
+		server := newStreamServer(b, heartbeatInterval)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		res, reader := openStream(t, ctx, server.URL+"/stream/scores?match_id=2", "")
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		// retry指示を受信した時点で購読済み
+		b.Publish(feed.ScoreEvent{MatchID: 1, Score: feed.ScoreState{HomeScore: "1"}})
+		ev := b.Publish(feed.ScoreEvent{MatchID: 2, Score: feed.ScoreState{HomeScore: "3"}, Changed: []string{"home_score"}})
+
+		lines := readSSE(t, reader)
+		assert.Equal(t, "id: "+strconv.FormatUint(ev.ID, 10), lines[0])
+		assert.Equal(t, "event: score", lines[1])
+		assert.Contains(t, lines[2], `"match_id":2`)
+		assert.Contains(t, lines[2], `"home_score":"3"`)
+	})
+
+	t.Run("Resume from Last-Event-ID", func(t *testing.T) {
+		b := feed.NewBroker(10)
+		server := newStreamServer(b, heartbeatInterval)
+		defer server.Close()
+		ev1 := b.Publish(feed.ScoreEvent{MatchID: 1, League: "セ・リーグ"})
+		ev2 := b.Publish(feed.ScoreEvent{MatchID: 2, League: "パ・リーグ"})
+		ev3 := b.Publish(feed.ScoreEvent{MatchID: 3, League: "セ・リーグ"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		res, reader := openStream(t, ctx, server.URL+"/stream/scores?league=%E3%82%BB%E3%83%BB%E3%83%AA%E3%83%BC%E3%82%B0", strconv.FormatUint(ev1.ID, 10))
+		defer res.Body.Close()
+
+		// ev2はリーグが異なるため再送されない
+		lines := readSSE(t, reader)
+		assert.NotEqual(t, "id: "+strconv.FormatUint(ev2.ID, 10), lines[0])
+		assert.Equal(t, "id: "+strconv.FormatUint(ev3.ID, 10), lines[0])
+	})
+
+	//Last-Event-ID以降のイベントが履歴から消えている場合はresetを送り、以降のイベントを配信する
+	t.Run("Reset when history is expired", func(t *testing.T) {
+		b := feed.NewBroker(2)
+		server := newStreamServer(b, heartbeatInterval)
+		defer server.Close()
+		ev1 := b.Publish(feed.ScoreEvent{MatchID: 1})
+		b.Publish(feed.ScoreEvent{MatchID: 2})
+		b.Publish(feed.ScoreEvent{MatchID: 3})
+		ev4 := b.Publish(feed.ScoreEvent{MatchID: 4})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		res, reader := openStream(t, ctx, server.URL+"/stream/scores", strconv.FormatUint(ev1.ID, 10))
+		defer res.Body.Close()
+
+		id := strconv.FormatUint(ev4.ID, 10)
+		assert.Equal(t, []string{"id: " + id, "event: reset", `data: {"last_event_id":` + id + `}`}, readSSE(t, reader))
+
+		ev5 := b.Publish(feed.ScoreEvent{MatchID: 5})
+		lines := readSSE(t, reader)
+		assert.Equal(t, "id: "+strconv.FormatUint(ev5.ID, 10), lines[0])
+	})
+
+	t.Run("Heartbeat", func(t *testing.T) {
+		server := newStreamServer(feed.NewBroker(10), 20*time.Millisecond)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		res, reader := openStream(t, ctx, server.URL+"/stream/scores", "")
+		defer res.Body.Close()
+
+		assert.Equal(t, []string{": heartbeat"}, readSSE(t, reader))
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for _, url := range []string{
+			"/stream/scores?match_id=abc",
+			"/stream/scores?match_id=1,-2",
+			"/stream/scores?league=",
+			"/stream/scores?last_event_id=x",
+			"/stream/scores?id=1",
+		} {
+			req := httptest.NewRequest("GET", url, nil)
+			rr := httptest.NewRecorder()
+			StreamScoresHandler(rr, req)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+		}
+	})
+}
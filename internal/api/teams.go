@@ -0,0 +1,80 @@
+package api
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"baseball_report/internal/standings"
+	"baseball_report/internal/teams"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// /teams/{team}/gamesで受け付けるクエリパラメータ
+var teamGamesParams = map[string]bool{"season": true}
+
+// チームのシーズンの試合日程と結果を日付順に取得、JSON形式でレスポンスする
+// teamはチームID・チーム名・正式名称などで指定する（例: baystars、DeNA、横浜DeNAベイスターズ）
+// ?season=YYYY でシーズンを指定（省略時は今年）
+func GetTeamGamesHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["team"]
+	if name == "" || len([]rune(name)) > maxTeamLength {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "team", fmt.Sprintf("team must be 1 to %d characters", maxTeamLength))
+		return
+	}
+	values := r.URL.Query()
+	if perr := checkParams(values, teamGamesParams); perr != nil {
+		writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+		return
+	}
+	season := time.Now().Year()
+	if values.Has("season") {
+		var perr *paramError
+		if season, perr = parseSeasonParam(values.Get("season")); perr != nil {
+			writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+			return
+		}
+	}
+	team, ok := teams.Lookup(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, codeNotFound, "team", fmt.Sprintf("team '%s' not found", name))
+		return
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+
+	//日程に別表記で登録された試合も含める
+	names := append([]string{team.Name}, team.Aliases...)
+	details, err := repo.GetTeamGames(db, names, fmt.Sprintf("%d-01-01", season), fmt.Sprintf("%d-12-31", season))
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	games := []models.TeamGame{}
+	for _, detail := range details {
+		if game, ok := standings.TeamGame(detail, team.Name); ok {
+			games = append(games, game)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TeamGames{
+		Team:   team,
+		Season: season,
+		Record: standings.Total(games),
+		Games:  games,
+	})
+}
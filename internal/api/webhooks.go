@@ -0,0 +1,187 @@
+package api
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"baseball_report/internal/webhook"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Webhook登録リクエスト
+type createWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// Webhook登録レスポンス
+// 署名用のシークレットは登録時のみ返す
+type createWebhookResponse struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// 登録リクエストの最大サイズ
+const maxWebhookRequestSize = 16 * 1024
+
+// 通知先のアドレスの確認（テストで差し替えられるよう変数にする）
+var checkWebhookURL = webhook.CheckURL
+
+// 試合イベントの通知先を登録する
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req createWebhookRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "", "request body must be a JSON object: "+err.Error())
+		return
+	}
+
+	if perr := validateWebhookRequest(&req); perr != nil {
+		writeError(w, http.StatusBadRequest, perr.Code, perr.Field, perr.Message)
+		return
+	}
+	//内部ネットワークへの送信に使われないよう、通知先のアドレスを確認する
+	if err := checkWebhookURL(r.Context(), req.URL); err != nil {
+		msg := "url host could not be resolved"
+		if errors.Is(err, webhook.ErrBlockedAddress) {
+			msg = "url must not resolve to a loopback, private or link-local address"
+		}
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "url", msg)
+		return
+	}
+
+	//シークレット未指定の場合は生成する
+	if req.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			http.Error(w, "Failed to generate secret", http.StatusInternalServerError)
+			return
+		}
+		req.Secret = secret
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+	hook := models.Webhook{URL: req.URL, Secret: req.Secret, Events: req.Events}
+	id, err := repo.InsertWebhook(db, hook)
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	hook.ID = id
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/webhooks/%d", id))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createWebhookResponse{Webhook: hook, Secret: hook.Secret})
+}
+
+// 登録済みの通知先一覧を取得する
+func GetWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+	hooks, err := repo.GetWebhooks(db)
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if hooks == nil {
+		hooks = []models.Webhook{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// 通知先を削除する
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, codeInvalidParameter, "id", "id must be a positive integer")
+		return
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		http.Error(w, "Database connection error", http.StatusInternalServerError)
+		log.Println("Database connection error: " + err.Error())
+		return
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+	err = repo.DeleteWebhook(db, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusNotFound, codeNotFound, "id", fmt.Sprintf("webhook %d not found", id))
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error executing query: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// 登録リクエストを検証する
+func validateWebhookRequest(req *createWebhookRequest) *paramError {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &paramError{codeInvalidParameter, "url", "url must be an absolute http or https URL"}
+	}
+	if len(req.URL) > 255 {
+		return &paramError{codeInvalidParameter, "url", "url must be 255 characters or less"}
+	}
+
+	//重複を除いたイベント一覧（未指定は全イベント）
+	seen := map[string]bool{}
+	events := []string{}
+	for _, ev := range req.Events {
+		if !webhook.IsEvent(ev) {
+			return &paramError{codeInvalidParameter, "events", fmt.Sprintf("unknown event '%s'", ev)}
+		}
+		if !seen[ev] {
+			seen[ev] = true
+			events = append(events, ev)
+		}
+	}
+	req.Events = events
+
+	if req.Secret != "" && (len(req.Secret) < 16 || len(req.Secret) > 128) {
+		return &paramError{codeInvalidParameter, "secret", "secret must be 16 to 128 characters"}
+	}
+	return nil
+}
+
+// 署名用のシークレットを生成する
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"baseball_report/internal/webhook"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// 管理用トークンを設定し、通知先の名前解決をモック化する
+func setupWebhookTest(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "admin-token")
+	checkWebhookURL = func(ctx context.Context, rawURL string) error {
+		if strings.Contains(rawURL, "internal") {
+			return fmt.Errorf("%w: internal", webhook.ErrBlockedAddress)
+		}
+		return nil
+	}
+	t.Cleanup(func() { checkWebhookURL = webhook.CheckURL })
+}
+
+// 管理用トークン付きのリクエスト
+func newAdminRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	return req
+}
+
+func TestWebhooksAuth(t *testing.T) {
+	t.Run("Disabled without ADMIN_TOKEN", func(t *testing.T) {
+		t.Setenv("ADMIN_TOKEN", "")
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, newAdminRequest("GET", "/webhooks", ""))
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	for _, auth := range []string{"", "Bearer wrong-token", "admin-token"} {
+		t.Run("Unauthorized "+auth, func(t *testing.T) {
+			setupWebhookTest(t)
+			for _, req := range []*http.Request{
+				httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{"url": "https://hooks.example.com/bb"}`)),
+				httptest.NewRequest("GET", "/webhooks", nil),
+				httptest.NewRequest("DELETE", "/webhooks/1", nil),
+			} {
+				req.Header.Set("Authorization", auth)
+				rr := httptest.NewRecorder()
+				SetupRouter().ServeHTTP(rr, req)
+				assert.Equal(t, http.StatusUnauthorized, rr.Code, req.Method)
+				assert.Contains(t, rr.Body.String(), `"code":"unauthorized"`)
+			}
+		})
+	}
+}
+
+func TestCreateWebhookHandler(t *testing.T) {
+	setupWebhookTest(t)
+	query := regexp.QuoteMeta("INSERT INTO webhooks (url, secret, events) VALUES (?, ?, ?)")
+
+	t.Run("Success with generated secret", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectExec(query).
+					WithArgs("https://hooks.example.com/bb", sqlmock.AnyArg(), "game_end,score_change").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				return db, nil
+			},
+		}
+
+		body := `{"url": "https://hooks.example.com/bb", "events": ["game_end", "score_change", "game_end"]}`
+		req := newAdminRequest("POST", "/webhooks", body)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, "/webhooks/3", rr.Header().Get("Location"))
+
+		var res map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		assert.Equal(t, 3.0, res["id"])
+		assert.Equal(t, []interface{}{"game_end", "score_change"}, res["events"])
+		assert.Len(t, res["secret"], 64)
+	})
+
+	t.Run("Success with all events and own secret", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectExec(query).
+					WithArgs("http://hooks.example.com:9000/hook", "0123456789abcdef", "").
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				return db, nil
+			},
+		}
+
+		body := `{"url": "http://hooks.example.com:9000/hook", "secret": "0123456789abcdef"}`
+		req := newAdminRequest("POST", "/webhooks", body)
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"secret":"0123456789abcdef"`)
+	})
+
+	// 不正なリクエストは400
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"Invalid JSON", `{"url":`, ""},
+		{"Unknown field", `{"url": "https://example.com", "event": ["game_end"]}`, ""},
+		{"Relative URL", `{"url": "/hook"}`, "url"},
+		{"Unsupported scheme", `{"url": "ftp://example.com/hook"}`, "url"},
+		{"Unknown event", `{"url": "https://example.com", "events": ["homerun"]}`, "events"},
+		{"Short secret", `{"url": "https://example.com", "secret": "abc"}`, "secret"},
+		{"Internal address", `{"url": "http://internal.example.com/hook"}`, "url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newAdminRequest("POST", "/webhooks", tt.body)
+			rr := httptest.NewRecorder()
+			SetupRouter().ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			if tt.field != "" {
+				assert.Contains(t, rr.Body.String(), `"field":"`+tt.field+`"`)
+			}
+		})
+	}
+}
+
+func TestGetWebhooksHandler(t *testing.T) {
+	setupWebhookTest(t)
+	connect = &MockDBHandler{
+		MockConnectOnly: func() (*sql.DB, error) {
+			db, mock, _ := sqlmock.New()
+			rows := sqlmock.NewRows([]string{"id", "url", "secret", "events", "created_at"}).
+				AddRow(1, "https://example.com/a", "secret-a", "game_end", "2025-04-06 12:00:00").
+				AddRow(2, "https://example.com/b", "secret-b", "", "2025-04-06 12:30:00")
+			mock.ExpectQuery("SELECT id, url, secret, events, created_at FROM webhooks").WillReturnRows(rows)
+			return db, nil
+		},
+	}
+
+	req := newAdminRequest("GET", "/webhooks", "")
+	rr := httptest.NewRecorder()
+	SetupRouter().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	expected := `[
+		{"id": 1, "url": "https://example.com/a", "events": ["game_end"], "created_at": "2025-04-06 12:00:00"},
+		{"id": 2, "url": "https://example.com/b", "events": [], "created_at": "2025-04-06 12:30:00"}
+	]`
+	assert.JSONEq(t, expected, rr.Body.String())
+	// シークレットは一覧に含めない
+	assert.NotContains(t, rr.Body.String(), "secret-a")
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	setupWebhookTest(t)
+	query := regexp.QuoteMeta("UPDATE webhooks SET active = FALSE WHERE id = ? AND active = TRUE")
+
+	t.Run("Success", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectExec(query).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				return db, nil
+			},
+		}
+
+		req := newAdminRequest("DELETE", "/webhooks/1", "")
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectExec(query).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+				return db, nil
+			},
+		}
+
+		req := newAdminRequest("DELETE", "/webhooks/9", "")
+		rr := httptest.NewRecorder()
+		SetupRouter().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"baseball_report/internal/feed"
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 1メッセージの書き込み待ち上限
+	wsWriteWait = 10 * time.Second
+	// クライアントからのpong待ち上限
+	wsPongWait = 60 * time.Second
+	// pingの送信間隔（pong待ち上限より短くする）
+	wsPingPeriod = 50 * time.Second
+	// クライアントから受け付けるメッセージの最大サイズ
+	wsMaxMessageSize = 4096
+	// 1接続で購読できる試合数の上限
+	wsMaxMatchIDs = 50
+	// 購読者ごとのバッファ件数。あふれた接続は切断する
+	wsBuffer = 64
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     checkOrigin,
+}
+
+// 接続元のページのOriginを確認する
+// Originがない（ブラウザ以外の）接続、リクエストと同じホスト、環境変数WS_ALLOWED_ORIGINS（カンマ区切り）に含まれるOriginのみ許可する
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range strings.Split(os.Getenv("WS_ALLOWED_ORIGINS"), ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// クライアントからの購読リクエスト
+// {"action": "subscribe", "match_ids": [1, 2], "league": "セ・リーグ"}
+type wsRequest struct {
+	Action   string `json:"action"`
+	MatchIDs []int  `json:"match_ids"`
+	League   string `json:"league"`
+}
+
+// 購読開始時の現在の試合進捗
+type wsSnapshot struct {
+	Type     string               `json:"type"`
+	MatchIDs []int                `json:"match_ids"`
+	Leagues  []string             `json:"leagues"`
+	Scores   []models.MatchDetail `json:"scores"`
+}
+
+// 購読解除の応答
+type wsUnsubscribed struct {
+	Type     string   `json:"type"`
+	MatchIDs []int    `json:"match_ids"`
+	Leagues  []string `json:"leagues"`
+}
+
+// 試合進捗の差分
+type wsDiff struct {
+	Type    string            `json:"type"`
+	ID      uint64            `json:"id"`
+	MatchID int               `json:"match_id"`
+	League  string            `json:"league"`
+	Changes map[string]string `json:"changes"`
+}
+
+// リクエストエラー
+type wsError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// 接続ごとの購読状態
+type wsSubscriptions struct {
+	mu       sync.Mutex
+	matchIDs map[int]bool
+	leagues  map[string]bool
+}
+
+func (s *wsSubscriptions) add(ids []int, league string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := 0
+	for _, id := range ids {
+		if !s.matchIDs[id] {
+			added++
+		}
+	}
+	if len(s.matchIDs)+added > wsMaxMatchIDs {
+		return fmt.Errorf("up to %d match_ids can be subscribed", wsMaxMatchIDs)
+	}
+	for _, id := range ids {
+		s.matchIDs[id] = true
+	}
+	if league != "" {
+		s.leagues[league] = true
+	}
+	return nil
+}
+
+func (s *wsSubscriptions) remove(ids []int, league string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.matchIDs, id)
+	}
+	if league != "" {
+		delete(s.leagues, league)
+	}
+}
+
+func (s *wsSubscriptions) match(ev feed.ScoreEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.matchIDs[ev.MatchID] || s.leagues[ev.League]
+}
+
+// 購読中の試合idとリーグを返す
+func (s *wsSubscriptions) list() ([]int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := []int{}
+	for id := range s.matchIDs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	leagues := []string{}
+	for league := range s.leagues {
+		leagues = append(leagues, league)
+	}
+	sort.Strings(leagues)
+	return ids, leagues
+}
+
+// 試合進捗の変化をWebSocketで配信する
+// 購読開始時に現在の試合進捗（snapshot）を返し、以降は変化した項目（diff）のみ送信する
+func ScoresWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	serveScoresWebSocket(w, r, broker)
+}
+
+func serveScoresWebSocket(w http.ResponseWriter, r *http.Request, b *feed.Broker) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		//Upgradeがエラーレスポンスを返している
+		log.Println("failed to upgrade websocket:", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := b.Subscribe(wsBuffer)
+	defer b.Unsubscribe(sub)
+
+	subs := &wsSubscriptions{matchIDs: map[int]bool{}, leagues: map[string]bool{}}
+	out := make(chan interface{}, 16)
+	done := make(chan struct{})
+	quit := make(chan struct{})
+	defer close(quit)
+
+	go readWebSocket(conn, subs, out, done, quit)
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	//書き込みはこのループのみで行う
+	for {
+		select {
+		case <-done:
+			return
+		case msg := <-out:
+			if err := writeWebSocket(conn, msg); err != nil {
+				return
+			}
+		case ev, ok := <-sub.C:
+			if !ok {
+				//受信が追いつかないクライアントは切断し、スケジューラを待たせない
+				log.Println("websocket subscriber closed: too slow")
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"),
+					time.Now().Add(wsWriteWait))
+				return
+			}
+			if !subs.match(ev) {
+				continue
+			}
+			diff := wsDiff{Type: "diff", ID: ev.ID, MatchID: ev.MatchID, League: ev.League, Changes: ev.Changes()}
+			if err := writeWebSocket(conn, diff); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// メッセージをJSONで書き込む
+func writeWebSocket(conn *websocket.Conn, msg interface{}) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(msg)
+}
+
+// クライアントからの購読リクエストを処理する
+// 接続が切れたらdoneを閉じる。書き込み側が終了した場合はquitが閉じられる
+func readWebSocket(conn *websocket.Conn, subs *wsSubscriptions, out chan<- interface{}, done chan<- struct{}, quit <-chan struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		return nil
+	})
+
+	send := func(msg interface{}) bool {
+		select {
+		case out <- msg:
+			return true
+		case <-quit:
+			return false
+		}
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			if !send(wsError{Type: "error", Message: "invalid message: " + err.Error()}) {
+				return
+			}
+			continue
+		}
+		if msg := validateWebSocketRequest(req); msg != "" {
+			if !send(wsError{Type: "error", Message: msg}) {
+				return
+			}
+			continue
+		}
+
+		switch req.Action {
+		case "subscribe":
+			if err := subs.add(req.MatchIDs, req.League); err != nil {
+				if !send(wsError{Type: "error", Message: err.Error()}) {
+					return
+				}
+				continue
+			}
+			//購読を登録してから取得するため、取得中の変化は後続のdiffで届く
+			scores, err := loadSnapshot(req.MatchIDs, req.League)
+			if err != nil {
+				log.Println("failed to load snapshot:", err)
+				if !send(wsError{Type: "error", Message: "failed to load snapshot"}) {
+					return
+				}
+				continue
+			}
+			ids, leagues := subs.list()
+			if !send(wsSnapshot{Type: "snapshot", MatchIDs: ids, Leagues: leagues, Scores: scores}) {
+				return
+			}
+		case "unsubscribe":
+			subs.remove(req.MatchIDs, req.League)
+			ids, leagues := subs.list()
+			if !send(wsUnsubscribed{Type: "unsubscribed", MatchIDs: ids, Leagues: leagues}) {
+				return
+			}
+		}
+	}
+}
+
+// 購読リクエストを検証し、不正な場合はエラーメッセージを返す
+func validateWebSocketRequest(req wsRequest) string {
+	if req.Action != "subscribe" && req.Action != "unsubscribe" {
+		return "action must be 'subscribe' or 'unsubscribe'"
+	}
+	if len(req.MatchIDs) == 0 && req.League == "" {
+		return "match_ids or league is required"
+	}
+	for _, id := range req.MatchIDs {
+		if id <= 0 {
+			return "match_ids must be positive integers"
+		}
+	}
+	if len([]rune(req.League)) > maxLeagueLength {
+		return fmt.Sprintf("league must be %d characters or less", maxLeagueLength)
+	}
+	return ""
+}
+
+// 購読対象の現在の試合進捗を取得する
+func loadSnapshot(ids []int, league string) ([]models.MatchDetail, error) {
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	repo := &repository.DefaultRepository{}
+	scores, err := repo.GetMatchDetails(db, ids, league, time.Now().Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	if scores == nil {
+		scores = []models.MatchDetail{}
+	}
+	return scores, nil
+}
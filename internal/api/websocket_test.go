@@ -0,0 +1,195 @@
+package api
+
+import (
+	"baseball_report/internal/feed"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// ブローカーを差し替えたテスト用サーバに接続する
+func dialScoresWebSocket(t *testing.T, b *feed.Broker) (*websocket.Conn, func()) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveScoresWebSocket(w, r, b)
+	}))
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		server.Close()
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	return conn, func() {
+		conn.Close()
+		server.Close()
+	}
+}
+
+// 1メッセージを受信する
+func readWebSocketJSON(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg map[string]interface{}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	return msg
+}
+
+func TestScoresWebSocket(t *testing.T) {
+	columns := []string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}
+
+	t.Run("Snapshot and diffs", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "2025-04-06", "ヤクルト", "中日", "セ・リーグ", "神宮", "13:00:00", "3回裏", "1", "0", "村上", "")
+				mock.ExpectQuery(regexp.QuoteMeta("m.id IN (?, ?)")).WithArgs(1, 2).WillReturnRows(rows)
+				return db, nil
+			},
+		}
+
+		b := feed.NewBroker(10)
+		conn, closeAll := dialScoresWebSocket(t, b)
+		defer closeAll()
+
+		assert.NoError(t, conn.WriteJSON(map[string]interface{}{"action": "subscribe", "match_ids": []int{1, 2}}))
+
+		snapshot := readWebSocketJSON(t, conn)
+		assert.Equal(t, "snapshot", snapshot["type"])
+		assert.Equal(t, []interface{}{1.0, 2.0}, snapshot["match_ids"])
+		scores := snapshot["scores"].([]interface{})
+		assert.Len(t, scores, 1)
+		assert.Equal(t, "村上", scores[0].(map[string]interface{})["batter"])
+
+		// 購読していない試合の変化は届かない
+		b.Publish(feed.ScoreEvent{MatchID: 3, Score: feed.ScoreState{HomeScore: "9"}, Changed: []string{"home_score"}})
+		b.Publish(feed.ScoreEvent{MatchID: 1, League: "セ・リーグ", Score: feed.ScoreState{HomeScore: "2", Batter: "山田"}, Changed: []string{"home_score", "batter"}})
+
+		diff := readWebSocketJSON(t, conn)
+		assert.Equal(t, "diff", diff["type"])
+		assert.Equal(t, 1.0, diff["match_id"])
+		assert.Equal(t, map[string]interface{}{"home_score": "2", "batter": "山田"}, diff["changes"])
+	})
+
+	t.Run("Subscribe league and unsubscribe", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				mock.ExpectQuery(regexp.QuoteMeta("(m.league = ? AND m.date = ? AND m.status <> 'removed')")).
+					WithArgs("パ・リーグ", time.Now().Format("2006-01-02")).
+					WillReturnRows(sqlmock.NewRows(columns))
+				return db, nil
+			},
+		}
+
+		b := feed.NewBroker(10)
+		conn, closeAll := dialScoresWebSocket(t, b)
+		defer closeAll()
+
+		assert.NoError(t, conn.WriteJSON(map[string]interface{}{"action": "subscribe", "league": "パ・リーグ"}))
+		snapshot := readWebSocketJSON(t, conn)
+		assert.Equal(t, "snapshot", snapshot["type"])
+		assert.Equal(t, []interface{}{"パ・リーグ"}, snapshot["leagues"])
+		assert.Equal(t, []interface{}{}, snapshot["scores"])
+
+		b.Publish(feed.ScoreEvent{MatchID: 5, League: "パ・リーグ", Score: feed.ScoreState{Inning: "1回表"}, Changed: []string{"inning"}})
+		diff := readWebSocketJSON(t, conn)
+		assert.Equal(t, 5.0, diff["match_id"])
+
+		assert.NoError(t, conn.WriteJSON(map[string]interface{}{"action": "unsubscribe", "league": "パ・リーグ"}))
+		unsubscribed := readWebSocketJSON(t, conn)
+		assert.Equal(t, "unsubscribed", unsubscribed["type"])
+		assert.Equal(t, []interface{}{}, unsubscribed["leagues"])
+
+		// 解除後の変化は届かない
+		b.Publish(feed.ScoreEvent{MatchID: 5, League: "パ・リーグ", Score: feed.ScoreState{Inning: "1回裏"}, Changed: []string{"inning"}})
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		_, _, err := conn.ReadMessage()
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		conn, closeAll := dialScoresWebSocket(t, feed.NewBroker(10))
+		defer closeAll()
+
+		for _, req := range []string{
+			`not json`,
+			`{"action": "watch", "match_ids": [1]}`,
+			`{"action": "subscribe"}`,
+			`{"action": "subscribe", "match_ids": [0]}`,
+		} {
+			assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+			msg := readWebSocketJSON(t, conn)
+			assert.Equal(t, "error", msg["type"], req)
+			assert.NotEmpty(t, msg["message"], req)
+		}
+	})
+
+	t.Run("Failed to load snapshot", func(t *testing.T) {
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				return nil, errors.New("DB接続エラー")
+			},
+		}
+
+		conn, closeAll := dialScoresWebSocket(t, feed.NewBroker(10))
+		defer closeAll()
+
+		assert.NoError(t, conn.WriteJSON(map[string]interface{}{"action": "subscribe", "match_ids": []int{1}}))
+		msg := readWebSocketJSON(t, conn)
+		assert.Equal(t, "error", msg["type"])
+		assert.Equal(t, "failed to load snapshot", msg["message"])
+	})
+}
+
+func TestCheckOrigin(t *testing.T) {
+	t.Setenv("WS_ALLOWED_ORIGINS", "https://app.example.com, http://localhost:3000/")
+
+	cases := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"No origin", "", true},
+		{"Same host", "https://scores.example.com", true},
+		{"Allowed origin", "https://app.example.com", true},
+		{"Allowed origin with trailing slash", "http://localhost:3000", true},
+		{"Other scheme", "http://app.example.com", false},
+		{"Other host", "https://evil.example.net", false},
+		{"Invalid origin", "://", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://scores.example.com/ws/scores", nil)
+			if c.origin != "" {
+				r.Header.Set("Origin", c.origin)
+			}
+			assert.Equal(t, c.want, checkOrigin(r))
+		})
+	}
+
+	//許可していないOriginからの接続はUpgradeしない
+	t.Run("Reject upgrade", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serveScoresWebSocket(w, r, feed.NewBroker(8))
+		}))
+		defer server.Close()
+
+		header := http.Header{"Origin": []string{"https://evil.example.net"}}
+		_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+		assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+		if assert.NotNil(t, resp) {
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatusHeader 前回の取得から内容が変わっていないことを示すヘッダー
+const StatusHeader = "X-Cache-Status"
+
+// StatusUnchanged StatusHeaderの値
+const StatusUnchanged = "unchanged"
+
+// キャッシュするレスポンスボディの上限
+const maxBodySize = 10 << 20
+
+// 保持するURLの数の既定値
+const defaultMaxEntries = 128
+
+// Default スクレイピングで共有するキャッシュ
+// 保存先は環境変数SCRAPE_CACHE_DIR（未設定の場合は一時ディレクトリ）
+var Default = New(cacheDir())
+
+// Entry URLごとのキャッシュ
+type Entry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"last_modified"`
+	Hash         string    `json:"hash"`
+	Body         []byte    `json:"body"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+// Transport 条件付きリクエストとボディのハッシュでレスポンスをキャッシュするhttp.RoundTripper
+// 304、または前回とボディが同一の200の場合は、キャッシュしたボディを200で返しStatusHeaderを付ける
+type Transport struct {
+	Base http.RoundTripper
+	Dir  string
+	// 保持するURLの数の上限。超えた場合は最近使っていないURLからメモリとディスクのキャッシュを破棄する
+	MaxEntries int
+
+	mu sync.Mutex
+	// Dirに保存済みのキャッシュを読み込んだか
+	loaded  bool
+	entries map[string]*list.Element
+	// 最近使った順（先頭が最新）の*Entry
+	recent *list.List
+}
+
+func New(dir string) *Transport {
+	return &Transport{Dir: dir, MaxEntries: defaultMaxEntries, entries: map[string]*list.Element{}, recent: list.New()}
+}
+
+func cacheDir() string {
+	if dir := os.Getenv("SCRAPE_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "baseball_report-cache")
+}
+
+// レスポンスが前回の取得から変わっていないか
+func Unchanged(res *http.Response) bool {
+	return res != nil && res.Header.Get(StatusHeader) == StatusUnchanged
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base().RoundTrip(req)
+	}
+	url := req.URL.String()
+	entry := t.get(url)
+
+	// 保存済みの検証子で条件付きリクエストにする
+	if entry != nil && (entry.ETag != "" || entry.LastModified != "") {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	res, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && entry != nil:
+		res.Body.Close()
+		return cachedResponse(res, entry.Body, true), nil
+	case res.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize+1))
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+		if len(body) > maxBodySize {
+			return cachedResponse(res, body, false), nil
+		}
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		unchanged := entry != nil && entry.Hash == hash
+		t.put(&Entry{
+			URL:          url,
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			Hash:         hash,
+			Body:         body,
+			StoredAt:     time.Now(),
+		})
+		return cachedResponse(res, body, unchanged), nil
+	default:
+		return res, nil
+	}
+}
+
+// bodyを持つ200のレスポンスを作る
+func cachedResponse(res *http.Response, body []byte, unchanged bool) *http.Response {
+	out := *res
+	out.Header = res.Header.Clone()
+	out.StatusCode = http.StatusOK
+	out.Status = "200 OK"
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	out.Header.Del("Content-Length")
+	if unchanged {
+		out.Header.Set(StatusHeader, StatusUnchanged)
+	}
+	return &out
+}
+
+// URLのキャッシュを破棄する（取得後の処理に失敗した場合、次回は変化ありとして扱う）
+func (t *Transport) Forget(url string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if el, ok := t.entries[url]; ok {
+		t.recent.Remove(el)
+		delete(t.entries, url)
+	}
+	if err := os.Remove(t.path(url)); err != nil && !os.IsNotExist(err) {
+		log.Println(fmt.Errorf("failed to remove cache: %w", err))
+	}
+}
+
+func (t *Transport) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// 初回のみDirに保存済みのキャッシュを古い順に読み込み、上限を超えた分と読めないファイルを削除する（呼び出し側でmuをロックする）
+// 前回の起動までのファイルも上限に含めるため
+func (t *Transport) load() {
+	if t.loaded {
+		return
+	}
+	t.loaded = true
+	files, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return
+	}
+	type stored struct {
+		name    string
+		modTime time.Time
+	}
+	var saved []stored
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() {
+			continue
+		}
+		if strings.HasPrefix(name, "tmp-") {
+			//書き込み途中で終了した一時ファイル
+			os.Remove(filepath.Join(t.Dir, name))
+			continue
+		}
+		if info, err := f.Info(); err == nil && filepath.Ext(name) == ".json" {
+			saved = append(saved, stored{name, info.ModTime()})
+		}
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].modTime.Before(saved[j].modTime) })
+	for _, f := range saved {
+		path := filepath.Join(t.Dir, f.name)
+		data, err := os.ReadFile(path)
+		var entry Entry
+		if err != nil || json.Unmarshal(data, &entry) != nil || t.path(entry.URL) != path {
+			os.Remove(path)
+			continue
+		}
+		t.remember(&entry)
+	}
+}
+
+// メモリになければディスクから読み込む
+func (t *Transport) get(url string) *Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load()
+	if el, ok := t.entries[url]; ok {
+		t.recent.MoveToFront(el)
+		return el.Value.(*Entry)
+	}
+	data, err := os.ReadFile(t.path(url))
+	if err != nil {
+		return nil
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil
+	}
+	t.remember(&entry)
+	return &entry
+}
+
+// メモリに保持し、上限を超えた分は最近使っていないURLからメモリとディスクのキャッシュを破棄する（呼び出し側でmuをロックする）
+func (t *Transport) remember(entry *Entry) {
+	if el, ok := t.entries[entry.URL]; ok {
+		el.Value = entry
+		t.recent.MoveToFront(el)
+	} else {
+		t.entries[entry.URL] = t.recent.PushFront(entry)
+	}
+	for t.recent.Len() > max(t.MaxEntries, 1) {
+		oldest := t.recent.Back()
+		t.recent.Remove(oldest)
+		url := oldest.Value.(*Entry).URL
+		delete(t.entries, url)
+		if err := os.Remove(t.path(url)); err != nil && !os.IsNotExist(err) {
+			log.Println(fmt.Errorf("failed to remove cache: %w", err))
+		}
+	}
+}
+
+// メモリとディスクに保存する（ディスクへの保存に失敗してもメモリのキャッシュは使う）
+func (t *Transport) put(entry *Entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load()
+	t.remember(entry)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to encode cache: %w", err))
+		return
+	}
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		log.Println(fmt.Errorf("failed to create cache dir: %w", err))
+		return
+	}
+	// 書き込み途中のファイルを読まないよう一時ファイルから置き換える
+	tmp, err := os.CreateTemp(t.Dir, "tmp-*")
+	if err != nil {
+		log.Println(fmt.Errorf("failed to write cache: %w", err))
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(tmp.Name())
+		log.Println(fmt.Errorf("failed to write cache: %w", errors.Join(werr, cerr)))
+		return
+	}
+	if err := os.Rename(tmp.Name(), t.path(entry.URL)); err != nil {
+		os.Remove(tmp.Name())
+		log.Println(fmt.Errorf("failed to write cache: %w", err))
+	}
+}
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// キャッシュを通してGETし、ボディと変化なしフラグを返す
+func fetch(t *testing.T, tr *Transport, url string) (string, bool) {
+	t.Helper()
+	client := &http.Client{Transport: tr}
+	res, err := client.Get(url)
+	if !assert.NoError(t, err) {
+		return "", false
+	}
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, _ := io.ReadAll(res.Body)
+	return string(body), Unchanged(res)
+}
+
+func TestTransport_ETag(t *testing.T) {
+	var notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("<em>1回表</em>"))
+	}))
+	defer server.Close()
+
+	tr := New(t.TempDir())
+
+	//初回は変化あり
+	body, unchanged := fetch(t, tr, server.URL)
+	assert.Equal(t, "<em>1回表</em>", body)
+	assert.False(t, unchanged)
+
+	//2回目は304となり、キャッシュのボディが返る
+	body, unchanged = fetch(t, tr, server.URL)
+	assert.Equal(t, "<em>1回表</em>", body)
+	assert.True(t, unchanged)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+}
+
+func TestTransport_LastModified(t *testing.T) {
+	const lastModified = "Sun, 06 Apr 2025 09:00:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte("page"))
+	}))
+	defer server.Close()
+
+	tr := New(t.TempDir())
+	fetch(t, tr, server.URL)
+
+	body, unchanged := fetch(t, tr, server.URL)
+	assert.Equal(t, "page", body)
+	assert.True(t, unchanged)
+}
+
+// 検証子がない場合はボディのハッシュで判定
+func TestTransport_Hash(t *testing.T) {
+	var content atomic.Value
+	content.Store("2回裏")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content.Load().(string)))
+	}))
+	defer server.Close()
+
+	tr := New(t.TempDir())
+	_, unchanged := fetch(t, tr, server.URL)
+	assert.False(t, unchanged)
+
+	//同一のボディ
+	_, unchanged = fetch(t, tr, server.URL)
+	assert.True(t, unchanged)
+
+	//ボディが変わった
+	content.Store("3回表")
+	body, unchanged := fetch(t, tr, server.URL)
+	assert.Equal(t, "3回表", body)
+	assert.False(t, unchanged)
+}
+
+// ディスクに保存したキャッシュを再起動後も使う
+func TestTransport_Persist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("same"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	fetch(t, New(dir), server.URL)
+
+	_, unchanged := fetch(t, New(dir), server.URL)
+	assert.True(t, unchanged)
+}
+
+// 保持するのはMaxEntriesまでで、最近使っていないURLからメモリとディスクのキャッシュを破棄する
+func TestTransport_MaxEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	tr := New(dir)
+	tr.MaxEntries = 2
+	for _, path := range []string{"/a", "/b", "/a", "/c"} {
+		fetch(t, tr, server.URL+path)
+	}
+
+	//最近使っていない/bから破棄する
+	tr.mu.Lock()
+	assert.Len(t, tr.entries, 2)
+	assert.Contains(t, tr.entries, server.URL+"/a")
+	assert.Contains(t, tr.entries, server.URL+"/c")
+	tr.mu.Unlock()
+	assert.ElementsMatch(t, []string{tr.path(server.URL + "/a"), tr.path(server.URL + "/c")}, cacheFiles(t, dir))
+
+	//破棄したURLは変化ありとして扱う
+	_, unchanged := fetch(t, tr, server.URL+"/b")
+	assert.False(t, unchanged)
+	assert.ElementsMatch(t, []string{tr.path(server.URL + "/c"), tr.path(server.URL + "/b")}, cacheFiles(t, dir))
+}
+
+// 前回の起動までに保存したファイルも上限に含め、古いファイルと書き込み途中のファイルを削除する
+func TestTransport_MaxEntries_Restart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	tr := New(dir)
+	base := time.Now().Add(-time.Hour)
+	for i, path := range []string{"/a", "/b", "/c", "/d"} {
+		fetch(t, tr, server.URL+path)
+		//保存順がわかるよう更新日時をずらす
+		assert.NoError(t, os.Chtimes(tr.path(server.URL+path), base, base.Add(time.Duration(i)*time.Minute)))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tmp-123"), []byte("{"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644))
+	assert.Len(t, cacheFiles(t, dir), 6)
+
+	restarted := New(dir)
+	restarted.MaxEntries = 2
+	_, unchanged := fetch(t, restarted, server.URL+"/d")
+	assert.True(t, unchanged)
+	assert.ElementsMatch(t, []string{tr.path(server.URL + "/c"), tr.path(server.URL + "/d")}, cacheFiles(t, dir))
+}
+
+// ディレクトリ内のファイル
+func cacheFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	return files
+}
+
+// Forget後は変化ありとして扱う
+func TestTransport_Forget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("same"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	tr := New(dir)
+	fetch(t, tr, server.URL)
+	tr.Forget(server.URL)
+
+	_, unchanged := fetch(t, tr, server.URL)
+	assert.False(t, unchanged)
+}
+
+// 200以外のレスポンスはそのまま返し、キャッシュしない
+func TestTransport_ErrorStatus(t *testing.T) {
+	var status int32 = http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write([]byte("error"))
+	}))
+	defer server.Close()
+
+	tr := New(t.TempDir())
+	client := &http.Client{Transport: tr}
+	res, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	_, unchanged := fetch(t, tr, server.URL)
+	assert.False(t, unchanged)
+}
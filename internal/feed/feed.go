@@ -0,0 +1,197 @@
+package feed
+
+import (
+	"sync"
+	"time"
+)
+
+// ScoreState 試合進捗の状態（scoresテーブルの1行に相当）
+type ScoreState struct {
+	Inning    string `json:"inning"`
+	HomeScore string `json:"home_score"`
+	AwayScore string `json:"away_score"`
+	Batter    string `json:"batter"`
+	Result    string `json:"result"`
+}
+
+// ScoreEvent 試合進捗の変化
+type ScoreEvent struct {
+	ID       uint64     `json:"id"`
+	MatchID  int        `json:"match_id"`
+	League   string     `json:"league"`
+	Home     string     `json:"home"`
+	Away     string     `json:"away"`
+	Score    ScoreState `json:"score"`
+	Previous ScoreState `json:"previous"`
+	Changed  []string   `json:"changed"`
+	Time     time.Time  `json:"time"`
+}
+
+// Publisher 試合進捗の変化を通知する
+type Publisher interface {
+	Publish(ev ScoreEvent) ScoreEvent
+}
+
+// Default アプリ全体で共有するブローカー
+var Default = NewBroker(1024)
+
+// Diff 変化した項目名を返す
+func Diff(prev, next ScoreState) []string {
+	var changed []string
+	if prev.Inning != next.Inning {
+		changed = append(changed, "inning")
+	}
+	if prev.HomeScore != next.HomeScore {
+		changed = append(changed, "home_score")
+	}
+	if prev.AwayScore != next.AwayScore {
+		changed = append(changed, "away_score")
+	}
+	if prev.Batter != next.Batter {
+		changed = append(changed, "batter")
+	}
+	if prev.Result != next.Result {
+		changed = append(changed, "result")
+	}
+	return changed
+}
+
+// Field 項目名に対応する値を返す
+func (s ScoreState) Field(name string) string {
+	switch name {
+	case "inning":
+		return s.Inning
+	case "home_score":
+		return s.HomeScore
+	case "away_score":
+		return s.AwayScore
+	case "batter":
+		return s.Batter
+	case "result":
+		return s.Result
+	}
+	return ""
+}
+
+// Changes 変化した項目と変化後の値を返す
+func (ev ScoreEvent) Changes() map[string]string {
+	changes := make(map[string]string, len(ev.Changed))
+	for _, name := range ev.Changed {
+		changes[name] = ev.Score.Field(name)
+	}
+	return changes
+}
+
+// Subscription 購読者ごとの受信チャネル
+// 受信が追いつかずバッファがあふれた場合はチャネルを閉じる
+type Subscription struct {
+	C <-chan ScoreEvent
+
+	ch     chan ScoreEvent
+	closed bool
+}
+
+// Broker 試合進捗の変化を購読者に配信する
+// 直近のイベントを保持し、Last-Event-IDからの再開に使用する
+type Broker struct {
+	mu      sync.Mutex
+	nextID  uint64
+	history []ScoreEvent
+	size    int
+	subs    map[*Subscription]struct{}
+}
+
+// NewBroker historySize件のイベントを保持するブローカーを生成
+func NewBroker(historySize int) *Broker {
+	return &Broker{
+		// 再起動後もIDが単調増加するよう起動時刻を起点にする
+		nextID: uint64(time.Now().UnixMilli()) * 1000,
+		size:   historySize,
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish イベントにIDを採番し、全購読者に配信する
+// 購読者の受信を待たないため、遅い購読者がスケジューラを止めることはない
+func (b *Broker) Publish(ev ScoreEvent) ScoreEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	ev.ID = b.nextID
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	b.history = append(b.history, ev)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			// バッファあふれの購読者は切断し、再接続時に履歴から再開させる
+			b.closeLocked(sub)
+		}
+	}
+	return ev
+}
+
+// Subscribe buffer件までバッファする購読を開始する
+func (b *Broker) Subscribe(buffer int) *Subscription {
+	sub, _ := b.SubscribeSince(0, buffer)
+	return sub
+}
+
+// SubscribeSince 購読を開始し、lastIDより後の保持中イベントを返す
+// lastIDが0の場合は履歴を返さない
+func (b *Broker) SubscribeSince(lastID uint64, buffer int) (*Subscription, []ScoreEvent) {
+	sub, missed, _, _ := b.Resume(lastID, buffer)
+	return sub, missed
+}
+
+// Resume 購読を開始し、lastIDより後の保持中イベントと、購読開始時点で最新のイベントIDを返す
+// lastIDより後のイベントが履歴から消えている（取りこぼしがある）場合はcompleteがfalse
+// lastIDが0の場合は履歴を返さず、completeはtrue
+func (b *Broker) Resume(lastID uint64, buffer int) (sub *Subscription, missed []ScoreEvent, latest uint64, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	if lastID != 0 {
+		//保持中の最も古いイベント（履歴が空の場合は次のイベント）の直前まで受信していれば取りこぼしはない
+		oldest := b.nextID + 1
+		if len(b.history) != 0 {
+			oldest = b.history[0].ID
+		}
+		complete = lastID+1 >= oldest
+		for _, ev := range b.history {
+			if ev.ID > lastID {
+				missed = append(missed, ev)
+			}
+		}
+	}
+
+	ch := make(chan ScoreEvent, buffer)
+	sub = &Subscription{C: ch, ch: ch}
+	b.subs[sub] = struct{}{}
+	return sub, missed, b.nextID, complete
+}
+
+// Unsubscribe 購読を終了する
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closeLocked(sub)
+}
+
+func (b *Broker) closeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subs, sub)
+	close(sub.ch)
+}
@@ -0,0 +1,124 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("No change", func(t *testing.T) {
+		state := ScoreState{Inning: "3回裏", HomeScore: "1", AwayScore: "0", Batter: "山田", Result: "ヒット"}
+		assert.Nil(t, Diff(state, state))
+	})
+
+	t.Run("Score and batter changed", func(t *testing.T) {
+		prev := ScoreState{Inning: "3回裏", HomeScore: "1", AwayScore: "0", Batter: "山田", Result: "ヒット"}
+		next := ScoreState{Inning: "3回裏", HomeScore: "2", AwayScore: "0", Batter: "佐藤", Result: "ヒット"}
+		assert.Equal(t, []string{"home_score", "batter"}, Diff(prev, next))
+	})
+}
+
+func TestChanges(t *testing.T) {
+	ev := ScoreEvent{
+		Score:   ScoreState{Inning: "4回表", HomeScore: "2", AwayScore: "0", Batter: "佐藤", Result: "四球"},
+		Changed: []string{"inning", "batter"},
+	}
+	assert.Equal(t, map[string]string{"inning": "4回表", "batter": "佐藤"}, ev.Changes())
+}
+
+func TestBroker(t *testing.T) {
+	t.Run("Publish to subscribers", func(t *testing.T) {
+		b := NewBroker(10)
+		sub1 := b.Subscribe(1)
+		sub2 := b.Subscribe(1)
+
+		ev := b.Publish(ScoreEvent{MatchID: 1})
+		assert.NotZero(t, ev.ID)
+		assert.False(t, ev.Time.IsZero())
+
+		assert.Equal(t, ev, <-sub1.C)
+		assert.Equal(t, ev, <-sub2.C)
+	})
+
+	t.Run("IDs are increasing", func(t *testing.T) {
+		b := NewBroker(10)
+		ev1 := b.Publish(ScoreEvent{MatchID: 1})
+		ev2 := b.Publish(ScoreEvent{MatchID: 2})
+		assert.Greater(t, ev2.ID, ev1.ID)
+	})
+
+	t.Run("Resume from last event id", func(t *testing.T) {
+		b := NewBroker(10)
+		ev1 := b.Publish(ScoreEvent{MatchID: 1})
+		ev2 := b.Publish(ScoreEvent{MatchID: 2})
+		ev3 := b.Publish(ScoreEvent{MatchID: 3})
+
+		sub, missed := b.SubscribeSince(ev1.ID, 1)
+		defer b.Unsubscribe(sub)
+		assert.Equal(t, []ScoreEvent{ev2, ev3}, missed)
+	})
+
+	t.Run("History is bounded", func(t *testing.T) {
+		b := NewBroker(2)
+		ev1 := b.Publish(ScoreEvent{MatchID: 1})
+		b.Publish(ScoreEvent{MatchID: 2})
+		b.Publish(ScoreEvent{MatchID: 3})
+
+		_, missed := b.SubscribeSince(ev1.ID, 1)
+		assert.Len(t, missed, 2)
+		assert.Equal(t, 2, missed[0].MatchID)
+	})
+
+	//履歴から消えたイベントがあるか判定する
+	t.Run("Resume reports expired history", func(t *testing.T) {
+		b := NewBroker(2)
+		ev1 := b.Publish(ScoreEvent{MatchID: 1})
+		ev2 := b.Publish(ScoreEvent{MatchID: 2})
+		b.Publish(ScoreEvent{MatchID: 3})
+		ev4 := b.Publish(ScoreEvent{MatchID: 4})
+
+		sub, missed, latest, complete := b.Resume(ev2.ID, 1)
+		b.Unsubscribe(sub)
+		assert.True(t, complete)
+		assert.Len(t, missed, 2)
+		assert.Equal(t, ev4.ID, latest)
+
+		sub, missed, latest, complete = b.Resume(ev1.ID, 1)
+		b.Unsubscribe(sub)
+		assert.False(t, complete)
+		assert.Len(t, missed, 2)
+		assert.Equal(t, ev4.ID, latest)
+
+		//再起動前のIDは取りこぼしがある
+		restarted := NewBroker(2)
+		restarted.nextID = ev4.ID + 1000
+		sub, missed, _, complete = restarted.Resume(ev4.ID, 1)
+		assert.NotNil(t, sub)
+		assert.False(t, complete)
+		assert.Empty(t, missed)
+
+		_, _, _, complete = b.Resume(0, 1)
+		assert.True(t, complete)
+	})
+
+	t.Run("Slow subscriber is closed", func(t *testing.T) {
+		b := NewBroker(10)
+		slow := b.Subscribe(1)
+		fast := b.Subscribe(10)
+
+		b.Publish(ScoreEvent{MatchID: 1})
+		b.Publish(ScoreEvent{MatchID: 2})
+
+		// バッファ分は受信でき、その後チャネルが閉じている
+		<-slow.C
+		_, ok := <-slow.C
+		assert.False(t, ok)
+
+		// 他の購読者には影響しない
+		assert.Len(t, fast.C, 2)
+
+		// 二重に閉じてもpanicしない
+		b.Unsubscribe(slow)
+	})
+}
@@ -0,0 +1,38 @@
+package fetcher
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/utils"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 試合速報ページからラインスコアを先攻・後攻の順に取得
+// ラインスコアが表示されていない（試合前など）場合は空を返す
+func GetLineScore(doc *goquery.Document) ([]models.LineScore, error) {
+	sel := CurrentSelectors().LineScore
+
+	var lines []models.LineScore
+	utils.GetElement(doc, sel.Row).Each(func(i int, row *goquery.Selection) {
+		//イニングごとの得点
+		innings := []string{}
+		row.Find(sel.Inning).Each(func(j int, cell *goquery.Selection) {
+			innings = append(innings, strings.TrimSpace(cell.Text()))
+		})
+		lines = append(lines, models.LineScore{
+			Team:    utils.GetText(row, sel.Team),
+			Innings: innings,
+			Runs:    utils.GetText(row, sel.Runs),
+			Hits:    utils.GetText(row, sel.Hits),
+			Errors:  utils.GetText(row, sel.Errors),
+		})
+	})
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	if err := validateLineScore(lines); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
@@ -0,0 +1,87 @@
+package fetcher
+
+import (
+	"baseball_report/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+// ラインスコアの1行を作る
+func lineScoreRow(team string, innings []string, runs, hits, errors string) string {
+	row := `<tr><td class="bb-gameScoreTable__team">` + team + `</td>`
+	for _, inning := range innings {
+		row += `<td class="bb-gameScoreTable__score">` + inning + `</td>`
+	}
+	return row + `<td class="bb-gameScoreTable__total">` + runs + `</td>` +
+		`<td class="bb-gameScoreTable__data--hits">` + hits + `</td>` +
+		`<td class="bb-gameScoreTable__data--loss">` + errors + `</td></tr>`
+}
+
+func TestGetLineScore(t *testing.T) {
+	page := func(rows ...string) *goquery.Document {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`
+			<table id="ing_brd">
+				<thead><tr><th>チーム</th><th>1</th><th>2</th><th>3</th><th>計</th><th>安</th><th>失</th></tr></thead>
+				<tbody>` + strings.Join(rows, "") + `</tbody>
+			</table>`))
+		return doc
+	}
+
+	t.Run("Success get line score", func(t *testing.T) {
+		doc := page(
+			lineScoreRow("オ", []string{"0", "1", "0"}, "1", "4", "0"),
+			lineScoreRow("デ", []string{"2", "0", "X"}, "2", "7", "1"),
+		)
+		lines, err := GetLineScore(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.LineScore{
+			{Team: "オ", Innings: []string{"0", "1", "0"}, Runs: "1", Hits: "4", Errors: "0"},
+			{Team: "デ", Innings: []string{"2", "0", "X"}, Runs: "2", Hits: "7", Errors: "1"},
+		}, lines)
+	})
+
+	//試合中は未実施のイニングが空
+	t.Run("Success get line score in game", func(t *testing.T) {
+		doc := page(
+			lineScoreRow("オ", []string{"0", "1", ""}, "1", "4", "0"),
+			lineScoreRow("デ", []string{"2", "", ""}, "2", "7", "1"),
+		)
+		lines, err := GetLineScore(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2", "", ""}, lines[1].Innings)
+	})
+
+	t.Run("No line score", func(t *testing.T) {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<div class="live"><em>試合前</em></div>`))
+		lines, err := GetLineScore(doc)
+		assert.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+
+	t.Run("Error invalid line score", func(t *testing.T) {
+		cases := map[string]struct {
+			doc   *goquery.Document
+			field string
+		}{
+			"one row": {page(lineScoreRow("オ", []string{"0"}, "0", "0", "0")), "line_score.row"},
+			"inning counts differ": {page(
+				lineScoreRow("オ", []string{"0", "1"}, "1", "4", "0"),
+				lineScoreRow("デ", []string{"2"}, "2", "7", "1"),
+			), "line_score.inning"},
+			"hits not a number": {page(
+				lineScoreRow("オ", []string{"0"}, "0", "四", "0"),
+				lineScoreRow("デ", []string{"2"}, "2", "7", "1"),
+			), "line_score.hits"},
+		}
+		for name, c := range cases {
+			_, err := GetLineScore(c.doc)
+			var perr *ParseError
+			if assert.ErrorAs(t, err, &perr, name) {
+				assert.Equal(t, c.field, perr.Field, name)
+			}
+		}
+	})
+}
@@ -9,30 +9,46 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
-func GetMatchSchedule(doc *goquery.Document) ([][]string, error) {
-	todate := time.Now().Format("2006/01/02")
+// dateの日程ページから試合情報を取得
+// 試合がない日はnilを返す。試合もなく、試合がないことも表示されていない場合はParseError
+func GetMatchSchedule(doc *goquery.Document, date time.Time) ([][]string, error) {
+	todate := date.Format("2006/01/02")
+	sel := CurrentSelectors().Schedule
 
 	// 2次元配列で試合情報を格納
 	var matchData [][]string
+	// 最初に見つかった解析結果の不正
+	var parseErr error
+	// 試合がないことが表示されているか
+	noData := false
 
 	// 各リーグのスコア要素を取得
-	utils.GetElement(doc, ".bb-score").Each(func(index int, param *goquery.Selection) {
+	utils.GetElement(doc, sel.League).Each(func(index int, param *goquery.Selection) {
 		//試合がない場合はno card todayを返す
-		if utils.GetText(param, ".bb-noData") == "" {
+		if utils.GetText(param, sel.NoData) == "" {
 
 			// リーグ名を取得
-			header := utils.GetText(param, ".bb-score__title")
+			header := utils.GetText(param, sel.Title)
 
 			// リーグ内の各試合情報を取得
-			utils.GetElement(param, ".bb-score__item").Each(func(count int, card *goquery.Selection) {
-				home := utils.GetText(card, "[class*='bb-score__homeLogo']")
-				away := utils.GetText(card, "[class*='bb-score__awayLogo']")
-				stadium := utils.GetText(card, ".bb-score__venue")
-				status := utils.GetText(card, ".bb-score__link")
-				starttime := utils.GetText(card, ".bb-score__status")
-				link, err := utils.GetElement(card, ".bb-score__content").Attr("href")
-				if !err {
-					log.Println("Link not found for the match.")
+			utils.GetElement(param, sel.Item).Each(func(count int, card *goquery.Selection) {
+				home := utils.GetText(card, sel.Home)
+				away := utils.GetText(card, sel.Away)
+				stadium := utils.GetText(card, sel.Venue)
+				status := utils.GetText(card, sel.Status)
+				starttime := utils.GetText(card, sel.StartTime)
+				if err := validateSchedule(home, away); err != nil {
+					if parseErr == nil {
+						parseErr = err
+					}
+					return
+				}
+				link, ok := utils.GetElement(card, sel.Link).Attr("href")
+				if !ok {
+					//試合を日程から消さないよう、その日の取り込みを失敗にする
+					if parseErr == nil {
+						parseErr = &ParseError{Field: "schedule.link", Value: home + "-" + away, Reason: "href not found"}
+					}
 					return
 				}
 				link = strings.Replace(link, "index", "score", 1)
@@ -41,8 +57,17 @@ func GetMatchSchedule(doc *goquery.Document) ([][]string, error) {
 			})
 
 		} else {
+			noData = true
 			log.Println("No card today.")
 		}
 	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	//試合がないと表示されていないのに試合が見つからない場合は、ページの構成が変わったものとする
+	//（試合がない日として日程を消さないため）
+	if len(matchData) == 0 && !noData {
+		return nil, &ParseError{Field: "schedule.item", Reason: "found no games and no no-data message"}
+	}
 	return matchData, nil
 }
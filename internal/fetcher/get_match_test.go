@@ -102,7 +102,7 @@ func TestGetmatchschedulemock(t *testing.T) {
 		}
 
 		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html1league1games))
-		matchdata, err := GetMatchSchedule(doc)
+		matchdata, err := GetMatchSchedule(doc, time.Now())
 
 		//エラーが発生せず処理が終了しているか確認
 		assert.NoError(t, err)
@@ -120,7 +120,7 @@ func TestGetmatchschedulemock(t *testing.T) {
 		}
 
 		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html1league2games))
-		matchdata, err := GetMatchSchedule(doc)
+		matchdata, err := GetMatchSchedule(doc, time.Now())
 
 		//エラーが発生せず処理が終了しているか確認
 		assert.NoError(t, err)
@@ -140,7 +140,7 @@ func TestGetmatchschedulemock(t *testing.T) {
 		}
 
 		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html2league2games))
-		matchdata, err := GetMatchSchedule(doc)
+		matchdata, err := GetMatchSchedule(doc, time.Now())
 
 		//エラーが発生せず処理が終了しているか確認
 		assert.NoError(t, err)
@@ -152,7 +152,7 @@ func TestGetmatchschedulemock(t *testing.T) {
 	// 今日は試合が無い
 	t.Run("Success get nogame", func(t *testing.T) {
 		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(htmlnogame))
-		matchdata, err := GetMatchSchedule(doc)
+		matchdata, err := GetMatchSchedule(doc, time.Now())
 
 		//エラーが発生せず処理が終了しているか確認
 		assert.NoError(t, err)
@@ -161,6 +161,30 @@ func TestGetmatchschedulemock(t *testing.T) {
 		assert.Nil(t, matchdata)
 
 	})
+	// リンクがない試合がある場合は、その試合を日程から消さないようエラー
+	t.Run("Error game without link", func(t *testing.T) {
+		html := strings.Replace(html1league2games, `<div class="bb-score__content" href="test2/index"></div>`, `<div class="bb-score__content"></div>`, 1)
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+		matchdata, err := GetMatchSchedule(doc, time.Now())
+
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "schedule.link", perr.Field)
+			assert.Equal(t, "Fighters-Hawks", perr.Value)
+		}
+		assert.Nil(t, matchdata)
+	})
+
+	// 試合も試合がない表示もない場合はページの構成が変わったものとしてエラー
+	t.Run("Error no games and no message", func(t *testing.T) {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<div class="bb-schedule"></div>`))
+		_, err := GetMatchSchedule(doc, time.Now())
+
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "schedule.item", perr.Field)
+		}
+	})
 
 }
 
@@ -177,7 +201,7 @@ func TestGetmatchscheduleprod(t *testing.T) {
 		doc, err := scraper.GetBody(res)
 		assert.NoError(t, err)
 
-		matchdata, err := GetMatchSchedule(doc)
+		matchdata, err := GetMatchSchedule(doc, time.Now())
 		assert.NoError(t, err)
 
 		assert.Nil(t, matchdata)
@@ -0,0 +1,222 @@
+package fetcher
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/utils"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 選手ページのリンクに含まれる選手ID（例: /npb/player/1234567/top）
+var playerIDPattern = regexp.MustCompile(`/player/([0-9]+)`)
+
+// 選手一覧の1行（列の見出しのキーごとの値）
+type playerRow struct {
+	id     int
+	number string
+	name   string
+	values map[string]string
+}
+
+// 選手一覧ページ（野手）から選手と打撃成績を取得
+func GetBatters(doc *goquery.Document) ([]models.Player, error) {
+	sel := CurrentSelectors().Players
+	rows, err := readPlayerTable(doc, sel, sel.Batting)
+	if err != nil {
+		return nil, err
+	}
+	players := make([]models.Player, 0, len(rows))
+	for _, row := range rows {
+		p := statParser{values: row.values, prefix: "players.batting."}
+		stats := &models.BattingStats{
+			Games:            p.count("games"),
+			PlateAppearances: p.count("plate_appearances"),
+			AtBats:           p.count("at_bats"),
+			Hits:             p.count("hits"),
+			HomeRuns:         p.count("home_runs"),
+			RBI:              p.count("rbi"),
+			StolenBases:      p.count("stolen_bases"),
+			Walks:            p.count("walks"),
+			Strikeouts:       p.count("strikeouts"),
+			Avg:              p.rate("avg"),
+			OBP:              p.rate("obp"),
+			SLG:              p.rate("slg"),
+			OPS:              p.rate("ops"),
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		players = append(players, models.Player{
+			ID:       row.id,
+			Name:     row.name,
+			Number:   row.number,
+			Position: models.PositionBatter,
+			Batting:  stats,
+		})
+	}
+	return players, nil
+}
+
+// 選手一覧ページ（投手）から選手と投球成績を取得
+func GetPitchers(doc *goquery.Document) ([]models.Player, error) {
+	sel := CurrentSelectors().Players
+	rows, err := readPlayerTable(doc, sel, sel.Pitching)
+	if err != nil {
+		return nil, err
+	}
+	players := make([]models.Player, 0, len(rows))
+	for _, row := range rows {
+		p := statParser{values: row.values, prefix: "players.pitching."}
+		stats := &models.PitchingStats{
+			Games:      p.count("games"),
+			Wins:       p.count("wins"),
+			Losses:     p.count("losses"),
+			Saves:      p.count("saves"),
+			Holds:      p.count("holds"),
+			Innings:    p.innings("innings"),
+			Strikeouts: p.count("strikeouts"),
+			Walks:      p.count("walks"),
+			EarnedRuns: p.count("earned_runs"),
+			ERA:        p.rate("era"),
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		players = append(players, models.Player{
+			ID:       row.id,
+			Name:     row.name,
+			Number:   row.number,
+			Position: models.PositionPitcher,
+			Pitching: stats,
+		})
+	}
+	return players, nil
+}
+
+// 選手一覧の表を見出しで列を探して読み込む（statsは成績のキーと見出し）
+// 見出しより列の少ない行（区切りなど）は読み飛ばす
+func readPlayerTable(doc *goquery.Document, sel PlayerSelectors, stats map[string]string) ([]playerRow, error) {
+	index := map[string]int{}
+	headers := utils.GetElement(doc, sel.Header)
+	headers.Each(func(i int, th *goquery.Selection) {
+		label := strings.TrimSpace(th.Text())
+		if _, ok := index[label]; !ok {
+			index[label] = i
+		}
+	})
+	column := func(label string) (int, error) {
+		i, ok := index[label]
+		if !ok {
+			return 0, &ParseError{Field: "players.header", Value: label, Reason: "column not found"}
+		}
+		return i, nil
+	}
+	numberCol, err := column(sel.Number)
+	if err != nil {
+		return nil, err
+	}
+	nameCol, err := column(sel.Name)
+	if err != nil {
+		return nil, err
+	}
+	statCols := make(map[string]int, len(stats))
+	for key, label := range stats {
+		if statCols[key], err = column(label); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []playerRow
+	var parseErr error
+	utils.GetElement(doc, sel.Row).EachWithBreak(func(i int, tr *goquery.Selection) bool {
+		cells := tr.Find(sel.Cell)
+		if cells.Length() < headers.Length() {
+			return true
+		}
+		text := func(col int) string { return strings.TrimSpace(cells.Eq(col).Text()) }
+
+		href, _ := tr.Find(sel.Link).Attr("href")
+		m := playerIDPattern.FindStringSubmatch(href)
+		if m == nil {
+			parseErr = &ParseError{Field: "players.link", Value: href, Reason: "no player id"}
+			return false
+		}
+		id, _ := strconv.Atoi(m[1])
+		row := playerRow{id: id, number: text(numberCol), name: text(nameCol), values: make(map[string]string, len(statCols))}
+		if row.name == "" {
+			parseErr = &ParseError{Field: "players.name", Reason: "empty"}
+			return false
+		}
+		for key, col := range statCols {
+			row.values[key] = text(col)
+		}
+		rows = append(rows, row)
+		return true
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if len(rows) == 0 {
+		return nil, &ParseError{Field: "players.row", Reason: "no players"}
+	}
+	return rows, nil
+}
+
+// 成績の値を変換する（最初に見つかった不正な値をerrに残す）
+type statParser struct {
+	values map[string]string
+	prefix string
+	err    error
+}
+
+func (p *statParser) fail(key, value, reason string) {
+	if p.err == nil {
+		p.err = &ParseError{Field: p.prefix + key, Value: value, Reason: reason}
+	}
+}
+
+// 回数（試合数・本塁打など）
+func (p *statParser) count(key string) int {
+	v := p.values[key]
+	if !runsPattern.MatchString(v) {
+		p.fail(key, v, "not a number")
+		return 0
+	}
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+// 率（打率・防御率など）。未算出（空・-）の場合はnil
+func (p *statParser) rate(key string) *float64 {
+	v := p.values[key]
+	if v == "" || v == "-" {
+		return nil
+	}
+	if !ratePattern.MatchString(v) {
+		p.fail(key, v, "not a rate")
+		return nil
+	}
+	f, _ := strconv.ParseFloat(v, 64)
+	return &f
+}
+
+// 投球回（"52 1/3"・"52.1"は"52.1"にそろえる）
+func (p *statParser) innings(key string) string {
+	v := p.values[key]
+	m := inningsPitchedPattern.FindStringSubmatch(v)
+	if m == nil || v == "" {
+		p.fail(key, v, "not innings pitched")
+		return ""
+	}
+	whole, third := m[1], m[2]+m[3]
+	if whole == "" {
+		whole = "0"
+	}
+	if third == "" {
+		return whole
+	}
+	return whole + "." + third
+}
@@ -0,0 +1,155 @@
+package fetcher
+
+import (
+	"baseball_report/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+// 選手一覧ページを作る（各行の先頭2列は背番号・選手名、選手名に選手ページへのリンクを付ける）
+func playerPage(headers []string, rows ...[]string) *goquery.Document {
+	html := `<table class="bb-playerTable"><thead><tr>`
+	for _, h := range headers {
+		html += `<th>` + h + `</th>`
+	}
+	html += `</tr></thead><tbody>`
+	for _, row := range rows {
+		html += `<tr><td>` + row[0] + `</td><td><a href="/npb/player/` + row[1] + `/top">` + row[2] + `</a></td>`
+		for _, v := range row[3:] {
+			html += `<td>` + v + `</td>`
+		}
+		html += `</tr>`
+	}
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html + `</tbody></table>`))
+	return doc
+}
+
+var battingHeaders = []string{"背番号", "選手名", "投打", "打率", "試合", "打席", "打数", "安打", "本塁打", "打点", "盗塁", "四球", "三振", "出塁率", "長打率", "OPS"}
+
+var pitchingHeaders = []string{"背番号", "選手名", "投打", "防御率", "登板", "勝利", "敗戦", "セーブ", "ホールド", "投球回", "奪三振", "与四球", "自責点"}
+
+func TestGetBatters(t *testing.T) {
+	t.Run("Success get batters", func(t *testing.T) {
+		doc := playerPage(battingHeaders,
+			[]string{"25", "1100001", "村上 宗隆", "右左", ".312", "140", "600", "510", "159", "40", "110", "5", "80", "130", ".420", ".600", "1.020"},
+			//打数が0の選手は率が未算出
+			[]string{"0", "1100002", "山田 太郎", "右右", "-", "1", "0", "0", "0", "0", "0", "0", "0", "0", "-", "-", "-"},
+		)
+		players, err := GetBatters(doc)
+		assert.NoError(t, err)
+		if assert.Len(t, players, 2) {
+			avg, ops := 0.312, 1.02
+			assert.Equal(t, 1100001, players[0].ID)
+			assert.Equal(t, "村上 宗隆", players[0].Name)
+			assert.Equal(t, "25", players[0].Number)
+			assert.Equal(t, models.PositionBatter, players[0].Position)
+			assert.Nil(t, players[0].Pitching)
+			assert.Equal(t, 40, players[0].Batting.HomeRuns)
+			assert.Equal(t, 510, players[0].Batting.AtBats)
+			assert.Equal(t, &avg, players[0].Batting.Avg)
+			assert.Equal(t, &ops, players[0].Batting.OPS)
+			assert.Nil(t, players[1].Batting.Avg)
+		}
+	})
+
+	t.Run("Missing column", func(t *testing.T) {
+		headers := append([]string{}, battingHeaders[:len(battingHeaders)-1]...)
+		doc := playerPage(headers, []string{"25", "1100001", "村上 宗隆", "右左", ".312", "140", "600", "510", "159", "40", "110", "5", "80", "130", ".420", ".600"})
+		_, err := GetBatters(doc)
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "players.header", perr.Field)
+			assert.Equal(t, "OPS", perr.Value)
+		}
+	})
+
+	t.Run("Invalid stat", func(t *testing.T) {
+		doc := playerPage(battingHeaders,
+			[]string{"25", "1100001", "村上 宗隆", "右左", ".312", "140", "600", "510", "159", "四十", "110", "5", "80", "130", ".420", ".600", "1.020"})
+		_, err := GetBatters(doc)
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "players.batting.home_runs", perr.Field)
+		}
+	})
+
+	t.Run("No players", func(t *testing.T) {
+		_, err := GetBatters(playerPage(battingHeaders))
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "players.row", perr.Field)
+		}
+	})
+}
+
+func TestGetPitchers(t *testing.T) {
+	t.Run("Success get pitchers", func(t *testing.T) {
+		doc := playerPage(pitchingHeaders,
+			[]string{"18", "1200001", "山本 由伸", "右右", "1.21", "23", "16", "6", "0", "0", "164 1/3", "169", "28", "22"},
+			[]string{"41", "1200002", "鈴木 一郎", "右右", "-", "0", "0", "0", "0", "0", "0", "0", "0", "0"},
+			[]string{"47", "1200003", "佐藤 次郎", "左左", "0.00", "1", "0", "0", "0", "1", "2/3", "1", "0", "0"},
+		)
+		players, err := GetPitchers(doc)
+		assert.NoError(t, err)
+		if assert.Len(t, players, 3) {
+			era := 1.21
+			assert.Equal(t, models.PositionPitcher, players[0].Position)
+			assert.Nil(t, players[0].Batting)
+			assert.Equal(t, 16, players[0].Pitching.Wins)
+			assert.Equal(t, "164.1", players[0].Pitching.Innings)
+			assert.Equal(t, &era, players[0].Pitching.ERA)
+			assert.Equal(t, "0", players[1].Pitching.Innings)
+			assert.Nil(t, players[1].Pitching.ERA)
+			assert.Equal(t, "0.2", players[2].Pitching.Innings)
+		}
+	})
+
+	t.Run("Invalid innings", func(t *testing.T) {
+		doc := playerPage(pitchingHeaders,
+			[]string{"18", "1200001", "山本 由伸", "右右", "1.21", "23", "16", "6", "0", "0", "164.5", "169", "28", "22"})
+		_, err := GetPitchers(doc)
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "players.pitching.innings", perr.Field)
+		}
+	})
+
+	//選手ページへのリンクがない行は選手IDが分からない
+	t.Run("No player link", func(t *testing.T) {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`
+			<table class="bb-playerTable">
+				<thead><tr><th>背番号</th><th>選手名</th></tr></thead>
+				<tbody><tr><td>18</td><td>山本 由伸</td></tr></tbody>
+			</table>`))
+		s, _ := LoadSelectors("")
+		custom := *s
+		custom.Players.Pitching = map[string]string{}
+		useSelectors(t, &custom)
+
+		_, err := GetPitchers(doc)
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "players.link", perr.Field)
+		}
+	})
+}
+
+// 選手一覧ページは列の見出しも検証する
+func TestValidateSelectors_Players(t *testing.T) {
+	s, _ := LoadSelectors("")
+	doc := playerPage(battingHeaders[:len(battingHeaders)-1],
+		[]string{"25", "1100001", "村上 宗隆", "右左", ".312", "140", "600", "510", "159", "40", "110", "5", "80", "130", ".420", ".600"})
+
+	results, err := ValidateSelectors(doc, s, "batting")
+	assert.NoError(t, err)
+	var missing []string
+	for _, r := range results {
+		if r.Missing() {
+			missing = append(missing, r.Name)
+		}
+	}
+	assert.Equal(t, []string{"players.batting.ops"}, missing)
+}
@@ -2,30 +2,44 @@ package fetcher
 
 import (
 	"baseball_report/utils"
+	"fmt"
+	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
 func GetMatchScore(doc *goquery.Document) ([][]string, error) {
 
+	sel := CurrentSelectors().Score
+
 	// 2次元配列で試合進捗を格納
 	var scoreData [][]string
 	var teamscore []string
 
 	//イニングを取得
-	inning := utils.GetText(doc, ".live em")
+	inning := utils.GetText(doc, sel.Inning)
 	//両チームのスコアを取得
-	utils.GetElement(doc, "tr").Each(func(i int, s *goquery.Selection) {
-		score := s.Find("td").Eq(1).Text()
-		if score != "" {
-			teamscore = append(teamscore, score)
+	//試合前・中止は得点のセルが空のため、空でもセルがある行は残して検証で判断する
+	utils.GetElement(doc, sel.Row).Each(func(i int, s *goquery.Selection) {
+		cell := s.Find(sel.Cell).Eq(sel.RunColumn)
+		if cell.Length() == 0 {
+			return
 		}
+		teamscore = append(teamscore, strings.TrimSpace(cell.Text()))
 	})
 	//進捗を取得
-	result := utils.GetText(doc, "div#result")
+	result := utils.GetText(doc, sel.Result)
 
 	// 打者情報を取得
-	batter := utils.GetText(doc, "table#batt a")
+	batter := utils.GetText(doc, sel.Batter)
+
+	// 得点は先攻（away）・後攻（home）の順に2行
+	if len(teamscore) < 2 {
+		return nil, &ParseError{Field: "score.cell", Value: strings.Join(teamscore, ","), Reason: fmt.Sprintf("found %d score cells, want 2", len(teamscore))}
+	}
+	if err := validateScore(inning, teamscore[1], teamscore[0]); err != nil {
+		return nil, err
+	}
 
 	scoreData = append(scoreData, []string{inning, teamscore[1], teamscore[0], batter, result})
 
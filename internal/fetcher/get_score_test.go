@@ -99,7 +99,80 @@ func TestGetmatchscoremock(t *testing.T) {
 		assert.Equal(t, expected, scoredata)
 
 	})
+	//試合前・中止は得点のセルが空でもエラーにしない
+	t.Run("Success get before game", func(t *testing.T) {
+		for _, inning := range []string{"試合前", "試合中止"} {
+			html := strings.NewReplacer("5回裏", inning, "<td>0</td>", "<td></td>", "<td>2</td>", "<td> </td>").Replace(inninghtml)
+			doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+			scoredata, err := GetMatchScore(doc)
+
+			assert.NoError(t, err, inning)
+			assert.Equal(t, [][]string{{inning, "", "", "山田 太郎", "ヒットで1塁"}}, scoredata, inning)
+		}
+	})
+	//試合中に得点のセルが空の場合はエラー
+	t.Run("Error empty score in game", func(t *testing.T) {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(strings.Replace(inninghtml, "<td>2</td>", "<td></td>", 1)))
+		_, err := GetMatchScore(doc)
+
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "score.home", perr.Field)
+		}
+	})
+	//スコア表がない場合はpanicせずにエラー
+	t.Run("Error missing score cells", func(t *testing.T) {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<div class="live"><em>5回裏</em></div>`))
+		_, err := GetMatchScore(doc)
+
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "score.cell", perr.Field)
+		}
+	})
+	t.Run("Error invalid inning", func(t *testing.T) {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(strings.Replace(inninghtml, "5回裏", "", 1)))
+		_, err := GetMatchScore(doc)
+
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "score.inning", perr.Field)
+		}
+	})
+
+}
 
+func TestValidateScore(t *testing.T) {
+	cases := []struct {
+		name       string
+		inning     string
+		home, away string
+		field      string
+	}{
+		{name: "inning", inning: "5回表", home: "3", away: "12"},
+		{name: "extra inning", inning: "10回裏", home: "0", away: "0"},
+		{name: "finished", inning: "試合終了", home: "4", away: "2"},
+		//試合前・中止は得点が表示されない
+		{name: "before game", inning: "試合前", home: "", away: "-"},
+		{name: "cancelled", inning: "試合中止", home: "", away: ""},
+		{name: "unknown status", inning: "5回", home: "1", away: "0", field: "score.inning"},
+		{name: "selector mismatch", inning: "", home: "", away: "", field: "score.inning"},
+		{name: "not a number", inning: "3回表", home: "オ", away: "0", field: "score.home"},
+		{name: "empty score in game", inning: "3回表", home: "1", away: "", field: "score.away"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateScore(c.inning, c.home, c.away)
+			if c.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var perr *ParseError
+			if assert.ErrorAs(t, err, &perr) {
+				assert.Equal(t, c.field, perr.Field)
+			}
+		})
+	}
 }
 
 // TestGetmatchscoreprod： 本番から試合情報取得するケースをテスト
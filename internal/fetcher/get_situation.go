@@ -0,0 +1,49 @@
+package fetcher
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/utils"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 試合速報ページから試合中の状況（投手・カウント・アウト・走者）を取得
+// 試合中（inningがN回表・N回裏）でない場合、または表示されていない項目はnil
+func GetSituation(doc *goquery.Document, inning string) (models.Situation, error) {
+	var situation models.Situation
+	if !inningPattern.MatchString(inning) {
+		return situation, nil
+	}
+	sel := CurrentSelectors().Situation
+
+	if pitcher := utils.GetText(doc, sel.Pitcher); pitcher != "" {
+		situation.Pitcher = &pitcher
+	}
+	situation.Balls = countLamps(doc, sel.Balls, sel.Lamp)
+	situation.Strikes = countLamps(doc, sel.Strikes, sel.Lamp)
+	situation.Outs = countLamps(doc, sel.Outs, sel.Lamp)
+
+	if bases := utils.GetElement(doc, sel.Bases); bases.Length() != 0 {
+		situation.Bases = &models.Bases{
+			First:  bases.Find(sel.First).Length() != 0,
+			Second: bases.Find(sel.Second).Length() != 0,
+			Third:  bases.Find(sel.Third).Length() != 0,
+		}
+	}
+
+	if err := validateSituation(situation); err != nil {
+		return models.Situation{}, err
+	}
+	return situation, nil
+}
+
+// 要素内の点灯したランプの数（要素がない場合はnil）
+func countLamps(doc *goquery.Document, selector, lamp string) *int {
+	elem := utils.GetElement(doc, selector)
+	if elem.Length() == 0 {
+		return nil
+	}
+	n := strings.Count(elem.First().Text(), lamp)
+	return &n
+}
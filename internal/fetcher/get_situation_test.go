@@ -0,0 +1,82 @@
+package fetcher
+
+import (
+	"baseball_report/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSituation(t *testing.T) {
+	situationhtml := `<body>
+    <!-- 投手情報 -->
+    <table id="pit">
+        <tr>
+            <td><a href="/player2">佐藤 一郎</a></td>
+        </tr>
+    </table>
+
+    <!-- カウント -->
+    <div id="sbo">
+        <p class="b"><b>●●●</b></p>
+        <p class="s"><b>●●</b></p>
+        <p class="o"><b>●</b></p>
+    </div>
+
+    <!-- 走者 -->
+    <div id="base">
+        <div id="base1"></div>
+        <div id="base2"><a href="/player3">田中</a></div>
+        <div id="base3"><a href="/player4">鈴木</a></div>
+    </div>
+</body>`
+	page := func(html string) *goquery.Document {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+		return doc
+	}
+	ptr := func(n int) *int { return &n }
+
+	t.Run("Success get situation", func(t *testing.T) {
+		situation, err := GetSituation(page(situationhtml), "7回表")
+		assert.NoError(t, err)
+
+		pitcher := "佐藤 一郎"
+		assert.Equal(t, models.Situation{
+			Pitcher: &pitcher,
+			Balls:   ptr(3),
+			Strikes: ptr(2),
+			Outs:    ptr(1),
+			Bases:   &models.Bases{First: false, Second: true, Third: true},
+		}, situation)
+	})
+
+	//試合中でない場合は全てnil
+	t.Run("Not in progress", func(t *testing.T) {
+		for _, inning := range []string{"試合前", "試合終了", "試合中止"} {
+			situation, err := GetSituation(page(situationhtml), inning)
+			assert.NoError(t, err)
+			assert.Equal(t, models.Situation{}, situation, inning)
+		}
+	})
+
+	//表示されていない項目はnil、カウントのランプが消えている場合は0
+	t.Run("Missing items", func(t *testing.T) {
+		situation, err := GetSituation(page(`<div id="sbo"><p class="b"><b></b></p></div>`), "1回表")
+		assert.NoError(t, err)
+		assert.Nil(t, situation.Pitcher)
+		assert.Equal(t, ptr(0), situation.Balls)
+		assert.Nil(t, situation.Strikes)
+		assert.Nil(t, situation.Bases)
+	})
+
+	t.Run("Error invalid count", func(t *testing.T) {
+		_, err := GetSituation(page(`<div id="sbo"><p class="s"><b>●●●</b></p></div>`), "1回表")
+
+		var perr *ParseError
+		if assert.ErrorAs(t, err, &perr) {
+			assert.Equal(t, "situation.strikes", perr.Field)
+		}
+	})
+}
@@ -0,0 +1,427 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// 対応するセレクタ定義のバージョン
+const SelectorsVersion = 4
+
+// セレクタ定義ファイルの変更を確認する間隔
+const selectorsReloadInterval = 30 * time.Second
+
+// 既定のセレクタ定義（Yahoo!プロ野球）
+//
+//go:embed selectors.json
+var defaultSelectors []byte
+
+// Selectors HTMLの解析に使うCSSセレクタの定義
+type Selectors struct {
+	Version   int                `json:"version"`
+	Schedule  ScheduleSelectors  `json:"schedule"`
+	Score     ScoreSelectors     `json:"score"`
+	LineScore LineScoreSelectors `json:"line_score"`
+	Situation SituationSelectors `json:"situation"`
+	Players   PlayerSelectors    `json:"players"`
+}
+
+// ScheduleSelectors 日程ページ（GetMatchSchedule）のセレクタ
+// title以降はleague内、home以降はitem内から探す
+type ScheduleSelectors struct {
+	League    string `json:"league"`
+	Title     string `json:"title"`
+	NoData    string `json:"no_data"`
+	Item      string `json:"item"`
+	Home      string `json:"home"`
+	Away      string `json:"away"`
+	Venue     string `json:"venue"`
+	Status    string `json:"status"`
+	StartTime string `json:"start_time"`
+	Link      string `json:"link"`
+}
+
+// ScoreSelectors 試合速報ページ（GetMatchScore）のセレクタ
+// 得点はrowごとにrun_column番目（0始まり）のcellから取得する
+type ScoreSelectors struct {
+	Inning    string `json:"inning"`
+	Row       string `json:"row"`
+	Cell      string `json:"cell"`
+	RunColumn int    `json:"run_column"`
+	Result    string `json:"result"`
+	Batter    string `json:"batter"`
+}
+
+// LineScoreSelectors 試合速報ページ（GetLineScore）のラインスコアのセレクタ
+// rowは先攻・後攻の順に一致し、team以降はrow内から探す。inningはイニングごとのセルに一致する
+type LineScoreSelectors struct {
+	Row    string `json:"row"`
+	Team   string `json:"team"`
+	Inning string `json:"inning"`
+	Runs   string `json:"runs"`
+	Hits   string `json:"hits"`
+	Errors string `json:"errors"`
+}
+
+// SituationSelectors 試合速報ページ（GetSituation）の試合中の状況のセレクタ
+// balls・strikes・outsは要素内のlampの数を数える。first以降はbases内から探し、走者がいる場合のみ一致する
+type SituationSelectors struct {
+	Pitcher string `json:"pitcher"`
+	Balls   string `json:"balls"`
+	Strikes string `json:"strikes"`
+	Outs    string `json:"outs"`
+	Lamp    string `json:"lamp"`
+	Bases   string `json:"bases"`
+	First   string `json:"first"`
+	Second  string `json:"second"`
+	Third   string `json:"third"`
+}
+
+// PlayerSelectors 選手一覧ページ（GetBatters・GetPitchers）のセレクタ
+// 列は見出し（header）の文字列で探す。number・nameと、batting・pitchingの各成績の値は見出しの文字列
+// cell以降はrow内から探し、linkのhrefから選手IDを取得する
+type PlayerSelectors struct {
+	Header   string            `json:"header"`
+	Row      string            `json:"row"`
+	Cell     string            `json:"cell"`
+	Link     string            `json:"link"`
+	Number   string            `json:"number"`
+	Name     string            `json:"name"`
+	Batting  map[string]string `json:"batting"`
+	Pitching map[string]string `json:"pitching"`
+}
+
+// 選手一覧ページの成績の列（PlayerSelectorsのbatting・pitchingのキー）
+var (
+	battingColumns  = []string{"games", "plate_appearances", "at_bats", "hits", "home_runs", "rbi", "stolen_bases", "walks", "strikeouts", "avg", "obp", "slg", "ops"}
+	pitchingColumns = []string{"games", "wins", "losses", "saves", "holds", "innings", "strikeouts", "walks", "earned_runs", "era"}
+)
+
+// 検証用のセレクタ（pathは親から順に辿るセレクタ）
+type selectorField struct {
+	name     string
+	path     []string
+	optional bool
+}
+
+func (s *Selectors) scheduleFields() []selectorField {
+	sc := s.Schedule
+	inItem := func(sel string) []string { return []string{sc.League, sc.Item, sel} }
+	return []selectorField{
+		{name: "schedule.league", path: []string{sc.League}},
+		{name: "schedule.title", path: []string{sc.League, sc.Title}},
+		//試合がある日は見つからない
+		{name: "schedule.no_data", path: []string{sc.League, sc.NoData}, optional: true},
+		{name: "schedule.item", path: []string{sc.League, sc.Item}},
+		{name: "schedule.home", path: inItem(sc.Home)},
+		{name: "schedule.away", path: inItem(sc.Away)},
+		{name: "schedule.venue", path: inItem(sc.Venue)},
+		{name: "schedule.status", path: inItem(sc.Status)},
+		{name: "schedule.start_time", path: inItem(sc.StartTime)},
+		{name: "schedule.link", path: inItem(sc.Link)},
+	}
+}
+
+func (s *Selectors) scoreFields() []selectorField {
+	sc, ls, st := s.Score, s.LineScore, s.Situation
+	return []selectorField{
+		{name: "score.inning", path: []string{sc.Inning}},
+		{name: "score.row", path: []string{sc.Row}},
+		{name: "score.cell", path: []string{sc.Row, sc.Cell}},
+		{name: "score.result", path: []string{sc.Result}},
+		{name: "score.batter", path: []string{sc.Batter}},
+		{name: "line_score.row", path: []string{ls.Row}},
+		{name: "line_score.team", path: []string{ls.Row, ls.Team}},
+		{name: "line_score.inning", path: []string{ls.Row, ls.Inning}},
+		{name: "line_score.runs", path: []string{ls.Row, ls.Runs}},
+		{name: "line_score.hits", path: []string{ls.Row, ls.Hits}},
+		{name: "line_score.errors", path: []string{ls.Row, ls.Errors}},
+		//試合前・試合終了のページには表示されない
+		{name: "situation.pitcher", path: []string{st.Pitcher}, optional: true},
+		{name: "situation.balls", path: []string{st.Balls}, optional: true},
+		{name: "situation.strikes", path: []string{st.Strikes}, optional: true},
+		{name: "situation.outs", path: []string{st.Outs}, optional: true},
+		{name: "situation.bases", path: []string{st.Bases}, optional: true},
+		//走者がいない場合は見つからない
+		{name: "situation.first", path: []string{st.Bases, st.First}, optional: true},
+		{name: "situation.second", path: []string{st.Bases, st.Second}, optional: true},
+		{name: "situation.third", path: []string{st.Bases, st.Third}, optional: true},
+	}
+}
+
+func (s *Selectors) playerFields() []selectorField {
+	pl := s.Players
+	return []selectorField{
+		{name: "players.header", path: []string{pl.Header}},
+		{name: "players.row", path: []string{pl.Row}},
+		{name: "players.cell", path: []string{pl.Row, pl.Cell}},
+		{name: "players.link", path: []string{pl.Row, pl.Link}},
+	}
+}
+
+// 選手一覧ページ（kindはbattingまたはpitching）の列の見出し（キーは検証結果の名前）
+func (s *Selectors) playerColumns(kind string) map[string]string {
+	pl := s.Players
+	columns := map[string]string{"players.number": pl.Number, "players.name": pl.Name}
+	stats := pl.Batting
+	if kind == "pitching" {
+		stats = pl.Pitching
+	}
+	for key, label := range stats {
+		columns["players."+kind+"."+key] = label
+	}
+	return columns
+}
+
+// 定義を検証する（バージョン・未設定・CSSセレクタとして不正な値）
+func (s *Selectors) validate() error {
+	if s.Version < 1 || s.Version > SelectorsVersion {
+		return fmt.Errorf("unsupported selectors version %d (want 1 to %d)", s.Version, SelectorsVersion)
+	}
+	var errs []error
+	fields := append(s.scheduleFields(), s.scoreFields()...)
+	for _, f := range append(fields, s.playerFields()...) {
+		sel := f.path[len(f.path)-1]
+		if sel == "" {
+			errs = append(errs, fmt.Errorf("%s is empty", f.name))
+			continue
+		}
+		if _, err := cascadia.Compile(sel); err != nil {
+			errs = append(errs, fmt.Errorf("%s %q is invalid: %w", f.name, sel, err))
+		}
+	}
+	if s.Players.Number == "" {
+		errs = append(errs, fmt.Errorf("players.number is empty"))
+	}
+	if s.Players.Name == "" {
+		errs = append(errs, fmt.Errorf("players.name is empty"))
+	}
+	for _, c := range []struct {
+		name    string
+		labels  map[string]string
+		columns []string
+	}{{"players.batting", s.Players.Batting, battingColumns}, {"players.pitching", s.Players.Pitching, pitchingColumns}} {
+		for _, key := range c.columns {
+			if c.labels[key] == "" {
+				errs = append(errs, fmt.Errorf("%s.%s is empty", c.name, key))
+			}
+		}
+		for key := range c.labels {
+			if !slices.Contains(c.columns, key) {
+				errs = append(errs, fmt.Errorf("%s.%s is unknown", c.name, key))
+			}
+		}
+	}
+	if s.Situation.Lamp == "" {
+		errs = append(errs, fmt.Errorf("situation.lamp is empty"))
+	}
+	if s.Score.RunColumn < 0 {
+		errs = append(errs, fmt.Errorf("score.run_column must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// JSONのセレクタ定義を読み込む
+// 定義にない項目は既定の定義の値を使う（古いバージョンの定義も、後から追加された項目を補って使える）
+func ParseSelectors(data []byte) (*Selectors, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode selectors: %w", err)
+	}
+	s := &Selectors{}
+	if err := decodeSelectors(defaultSelectors, s); err != nil {
+		return nil, err
+	}
+	if err := decodeSelectors(data, s); err != nil {
+		return nil, err
+	}
+	//versionがない定義は既定の定義のversionにしない
+	s.Version = header.Version
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid selectors: %w", err)
+	}
+	if s.Version < SelectorsVersion {
+		log.Printf("selectors version %d is older than %d: missing selectors use the defaults", s.Version, SelectorsVersion)
+	}
+	return s, nil
+}
+
+// sにJSONの値を上書きする（JSONにない項目はsの値のまま）
+func decodeSelectors(data []byte, s *Selectors) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(s); err != nil {
+		return fmt.Errorf("failed to decode selectors: %w", err)
+	}
+	return nil
+}
+
+// ファイルからセレクタ定義を読み込む（pathが空の場合は既定の定義）
+func LoadSelectors(path string) (*Selectors, error) {
+	if path == "" {
+		return ParseSelectors(defaultSelectors)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selectors: %w", err)
+	}
+	return ParseSelectors(data)
+}
+
+// 解析に使用中のセレクタ定義
+var current atomic.Pointer[Selectors]
+
+func init() {
+	s, err := LoadSelectors("")
+	if err != nil {
+		panic(err)
+	}
+	current.Store(s)
+}
+
+// 使用中のセレクタ定義
+func CurrentSelectors() *Selectors {
+	return current.Load()
+}
+
+// セレクタ定義を差し替える
+func SetSelectors(s *Selectors) {
+	current.Store(s)
+}
+
+// 環境変数SELECTORS_FILEのセレクタ定義を読み込み、ファイルの変更を監視して再読み込みする
+// 未設定の場合は既定の定義を使う。不正な定義の場合はエラー（再読み込み時は使用中の定義を維持）
+func StartSelectors(ctx context.Context) error {
+	path := os.Getenv("SELECTORS_FILE")
+	if path == "" {
+		return nil
+	}
+	s, err := LoadSelectors(path)
+	if err != nil {
+		return err
+	}
+	SetSelectors(s)
+	log.Println("Loaded selectors:", path)
+	go watchSelectors(ctx, path, selectorsReloadInterval)
+	return nil
+}
+
+// ファイルの更新時刻が変わったら再読み込みする
+func watchSelectors(ctx context.Context, path string, interval time.Duration) {
+	var modified time.Time
+	if info, err := os.Stat(path); err == nil {
+		modified = info.ModTime()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modified = reloadSelectors(path, modified)
+		}
+	}
+}
+
+// 前回確認した更新時刻から変わっていれば再読み込みし、確認した更新時刻を返す
+func reloadSelectors(path string, modified time.Time) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to stat selectors: %w", err))
+		return modified
+	}
+	if info.ModTime().Equal(modified) {
+		return modified
+	}
+	s, err := LoadSelectors(path)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to reload selectors, keeping current: %w", err))
+		return info.ModTime()
+	}
+	SetSelectors(s)
+	log.Println("Reloaded selectors:", path)
+	return info.ModTime()
+}
+
+// SelectorResult 保存したページに対するセレクタの検証結果
+type SelectorResult struct {
+	Name     string
+	Selector string
+	Matches  int
+	Optional bool
+}
+
+// 見つからなかった必須のセレクタか
+func (r SelectorResult) Missing() bool {
+	return r.Matches == 0 && !r.Optional
+}
+
+// 保存したページ（kindはschedule・score・batting・pitching）に対して各セレクタが一致した数を返す
+// 選手一覧ページ（batting・pitching）は列の見出しが一致した数も返す
+func ValidateSelectors(doc *goquery.Document, s *Selectors, kind string) ([]SelectorResult, error) {
+	var fields []selectorField
+	switch kind {
+	case "schedule":
+		fields = s.scheduleFields()
+	case "score":
+		fields = s.scoreFields()
+	case "batting", "pitching":
+		fields = s.playerFields()
+	default:
+		return nil, fmt.Errorf("unknown page kind: %s (schedule|score|batting|pitching)", kind)
+	}
+	results := make([]SelectorResult, 0, len(fields))
+	for _, f := range fields {
+		sel := doc.Selection
+		for _, p := range f.path {
+			sel = sel.Find(p)
+		}
+		results = append(results, SelectorResult{
+			Name:     f.name,
+			Selector: f.path[len(f.path)-1],
+			Matches:  sel.Length(),
+			Optional: f.optional,
+		})
+	}
+	if kind == "batting" || kind == "pitching" {
+		results = append(results, validateColumns(doc, s, kind)...)
+	}
+	return results, nil
+}
+
+// 選手一覧ページの列の見出しごとに一致した数を返す（名前の順）
+func validateColumns(doc *goquery.Document, s *Selectors, kind string) []SelectorResult {
+	var headers []string
+	doc.Find(s.Players.Header).Each(func(i int, th *goquery.Selection) {
+		headers = append(headers, strings.TrimSpace(th.Text()))
+	})
+	var results []SelectorResult
+	for name, label := range s.playerColumns(kind) {
+		matches := 0
+		for _, h := range headers {
+			if h == label {
+				matches++
+			}
+		}
+		results = append(results, SelectorResult{Name: name, Selector: label, Matches: matches})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
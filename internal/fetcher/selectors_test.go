@@ -0,0 +1,128 @@
+package fetcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+// テスト中だけセレクタ定義を差し替える
+func useSelectors(t *testing.T, s *Selectors) {
+	orig := CurrentSelectors()
+	SetSelectors(s)
+	t.Cleanup(func() { SetSelectors(orig) })
+}
+
+func TestLoadSelectors(t *testing.T) {
+	t.Run("Default selectors", func(t *testing.T) {
+		s, err := LoadSelectors("")
+		assert.NoError(t, err)
+		assert.Equal(t, SelectorsVersion, s.Version)
+		assert.Equal(t, ".bb-score__item", s.Schedule.Item)
+		assert.Equal(t, 1, s.Score.RunColumn)
+	})
+
+	//古いバージョンの定義は、定義にない項目を既定の定義で補う
+	t.Run("Older version", func(t *testing.T) {
+		s, err := ParseSelectors([]byte(`{"version": 1, "schedule": {"home": ".home-team"}, "score": {"run_column": 2}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, s.Version)
+		assert.Equal(t, ".home-team", s.Schedule.Home)
+		assert.Equal(t, ".bb-score__item", s.Schedule.Item)
+		assert.Equal(t, 2, s.Score.RunColumn)
+		def, _ := LoadSelectors("")
+		assert.Equal(t, def.LineScore, s.LineScore)
+		assert.Equal(t, def.Situation, s.Situation)
+		assert.Equal(t, def.Players, s.Players)
+	})
+
+	t.Run("Invalid selectors", func(t *testing.T) {
+		cases := map[string]string{
+			"newer version":    strings.Replace(string(defaultSelectors), `"version": 4`, `"version": 5`, 1),
+			"no version":       strings.Replace(string(defaultSelectors), `"version": 4,`, ``, 1),
+			"empty selector":   strings.Replace(string(defaultSelectors), `"table#batt a"`, `""`, 1),
+			"invalid selector": strings.Replace(string(defaultSelectors), `"table#batt a"`, `"table[batt"`, 1),
+			"missing column":   strings.Replace(string(defaultSelectors), `"ops": "OPS"`, `"ops": ""`, 1),
+			"unknown column":   strings.Replace(string(defaultSelectors), `"ops": "OPS"`, `"ops": "OPS", "war": "WAR"`, 1),
+			"unknown field":    strings.Replace(string(defaultSelectors), `"version": 4,`, `"version": 4, "pitcher": "x",`, 1),
+		}
+		for name, data := range cases {
+			_, err := ParseSelectors([]byte(data))
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+// 定義を差し替えるとパーサーが新しいセレクタで解析する
+func TestGetMatchSchedule_CustomSelectors(t *testing.T) {
+	s, _ := LoadSelectors("")
+	custom := *s
+	custom.Schedule.Home = ".home-team"
+	useSelectors(t, &custom)
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`
+		<div class="bb-score">
+			<div class="bb-score__item">
+				<div class="home-team">Lions</div>
+				<div class="bb-score__awayLogo">Giants</div>
+				<div class="bb-score__content" href="test1/index"></div>
+			</div>
+		</div>`))
+	matchdata, err := GetMatchSchedule(doc, time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, matchdata, 1) {
+		assert.Equal(t, "Lions", matchdata[0][1])
+	}
+}
+
+func TestValidateSelectors(t *testing.T) {
+	s, _ := LoadSelectors("")
+	//打者と進捗のないページ
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`
+		<div class="live"><em>5回裏</em></div>
+		<table><tr><td>オ</td><td>0</td></tr></table>`))
+
+	results, err := ValidateSelectors(doc, s, "score")
+	assert.NoError(t, err)
+	var missing []string
+	for _, r := range results {
+		if r.Missing() {
+			missing = append(missing, r.Name)
+		}
+	}
+	assert.Equal(t, []string{
+		"score.result", "score.batter",
+		"line_score.row", "line_score.team", "line_score.inning", "line_score.runs", "line_score.hits", "line_score.errors",
+	}, missing)
+
+	_, err = ValidateSelectors(doc, s, "player")
+	assert.Error(t, err)
+}
+
+// ファイルが更新されたら再読み込みし、不正な定義の場合は使用中の定義を維持する
+func TestReloadSelectors(t *testing.T) {
+	useSelectors(t, CurrentSelectors())
+	path := filepath.Join(t.TempDir(), "selectors.json")
+	assert.NoError(t, os.WriteFile(path, defaultSelectors, 0o644))
+	info, _ := os.Stat(path)
+	modified := info.ModTime()
+
+	//更新がなければ読み込まない
+	assert.Equal(t, modified, reloadSelectors(path, modified))
+
+	updated := strings.Replace(string(defaultSelectors), `".live em"`, `".inning"`, 1)
+	assert.NoError(t, os.WriteFile(path, []byte(updated), 0o644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), modified.Add(time.Second)))
+	modified = reloadSelectors(path, modified)
+	assert.Equal(t, ".inning", CurrentSelectors().Score.Inning)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), modified.Add(time.Second)))
+	reloadSelectors(path, modified)
+	assert.Equal(t, ".inning", CurrentSelectors().Score.Inning)
+}
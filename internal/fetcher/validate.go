@@ -0,0 +1,114 @@
+package fetcher
+
+import (
+	"baseball_report/internal/models"
+	"fmt"
+	"regexp"
+)
+
+// ParseError 解析結果が想定した形式でない（ページの構造が変わった可能性がある）
+type ParseError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// 得点（数字）
+var runsPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// 試合中のイニング（例: 5回表、10回裏）
+var inningPattern = regexp.MustCompile(`^[0-9]+回[表裏]$`)
+
+// ラインスコアのイニングの得点（未実施は空・-、不要な裏の攻撃はX）
+var lineInningPattern = regexp.MustCompile(`^([0-9]+|[Xx]|-|)$`)
+
+// ラインスコアの合計（試合前は空・-）
+var lineTotalPattern = regexp.MustCompile(`^([0-9]+|-|)$`)
+
+// 選手成績の率（例: .312、1.045、3.21）
+var ratePattern = regexp.MustCompile(`^([0-9]*\.[0-9]+|[0-9]+)$`)
+
+// 投球回（例: 52、52.1、52 1/3、1/3）
+var inningsPitchedPattern = regexp.MustCompile(`^([0-9]*)(?:\.([12])| ?([12])/3)?$`)
+
+// イニング以外に表示される試合の状態と、その状態で得点が未表示でもよいか
+var knownStatuses = map[string]bool{
+	"試合前":   true,
+	"試合終了":  false,
+	"試合中止":  true,
+	"試合中断":  false,
+	"ノーゲーム": true,
+}
+
+// 試合進捗の解析結果を検証する
+func validateScore(inning, home, away string) error {
+	allowEmpty, known := knownStatuses[inning]
+	if !known && !inningPattern.MatchString(inning) {
+		return &ParseError{Field: "score.inning", Value: inning, Reason: "not an inning or a known status"}
+	}
+	for _, f := range []struct{ field, value string }{{"score.home", home}, {"score.away", away}} {
+		if allowEmpty && (f.value == "" || f.value == "-") {
+			continue
+		}
+		if !runsPattern.MatchString(f.value) {
+			return &ParseError{Field: f.field, Value: f.value, Reason: "not a number"}
+		}
+	}
+	return nil
+}
+
+// 日程の解析結果（1試合）を検証する
+func validateSchedule(home, away string) error {
+	if home == "" {
+		return &ParseError{Field: "schedule.home", Reason: "empty"}
+	}
+	if away == "" {
+		return &ParseError{Field: "schedule.away", Reason: "empty"}
+	}
+	return nil
+}
+
+// ラインスコアの解析結果を検証する
+func validateLineScore(lines []models.LineScore) error {
+	if len(lines) != 2 {
+		return &ParseError{Field: "line_score.row", Value: fmt.Sprint(len(lines)), Reason: "want 2 rows"}
+	}
+	if len(lines[0].Innings) == 0 || len(lines[0].Innings) != len(lines[1].Innings) {
+		return &ParseError{Field: "line_score.inning", Value: fmt.Sprintf("%d,%d", len(lines[0].Innings), len(lines[1].Innings)), Reason: "inning counts differ or are empty"}
+	}
+	for _, line := range lines {
+		for _, runs := range line.Innings {
+			if !lineInningPattern.MatchString(runs) {
+				return &ParseError{Field: "line_score.inning", Value: runs, Reason: "not a number"}
+			}
+		}
+		for _, f := range []struct{ field, value string }{{"line_score.runs", line.Runs}, {"line_score.hits", line.Hits}, {"line_score.errors", line.Errors}} {
+			if !lineTotalPattern.MatchString(f.value) {
+				return &ParseError{Field: f.field, Value: f.value, Reason: "not a number"}
+			}
+		}
+	}
+	return nil
+}
+
+// 試合中の状況の解析結果を検証する
+func validateSituation(situation models.Situation) error {
+	for _, f := range []struct {
+		field string
+		value *int
+		max   int
+	}{
+		{"situation.balls", situation.Balls, 3},
+		{"situation.strikes", situation.Strikes, 2},
+		{"situation.outs", situation.Outs, 3},
+	} {
+		if f.value != nil && *f.value > f.max {
+			return &ParseError{Field: f.field, Value: fmt.Sprint(*f.value), Reason: fmt.Sprintf("must be %d or less", f.max)}
+		}
+	}
+	return nil
+}
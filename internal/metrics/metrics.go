@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ParseFailures ページの解析に失敗した回数（取得元・ページ・項目ごと）
+var ParseFailures = NewCounter("baseball_report_parse_failures_total", "Number of pages whose parsed result was invalid.", "source", "page", "field")
+
+var (
+	mu       sync.Mutex
+	counters []*Counter
+)
+
+// Counter ラベルの値ごとに加算するカウンター
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]uint64
+}
+
+// カウンターを作り、/metricsの出力に登録する
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, values: map[string]uint64{}}
+	mu.Lock()
+	defer mu.Unlock()
+	counters = append(counters, c)
+	return c
+}
+
+// ラベルの値（labelsと同じ順）の件数を1加算する
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// ラベルの値（labelsと同じ順）の件数をn加算する
+func (c *Counter) Add(n uint64, values ...string) {
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] += n
+}
+
+// ラベルの値の件数
+func (c *Counter) Value(values ...string) uint64 {
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+// ラベルの値のエスケープ（Prometheusのテキスト形式）
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// ラベルをPrometheusの形式（name="value",...）にする
+func (c *Counter) key(values []string) string {
+	if len(values) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", c.name, len(c.labels), len(values)))
+	}
+	pairs := make([]string, len(values))
+	for i, v := range values {
+		pairs[i] = c.labels[i] + `="` + labelEscaper.Replace(v) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "" {
+			fmt.Fprintf(w, "%s %d\n", c.name, c.values[key])
+		} else {
+			fmt.Fprintf(w, "%s{%s} %d\n", c.name, key, c.values[key])
+		}
+	}
+}
+
+// Handler 登録済みのカウンターをPrometheusのテキスト形式で返す
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	mu.Lock()
+	defer mu.Unlock()
+	for _, c := range counters {
+		c.write(w)
+	}
+}
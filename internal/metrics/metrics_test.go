@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter(t *testing.T) {
+	c := &Counter{name: "test_total", help: "Test counter.", labels: []string{"source", "field"}, values: map[string]uint64{}}
+
+	c.Inc("yahoo", "score.inning")
+	c.Inc("yahoo", "score.inning")
+	c.Inc("yahoo", `a"b`)
+	c.Add(3, "yahoo", `a"b`)
+	assert.Equal(t, uint64(2), c.Value("yahoo", "score.inning"))
+	assert.Equal(t, uint64(4), c.Value("yahoo", `a"b`))
+	assert.Equal(t, uint64(0), c.Value("yahoo", "score.home"))
+
+	//ラベルの数が違う場合はpanic
+	assert.Panics(t, func() { c.Inc("yahoo") })
+}
+
+func TestHandler(t *testing.T) {
+	ParseFailures.Inc("yahoo", "score", "score.inning")
+
+	rec := httptest.NewRecorder()
+	Handler(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), "# TYPE baseball_report_parse_failures_total counter\n")
+	assert.Contains(t, rec.Body.String(), `baseball_report_parse_failures_total{source="yahoo",page="score",field="score.inning"} 1`)
+}
@@ -0,0 +1,268 @@
+package migrate
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// マイグレーションファイル（{version}_{name}.up.sql / {version}_{name}.down.sql）
+//
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// 読み込み元（テストで差し替えられるよう変数にする）
+var source fs.FS = mustSub(embedded, "migrations")
+
+// ErrSchemaBehind 未適用のマイグレーションがある
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+// 適用済みのバージョンを管理するテーブル
+const createVersionTable = `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INT PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)
+			`
+
+// Migration 1バージョン分のスキーマ変更
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status マイグレーションの適用状況
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt string
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// マイグレーションファイルを読み込み、バージョン順に並べる
+func Load() ([]Migration, error) {
+	files, err := fs.Glob(source, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		name := path.Base(file)
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		num, label, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+
+		body, err := fs.ReadFile(source, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if m.Name != label {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s, %s", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// SQLファイルを文単位に分割する（行末の;を区切りとし、--のコメント行は除く）
+func splitStatements(script string) []string {
+	var stmts []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// 適用済みのバージョンと適用日時を取得
+func applied(db *sql.DB) (map[int]string, error) {
+	if _, err := db.Exec(createVersionTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	versions := map[int]string{}
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations row: %w", err)
+		}
+		versions[version] = appliedAt
+	}
+	return versions, nil
+}
+
+// スクリプトを1文ずつ実行
+// MySQLのDDLは暗黙にコミットされるため、途中で失敗した場合は手動で戻す必要がある
+func execScript(db *sql.DB, m Migration, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// 未適用のマイグレーションをすべて適用し、適用したものを返す
+func Up(db *sql.DB) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if _, ok := versions[m.Version]; ok {
+			continue
+		}
+		if err := execScript(db, m, m.Up); err != nil {
+			return done, err
+		}
+		if _, err := db.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name); err != nil {
+			return done, fmt.Errorf("failed to record migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// 最後に適用したマイグレーションを1つ戻す
+// 戻すものがない場合はnilを返す
+func Down(db *sql.DB) (*Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if _, ok := versions[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+		if err := execScript(db, m, m.Down); err != nil {
+			return nil, err
+		}
+		if _, err := db.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+			return nil, fmt.Errorf("failed to remove migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Reverted migration %04d_%s", m.Version, m.Name)
+		return &m, nil
+	}
+	return nil, nil
+}
+
+// 各マイグレーションの適用状況を取得
+func GetStatus(db *sql.DB) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := versions[m.Version]
+		statuses = append(statuses, Status{Version: m.Version, Name: m.Name, Applied: ok, AppliedAt: appliedAt})
+	}
+	return statuses, nil
+}
+
+// 未適用のマイグレーションがあればErrSchemaBehindを返す
+func Check(db *sql.DB) error {
+	statuses, err := GetStatus(db)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, s := range statuses {
+		if !s.Applied {
+			pending = append(pending, fmt.Sprintf("%04d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) != 0 {
+		return fmt.Errorf("%w: pending %s (run \"main migrate up\")", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+	return nil
+}
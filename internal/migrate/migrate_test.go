@@ -0,0 +1,246 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// テスト用のマイグレーションに差し替える
+func useSource(t *testing.T, files fstest.MapFS) {
+	orig := source
+	source = files
+	t.Cleanup(func() { source = orig })
+}
+
+var testFiles = fstest.MapFS{
+	"0001_create_a.up.sql":   {Data: []byte("-- comment\nCREATE TABLE a (\n    id INT\n);\n")},
+	"0001_create_a.down.sql": {Data: []byte("DROP TABLE a;\n")},
+	"0002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT);\nCREATE INDEX idx_b ON b (id);\n")},
+	"0002_create_b.down.sql": {Data: []byte("DROP TABLE b;\n")},
+}
+
+var (
+	createTable = regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")
+	selectRows  = regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations ORDER BY version")
+	insertRow   = regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES (?, ?)")
+	deleteRow   = regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = ?")
+)
+
+// 埋め込みのマイグレーションが読み込めること
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := Load()
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, migrations) {
+		for i, m := range migrations {
+			//バージョンが1から連番で、up/downが揃っている
+			assert.Equal(t, i+1, m.Version)
+			assert.NotEmpty(t, m.Up, m.Name)
+			assert.NotEmpty(t, m.Down, m.Name)
+		}
+	}
+}
+
+func TestLoad_InvalidName(t *testing.T) {
+	useSource(t, fstest.MapFS{"create_a.up.sql": {Data: []byte("CREATE TABLE a (id INT);")}})
+
+	_, err := Load()
+	assert.Error(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := splitStatements("-- comment\nCREATE TABLE a (\n    id INT\n);\n\nDROP TABLE b;\nSELECT 1")
+	assert.Equal(t, []string{"CREATE TABLE a (\n    id INT\n)", "DROP TABLE b", "SELECT 1"}, stmts)
+}
+
+func TestUp(t *testing.T) {
+	useSource(t, testFiles)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 0001は適用済み、0002のみ適用される
+	mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(selectRows).WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, "2025-04-01 00:00:00"))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE b (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_b ON b (id)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(insertRow).WithArgs(2, "create_b").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	done, err := Up(db)
+	assert.NoError(t, err)
+	if assert.Len(t, done, 1) {
+		assert.Equal(t, 2, done[0].Version)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDown(t *testing.T) {
+	useSource(t, testFiles)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Revert latest migration", func(t *testing.T) {
+		mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectRows).WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+			AddRow(1, "2025-04-01 00:00:00").
+			AddRow(2, "2025-04-02 00:00:00"))
+		mock.ExpectExec(regexp.QuoteMeta("DROP TABLE b")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(deleteRow).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		m, err := Down(db)
+		assert.NoError(t, err)
+		if assert.NotNil(t, m) {
+			assert.Equal(t, 2, m.Version)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing to revert", func(t *testing.T) {
+		mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectRows).WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+
+		m, err := Down(db)
+		assert.NoError(t, err)
+		assert.Nil(t, m)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCheck(t *testing.T) {
+	useSource(t, testFiles)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Schema is up to date", func(t *testing.T) {
+		mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectRows).WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+			AddRow(1, "2025-04-01 00:00:00").
+			AddRow(2, "2025-04-02 00:00:00"))
+
+		assert.NoError(t, Check(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Schema is behind", func(t *testing.T) {
+		mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectRows).WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+			AddRow(1, "2025-04-01 00:00:00"))
+
+		err := Check(db)
+		assert.ErrorIs(t, err, ErrSchemaBehind)
+		assert.Contains(t, err.Error(), "0002_create_b")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// 埋め込みのマイグレーションのうちversionまでを使用する
+func useEmbedded(t *testing.T, version int) {
+	migrations, err := Load()
+	assert.NoError(t, err)
+	files := fstest.MapFS{}
+	for _, m := range migrations {
+		if m.Version <= version {
+			name := fmt.Sprintf("%04d_%s", m.Version, m.Name)
+			files[name+".up.sql"] = &fstest.MapFile{Data: []byte(m.Up)}
+			files[name+".down.sql"] = &fstest.MapFile{Data: []byte(m.Down)}
+		}
+	}
+	useSource(t, files)
+}
+
+// ユニークキーの追加前に重複した試合・試合進捗を除く
+func TestUp_AddUniqueKeys(t *testing.T) {
+	useEmbedded(t, 2)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(selectRows).WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, "2025-04-01 00:00:00"))
+	//付け替え・削除の順に実行し、最後にユニークキーを追加する
+	mock.ExpectExec(`UPDATE scores s .+GROUP BY link\) k .+SET s.match_id = k.keep_id`).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE m FROM matches m .+GROUP BY link\) k .+WHERE m.id <> k.keep_id`).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE scores s .+GROUP BY date, home, away\) k .+SET s.match_id = k.keep_id`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE m FROM matches m .+GROUP BY date, home, away\) k .+WHERE m.id <> k.keep_id`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE s FROM scores s .+GROUP BY match_id\) k .+WHERE s.id <> k.keep_id`).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`ALTER TABLE matches\s+ADD UNIQUE KEY uq_matches_link`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ALTER TABLE scores\s+ADD UNIQUE KEY uq_scores_match`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(insertRow).WithArgs(2, "add_unique_keys").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	done, err := Up(db)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// 重複した行がある既存のDBに0002を適用する（MIGRATE_TEST_DSNに空のテスト用DBを指定した場合のみ）
+func TestUp_AddUniqueKeys_MySQL(t *testing.T) {
+	dsn := os.Getenv("MIGRATE_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MIGRATE_TEST_DSN is not set")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+	reset := func() {
+		for _, table := range []string{"scores", "matches", "schema_migrations"} {
+			_, err := db.Exec("DROP TABLE IF EXISTS " + table)
+			assert.NoError(t, err)
+		}
+	}
+	reset()
+	defer reset()
+
+	//0001まで適用し、繰り返し取り込んだ重複行を作る
+	useEmbedded(t, 1)
+	_, err = Up(db)
+	assert.NoError(t, err)
+	for _, q := range []string{
+		`INSERT INTO matches (id, date, home, away, league, stadium, starttime, link) VALUES
+			(1, '2025-04-06', 'ヤクルト', '中日', 'セ・リーグ', '神宮', '13:00', '/game/1'),
+			(2, '2025-04-06', 'ヤクルト', '中日', 'セ・リーグ', '神宮', '13:00', '/game/1'),
+			(3, '2025-04-06', 'ヤクルト', '中日', 'セ・リーグ', '神宮', '13:00', '/game/1b'),
+			(4, '2025-04-06', '広島', 'DeNA', 'セ・リーグ', 'マツダスタジアム', '13:30', '/game/2')`,
+		`INSERT INTO scores (id, home_score, match_id) VALUES (1, '1', 1), (2, '1', 2), (3, '1', 3), (4, '0', 4), (5, '0', 4)`,
+	} {
+		_, err := db.Exec(q)
+		assert.NoError(t, err)
+	}
+
+	useEmbedded(t, 2)
+	done, err := Up(db)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+
+	ids := func(query string) []int {
+		rows, err := db.Query(query)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		defer rows.Close()
+		var ids []int
+		for rows.Next() {
+			var id int
+			assert.NoError(t, rows.Scan(&id))
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	assert.Equal(t, []int{1, 4}, ids("SELECT id FROM matches ORDER BY id"))
+	assert.Equal(t, []int{1, 4}, ids("SELECT match_id FROM scores ORDER BY match_id"))
+}
@@ -0,0 +1,24 @@
+-- 試合情報と試合進捗（既存のDBに適用してもエラーにならないようIF NOT EXISTSとする）
+CREATE TABLE IF NOT EXISTS matches (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    date DATE NOT NULL,
+    home VARCHAR(50) NOT NULL,
+    away VARCHAR(50) NOT NULL,
+    league VARCHAR(50) NOT NULL,
+    stadium VARCHAR(100) NOT NULL,
+    starttime TIME NOT NULL,
+    link VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS scores (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    home_score VARCHAR(3) DEFAULT '0',
+    away_score VARCHAR(3) DEFAULT '0',
+    batter VARCHAR(30) DEFAULT 'No Batter',
+    inning VARCHAR(30) DEFAULT '0回表',
+    result VARCHAR(100) DEFAULT '試合前',
+    match_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (match_id) REFERENCES matches(id)
+);
@@ -0,0 +1,9 @@
+-- uq_scores_matchの追加時に外部キーの暗黙のインデックスが削除されているため、
+-- 外部キー用のインデックスを作り直してからユニークキーを削除する（MySQL error 1553）
+ALTER TABLE scores
+    ADD INDEX match_id (match_id),
+    DROP INDEX uq_scores_match;
+
+ALTER TABLE matches
+    DROP INDEX uq_matches_game,
+    DROP INDEX uq_matches_link;
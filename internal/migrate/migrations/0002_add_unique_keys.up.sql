@@ -0,0 +1,38 @@
+-- 日程の取り込みを冪等にするためのユニークキー
+-- 既存のDBには繰り返し取り込んだ重複行があるため、先に重複を除く（MySQL error 1062）
+
+-- 同じリンクの試合はidが最小の行を残し、試合進捗を残す試合に付け替えてから削除する
+UPDATE scores s
+    JOIN matches m ON m.id = s.match_id
+    JOIN (SELECT link, MIN(id) AS keep_id FROM matches GROUP BY link) k ON k.link = m.link
+SET s.match_id = k.keep_id
+WHERE m.id <> k.keep_id;
+
+DELETE m FROM matches m
+    JOIN (SELECT link, MIN(id) AS keep_id FROM matches GROUP BY link) k ON k.link = m.link
+WHERE m.id <> k.keep_id;
+
+-- 同じ日・同じ対戦の試合も同様にidが最小の行を残す
+UPDATE scores s
+    JOIN matches m ON m.id = s.match_id
+    JOIN (SELECT date, home, away, MIN(id) AS keep_id FROM matches GROUP BY date, home, away) k
+        ON k.date = m.date AND k.home = m.home AND k.away = m.away
+SET s.match_id = k.keep_id
+WHERE m.id <> k.keep_id;
+
+DELETE m FROM matches m
+    JOIN (SELECT date, home, away, MIN(id) AS keep_id FROM matches GROUP BY date, home, away) k
+        ON k.date = m.date AND k.home = m.home AND k.away = m.away
+WHERE m.id <> k.keep_id;
+
+-- 1試合に複数ある試合進捗はidが最小の行を残す
+DELETE s FROM scores s
+    JOIN (SELECT match_id, MIN(id) AS keep_id FROM scores GROUP BY match_id) k ON k.match_id = s.match_id
+WHERE s.id <> k.keep_id;
+
+ALTER TABLE matches
+    ADD UNIQUE KEY uq_matches_link (link),
+    ADD UNIQUE KEY uq_matches_game (date, home, away);
+
+ALTER TABLE scores
+    ADD UNIQUE KEY uq_scores_match (match_id);
@@ -0,0 +1 @@
+DROP TABLE score_events;
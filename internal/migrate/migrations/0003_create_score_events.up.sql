@@ -0,0 +1,13 @@
+-- 試合進捗の変化の履歴
+CREATE TABLE score_events (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    match_id INT NOT NULL,
+    inning VARCHAR(30) NOT NULL,
+    home_score VARCHAR(3) NOT NULL,
+    away_score VARCHAR(3) NOT NULL,
+    batter VARCHAR(30) NOT NULL,
+    result VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_score_events_match (match_id, id),
+    FOREIGN KEY (match_id) REFERENCES matches(id)
+);
@@ -0,0 +1,13 @@
+-- 解析に失敗したページ（ページの構造の変化を調査するためHTMLを保存）
+CREATE TABLE parse_failures (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    source VARCHAR(30) NOT NULL,
+    page VARCHAR(30) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    match_id INT NULL,
+    field VARCHAR(50) NOT NULL,
+    error TEXT NOT NULL,
+    html MEDIUMTEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_parse_failures_created (created_at)
+);
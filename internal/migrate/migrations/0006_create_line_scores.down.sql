@@ -0,0 +1 @@
+DROP TABLE line_scores;
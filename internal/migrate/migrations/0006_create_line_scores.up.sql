@@ -0,0 +1,12 @@
+-- ラインスコア（イニングごとの得点はカンマ区切り）
+CREATE TABLE line_scores (
+    match_id INT NOT NULL,
+    side VARCHAR(4) NOT NULL,
+    innings VARCHAR(255) NOT NULL,
+    runs VARCHAR(3) NOT NULL,
+    hits VARCHAR(3) NOT NULL,
+    errors VARCHAR(3) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (match_id, side),
+    FOREIGN KEY (match_id) REFERENCES matches(id)
+);
@@ -0,0 +1,8 @@
+ALTER TABLE scores
+    DROP COLUMN pitcher,
+    DROP COLUMN balls,
+    DROP COLUMN strikes,
+    DROP COLUMN outs,
+    DROP COLUMN on_first,
+    DROP COLUMN on_second,
+    DROP COLUMN on_third;
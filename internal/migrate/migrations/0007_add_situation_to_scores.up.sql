@@ -0,0 +1,9 @@
+-- 試合中の状況（試合中でない場合はNULL）
+ALTER TABLE scores
+    ADD COLUMN pitcher VARCHAR(30) NULL,
+    ADD COLUMN balls TINYINT NULL,
+    ADD COLUMN strikes TINYINT NULL,
+    ADD COLUMN outs TINYINT NULL,
+    ADD COLUMN on_first BOOLEAN NULL,
+    ADD COLUMN on_second BOOLEAN NULL,
+    ADD COLUMN on_third BOOLEAN NULL;
@@ -0,0 +1,2 @@
+DROP TABLE player_stats;
+DROP TABLE players;
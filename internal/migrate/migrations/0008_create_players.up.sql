@@ -0,0 +1,42 @@
+-- 選手（idは取得元の選手ID）
+CREATE TABLE players (
+    id INT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    team VARCHAR(50) NOT NULL,
+    number VARCHAR(4) NOT NULL,
+    position VARCHAR(4) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_players_team (team)
+);
+
+-- 選手のシーズン成績（bat_は打撃、pit_は投球の成績。該当しない場合はNULL）
+CREATE TABLE player_stats (
+    player_id INT NOT NULL,
+    season SMALLINT NOT NULL,
+    bat_games SMALLINT NULL,
+    bat_plate_appearances SMALLINT NULL,
+    bat_at_bats SMALLINT NULL,
+    bat_hits SMALLINT NULL,
+    bat_home_runs SMALLINT NULL,
+    bat_rbi SMALLINT NULL,
+    bat_stolen_bases SMALLINT NULL,
+    bat_walks SMALLINT NULL,
+    bat_strikeouts SMALLINT NULL,
+    bat_avg DECIMAL(4,3) NULL,
+    bat_obp DECIMAL(4,3) NULL,
+    bat_slg DECIMAL(4,3) NULL,
+    bat_ops DECIMAL(4,3) NULL,
+    pit_games SMALLINT NULL,
+    pit_wins SMALLINT NULL,
+    pit_losses SMALLINT NULL,
+    pit_saves SMALLINT NULL,
+    pit_holds SMALLINT NULL,
+    pit_innings VARCHAR(8) NULL,
+    pit_strikeouts SMALLINT NULL,
+    pit_walks SMALLINT NULL,
+    pit_earned_runs SMALLINT NULL,
+    pit_era DECIMAL(6,2) NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (player_id, season),
+    FOREIGN KEY (player_id) REFERENCES players(id)
+);
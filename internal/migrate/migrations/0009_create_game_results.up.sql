@@ -0,0 +1,15 @@
+-- 終了した試合のチームごとの結果（順位表の集計に使う）
+CREATE TABLE game_results (
+    match_id INT NOT NULL,
+    team VARCHAR(50) NOT NULL,
+    opponent VARCHAR(50) NOT NULL,
+    date DATE NOT NULL,
+    home BOOLEAN NOT NULL,
+    runs TINYINT NOT NULL,
+    opponent_runs TINYINT NOT NULL,
+    result CHAR(1) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (match_id, team),
+    INDEX idx_game_results_date (date),
+    FOREIGN KEY (match_id) REFERENCES matches(id)
+);
@@ -0,0 +1,14 @@
+ALTER TABLE matches
+    DROP FOREIGN KEY fk_matches_home_team,
+    DROP FOREIGN KEY fk_matches_away_team,
+    DROP FOREIGN KEY fk_matches_stadium;
+
+ALTER TABLE matches
+    DROP COLUMN home_team_id,
+    DROP COLUMN away_team_id,
+    DROP COLUMN stadium_id;
+
+DROP TABLE stadium_aliases;
+DROP TABLE stadiums;
+DROP TABLE team_aliases;
+DROP TABLE teams;
@@ -0,0 +1,140 @@
+-- 12球団（nameは日程ページの表記。internal/teamsの定義と同じ内容を登録する）
+CREATE TABLE teams (
+    id VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    name_en VARCHAR(100) NOT NULL,
+    abbreviation VARCHAR(4) NOT NULL,
+    league VARCHAR(50) NOT NULL,
+    UNIQUE KEY uq_teams_name (name)
+);
+
+-- チーム名の別表記（正式名称など）
+CREATE TABLE team_aliases (
+    alias VARCHAR(100) PRIMARY KEY,
+    team_id VARCHAR(20) NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES teams(id)
+);
+
+-- 12球団の本拠地・準本拠地の球場（nameは日程ページの表記）
+CREATE TABLE stadiums (
+    id VARCHAR(30) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    name_en VARCHAR(100) NOT NULL,
+    team_id VARCHAR(20) NULL,
+    UNIQUE KEY uq_stadiums_name (name),
+    FOREIGN KEY (team_id) REFERENCES teams(id)
+);
+
+-- 球場名の別表記（正式名称・旧名称など）
+CREATE TABLE stadium_aliases (
+    alias VARCHAR(100) PRIMARY KEY,
+    stadium_id VARCHAR(30) NOT NULL,
+    FOREIGN KEY (stadium_id) REFERENCES stadiums(id)
+);
+
+INSERT INTO teams (id, name, name_en, abbreviation, league) VALUES
+    ('giants', '巨人', 'Yomiuri Giants', 'G', 'セ・リーグ'),
+    ('swallows', 'ヤクルト', 'Tokyo Yakult Swallows', 'S', 'セ・リーグ'),
+    ('baystars', 'DeNA', 'Yokohama DeNA BayStars', 'DB', 'セ・リーグ'),
+    ('dragons', '中日', 'Chunichi Dragons', 'D', 'セ・リーグ'),
+    ('tigers', '阪神', 'Hanshin Tigers', 'T', 'セ・リーグ'),
+    ('carp', '広島', 'Hiroshima Toyo Carp', 'C', 'セ・リーグ'),
+    ('lions', '西武', 'Saitama Seibu Lions', 'L', 'パ・リーグ'),
+    ('fighters', '日本ハム', 'Hokkaido Nippon-Ham Fighters', 'F', 'パ・リーグ'),
+    ('marines', 'ロッテ', 'Chiba Lotte Marines', 'M', 'パ・リーグ'),
+    ('buffaloes', 'オリックス', 'ORIX Buffaloes', 'B', 'パ・リーグ'),
+    ('hawks', 'ソフトバンク', 'Fukuoka SoftBank Hawks', 'H', 'パ・リーグ'),
+    ('eagles', '楽天', 'Tohoku Rakuten Golden Eagles', 'E', 'パ・リーグ');
+
+INSERT INTO team_aliases (alias, team_id) VALUES
+    ('読売ジャイアンツ', 'giants'),
+    ('読売', 'giants'),
+    ('ジャイアンツ', 'giants'),
+    ('東京ヤクルトスワローズ', 'swallows'),
+    ('東京ヤクルト', 'swallows'),
+    ('スワローズ', 'swallows'),
+    ('横浜DeNAベイスターズ', 'baystars'),
+    ('横浜DeNA', 'baystars'),
+    ('横浜', 'baystars'),
+    ('ベイスターズ', 'baystars'),
+    ('中日ドラゴンズ', 'dragons'),
+    ('ドラゴンズ', 'dragons'),
+    ('阪神タイガース', 'tigers'),
+    ('タイガース', 'tigers'),
+    ('広島東洋カープ', 'carp'),
+    ('広島東洋', 'carp'),
+    ('カープ', 'carp'),
+    ('埼玉西武ライオンズ', 'lions'),
+    ('埼玉西武', 'lions'),
+    ('ライオンズ', 'lions'),
+    ('北海道日本ハムファイターズ', 'fighters'),
+    ('北海道日本ハム', 'fighters'),
+    ('日ハム', 'fighters'),
+    ('ファイターズ', 'fighters'),
+    ('千葉ロッテマリーンズ', 'marines'),
+    ('千葉ロッテ', 'marines'),
+    ('マリーンズ', 'marines'),
+    ('オリックス・バファローズ', 'buffaloes'),
+    ('バファローズ', 'buffaloes'),
+    ('福岡ソフトバンクホークス', 'hawks'),
+    ('福岡ソフトバンク', 'hawks'),
+    ('ホークス', 'hawks'),
+    ('東北楽天ゴールデンイーグルス', 'eagles'),
+    ('東北楽天', 'eagles'),
+    ('イーグルス', 'eagles');
+
+INSERT INTO stadiums (id, name, name_en, team_id) VALUES
+    ('tokyo-dome', '東京ドーム', 'Tokyo Dome', 'giants'),
+    ('jingu', '神宮', 'Meiji Jingu Stadium', 'swallows'),
+    ('yokohama', '横浜', 'Yokohama Stadium', 'baystars'),
+    ('vantelin-dome', 'バンテリンドーム', 'Vantelin Dome Nagoya', 'dragons'),
+    ('koshien', '甲子園', 'Hanshin Koshien Stadium', 'tigers'),
+    ('mazda-stadium', 'マツダスタジアム', 'MAZDA Zoom-Zoom Stadium Hiroshima', 'carp'),
+    ('belluna-dome', 'ベルーナドーム', 'Belluna Dome', 'lions'),
+    ('escon-field', 'エスコンフィールド', 'ES CON FIELD HOKKAIDO', 'fighters'),
+    ('zozo-marine', 'ZOZOマリン', 'ZOZO Marine Stadium', 'marines'),
+    ('kyocera-dome', '京セラD大阪', 'Kyocera Dome Osaka', 'buffaloes'),
+    ('hotto-motto-kobe', 'ほっと神戸', 'Hotto Motto Field Kobe', 'buffaloes'),
+    ('mizuho-paypay', 'みずほPayPay', 'Mizuho PayPay Dome Fukuoka', 'hawks'),
+    ('rakuten-mobile', '楽天モバイル', 'Rakuten Mobile Park Miyagi', 'eagles');
+
+INSERT INTO stadium_aliases (alias, stadium_id) VALUES
+    ('東京D', 'tokyo-dome'),
+    ('明治神宮野球場', 'jingu'),
+    ('神宮球場', 'jingu'),
+    ('横浜スタジアム', 'yokohama'),
+    ('ハマスタ', 'yokohama'),
+    ('バンテリンドームナゴヤ', 'vantelin-dome'),
+    ('ナゴヤドーム', 'vantelin-dome'),
+    ('阪神甲子園球場', 'koshien'),
+    ('甲子園球場', 'koshien'),
+    ('MAZDA Zoom-Zoom スタジアム広島', 'mazda-stadium'),
+    ('マツダ', 'mazda-stadium'),
+    ('メットライフドーム', 'belluna-dome'),
+    ('西武ドーム', 'belluna-dome'),
+    ('エスコンフィールドHOKKAIDO', 'escon-field'),
+    ('エスコン', 'escon-field'),
+    ('ZOZOマリンスタジアム', 'zozo-marine'),
+    ('千葉マリン', 'zozo-marine'),
+    ('京セラドーム大阪', 'kyocera-dome'),
+    ('京セラドーム', 'kyocera-dome'),
+    ('ほっともっとフィールド神戸', 'hotto-motto-kobe'),
+    ('みずほPayPayドーム福岡', 'mizuho-paypay'),
+    ('みずほPayPayドーム', 'mizuho-paypay'),
+    ('PayPayドーム', 'mizuho-paypay'),
+    ('楽天モバイルパーク宮城', 'rakuten-mobile'),
+    ('楽天モバイルパーク', 'rakuten-mobile');
+
+-- 試合のチーム・球場のID（登録されていないチーム・球場はNULL）
+ALTER TABLE matches
+    ADD COLUMN home_team_id VARCHAR(20) NULL,
+    ADD COLUMN away_team_id VARCHAR(20) NULL,
+    ADD COLUMN stadium_id VARCHAR(30) NULL,
+    ADD CONSTRAINT fk_matches_home_team FOREIGN KEY (home_team_id) REFERENCES teams(id),
+    ADD CONSTRAINT fk_matches_away_team FOREIGN KEY (away_team_id) REFERENCES teams(id),
+    ADD CONSTRAINT fk_matches_stadium FOREIGN KEY (stadium_id) REFERENCES stadiums(id);
+
+-- 登録済みの試合はチーム名・球場名から設定する
+UPDATE matches m JOIN teams t ON m.home = t.name SET m.home_team_id = t.id;
+UPDATE matches m JOIN teams t ON m.away = t.name SET m.away_team_id = t.id;
+UPDATE matches m JOIN stadiums s ON m.stadium = s.name SET m.stadium_id = s.id;
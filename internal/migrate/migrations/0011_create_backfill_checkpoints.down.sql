@@ -0,0 +1 @@
+DROP TABLE backfill_checkpoints;
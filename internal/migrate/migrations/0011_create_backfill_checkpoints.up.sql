@@ -0,0 +1,6 @@
+-- 過去の日程の取り込みが完了した日付（backfillの再実行時に飛ばす）
+CREATE TABLE backfill_checkpoints (
+    date DATE PRIMARY KEY,
+    games TINYINT NOT NULL,
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
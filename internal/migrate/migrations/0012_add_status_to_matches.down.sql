@@ -0,0 +1,3 @@
+ALTER TABLE matches
+    DROP COLUMN status,
+    DROP COLUMN original_date;
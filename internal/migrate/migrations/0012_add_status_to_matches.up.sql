@@ -0,0 +1,5 @@
+-- 日程の変更（scheduled: 日程どおり、rescheduled: 別の日に移動、removed: 日程から消えた）
+-- original_dateは最初に移動する前の日付（移動していない場合はNULL）
+ALTER TABLE matches
+    ADD COLUMN status VARCHAR(12) NOT NULL DEFAULT 'scheduled',
+    ADD COLUMN original_date DATE NULL;
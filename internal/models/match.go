@@ -0,0 +1,109 @@
+package models
+
+// 日程の変更（matchesテーブルのstatus）
+const (
+	MatchScheduled   = "scheduled"
+	MatchRescheduled = "rescheduled"
+	MatchRemoved     = "removed"
+)
+
+// Match 試合情報（matchesテーブルの1行）
+// linkはスクレイピング用、チーム・球場のIDは登録用のためJSONには含めない
+type Match struct {
+	ID           int     `json:"id"`
+	Date         string  `json:"date"`
+	Home         string  `json:"home"`
+	Away         string  `json:"away"`
+	League       string  `json:"league"`
+	Stadium      string  `json:"stadium"`
+	StartTime    string  `json:"starttime"`
+	Status       string  `json:"status"`
+	OriginalDate *string `json:"original_date"`
+	Link         string  `json:"-"`
+	HomeTeamID   string  `json:"-"`
+	AwayTeamID   string  `json:"-"`
+	StadiumID    string  `json:"-"`
+}
+
+// Score 試合進捗（scoresテーブルの1行）
+type Score struct {
+	MatchID   int    `json:"match_id"`
+	HomeScore string `json:"home_score"`
+	AwayScore string `json:"away_score"`
+	Batter    string `json:"batter"`
+	Inning    string `json:"inning"`
+	Result    string `json:"result"`
+	Situation
+}
+
+// Situation 試合中の状況
+// 試合中でない、または取得できなかった項目はnull
+type Situation struct {
+	Pitcher *string `json:"pitcher"`
+	Balls   *int    `json:"balls"`
+	Strikes *int    `json:"strikes"`
+	Outs    *int    `json:"outs"`
+	Bases   *Bases  `json:"bases"`
+}
+
+// Bases 走者の有無
+type Bases struct {
+	First  bool `json:"first"`
+	Second bool `json:"second"`
+	Third  bool `json:"third"`
+}
+
+// LiveMatch 開始済みで終了していない試合と現在の試合進捗
+// スコアが未登録の場合、試合進捗の各項目は空文字になる
+type LiveMatch struct {
+	Match
+	Inning    string `json:"inning"`
+	HomeScore string `json:"home_score"`
+	AwayScore string `json:"away_score"`
+	Batter    string `json:"batter"`
+	Result    string `json:"result"`
+}
+
+// MatchDetail 試合情報（matches）と試合進捗（scores）を結合した試合詳細
+type MatchDetail struct {
+	ID        int    `json:"id"`
+	Date      string `json:"date"`
+	Home      string `json:"home"`
+	Away      string `json:"away"`
+	League    string `json:"league"`
+	Stadium   string `json:"stadium"`
+	StartTime string `json:"starttime"`
+	Inning    string `json:"inning"`
+	HomeScore string `json:"home_score"`
+	AwayScore string `json:"away_score"`
+	Batter    string `json:"batter"`
+	Result    string `json:"result"`
+}
+
+// ScoreEvent 試合進捗の変化の履歴（score_eventsテーブルの1行）
+type ScoreEvent struct {
+	ID        int    `json:"id"`
+	Inning    string `json:"inning"`
+	HomeScore string `json:"home_score"`
+	AwayScore string `json:"away_score"`
+	Batter    string `json:"batter"`
+	Result    string `json:"result"`
+	CreatedAt string `json:"created_at"`
+}
+
+// LineScore 1チームのイニングごとの得点と合計の得点・安打・失策（line_scoresテーブルの1行）
+// inningsは1回から順に、未実施は空文字、サヨナラ等で不要な裏の攻撃は"X"
+type LineScore struct {
+	Team    string   `json:"team"`
+	Innings []string `json:"innings"`
+	Runs    string   `json:"runs"`
+	Hits    string   `json:"hits"`
+	Errors  string   `json:"errors"`
+}
+
+// MatchLineScore 試合のラインスコア（先攻がaway、後攻がhome）
+type MatchLineScore struct {
+	MatchID int       `json:"match_id"`
+	Away    LineScore `json:"away"`
+	Home    LineScore `json:"home"`
+}
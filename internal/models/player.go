@@ -0,0 +1,62 @@
+package models
+
+// 選手の区分（選手一覧ページの投手・野手）
+const (
+	PositionPitcher = "投手"
+	PositionBatter  = "野手"
+)
+
+// Player 選手情報（playersテーブルの1行）と1シーズンの成績
+// 打撃・投球の成績は該当する選手一覧に載っていない場合null
+type Player struct {
+	ID       int            `json:"id"`
+	Name     string         `json:"name"`
+	Team     string         `json:"team"`
+	Number   string         `json:"number"`
+	Position string         `json:"position"`
+	Season   int            `json:"season"`
+	Batting  *BattingStats  `json:"batting"`
+	Pitching *PitchingStats `json:"pitching"`
+}
+
+// BattingStats 打撃成績
+// 率は打数・打席が0の場合null
+type BattingStats struct {
+	Games            int      `json:"games"`
+	PlateAppearances int      `json:"plate_appearances"`
+	AtBats           int      `json:"at_bats"`
+	Hits             int      `json:"hits"`
+	HomeRuns         int      `json:"home_runs"`
+	RBI              int      `json:"rbi"`
+	StolenBases      int      `json:"stolen_bases"`
+	Walks            int      `json:"walks"`
+	Strikeouts       int      `json:"strikeouts"`
+	Avg              *float64 `json:"avg"`
+	OBP              *float64 `json:"obp"`
+	SLG              *float64 `json:"slg"`
+	OPS              *float64 `json:"ops"`
+}
+
+// PitchingStats 投球成績
+// 投球回は1/3回を".1"で表す（例: "52.1"）。防御率は投球回が0の場合null
+type PitchingStats struct {
+	Games      int      `json:"games"`
+	Wins       int      `json:"wins"`
+	Losses     int      `json:"losses"`
+	Saves      int      `json:"saves"`
+	Holds      int      `json:"holds"`
+	Innings    string   `json:"innings"`
+	Strikeouts int      `json:"strikeouts"`
+	Walks      int      `json:"walks"`
+	EarnedRuns int      `json:"earned_runs"`
+	ERA        *float64 `json:"era"`
+}
+
+// TeamPlayers チームの選手一覧（sortの成績の順）
+type TeamPlayers struct {
+	Team    string   `json:"team"`
+	Season  int      `json:"season"`
+	Sort    string   `json:"sort"`
+	Order   string   `json:"order"`
+	Players []Player `json:"players"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// 試合結果（game_resultsテーブルのresult）
+const (
+	ResultWin  = "W"
+	ResultLoss = "L"
+	ResultTie  = "T"
+)
+
+// GameResult 終了した試合のチームごとの結果（game_resultsテーブルの1行）
+type GameResult struct {
+	MatchID      int
+	Date         string
+	Team         string
+	Opponent     string
+	Home         bool
+	Runs         int
+	OpponentRuns int
+	Result       string
+}
+
+// Record 勝敗数
+type Record struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+}
+
+// Standing 順位表の1チーム
+type Standing struct {
+	Rank        int     `json:"rank"`
+	Team        string  `json:"team"`
+	Games       int     `json:"games"`
+	Wins        int     `json:"wins"`
+	Losses      int     `json:"losses"`
+	Ties        int     `json:"ties"`
+	Pct         float64 `json:"pct"`
+	GamesBehind float64 `json:"games_behind"`
+	Home        Record  `json:"home"`
+	Away        Record  `json:"away"`
+	Last10      Record  `json:"last10"`
+}
+
+// Standings dateの試合終了時点の順位表（リーグごと）
+type Standings struct {
+	Date      string                `json:"date"`
+	Standings map[string][]Standing `json:"standings"`
+}
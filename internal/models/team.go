@@ -0,0 +1,44 @@
+package models
+
+// Team チーム（teamsテーブルの1行、チーム名は日程ページの表記）
+type Team struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	NameEn       string   `json:"name_en"`
+	Abbreviation string   `json:"abbreviation"`
+	League       string   `json:"league"`
+	Aliases      []string `json:"aliases"`
+}
+
+// Stadium 球場（stadiumsテーブルの1行、球場名は日程ページの表記）
+type Stadium struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	NameEn  string   `json:"name_en"`
+	TeamID  string   `json:"team_id"`
+	Aliases []string `json:"aliases"`
+}
+
+// TeamGame チームから見た1試合
+// 得点と勝敗は試合終了の場合のみ値が入る
+type TeamGame struct {
+	MatchID      int     `json:"match_id"`
+	Date         string  `json:"date"`
+	StartTime    string  `json:"starttime"`
+	Opponent     string  `json:"opponent"`
+	Home         bool    `json:"home"`
+	League       string  `json:"league"`
+	Stadium      string  `json:"stadium"`
+	Inning       string  `json:"inning"`
+	Runs         *int    `json:"runs"`
+	OpponentRuns *int    `json:"opponent_runs"`
+	Result       *string `json:"result"`
+}
+
+// TeamGames チームのシーズンの試合日程と結果
+type TeamGames struct {
+	Team   Team       `json:"team"`
+	Season int        `json:"season"`
+	Record Record     `json:"record"`
+	Games  []TeamGame `json:"games"`
+}
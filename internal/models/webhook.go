@@ -0,0 +1,19 @@
+package models
+
+// Webhook 試合イベントの通知先
+type Webhook struct {
+	ID        int      `json:"id"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"-"`
+	Events    []string `json:"events"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// DeadLetter 再送上限に達した通知
+type DeadLetter struct {
+	WebhookID int
+	Event     string
+	Payload   string
+	Attempts  int
+	LastError string
+}
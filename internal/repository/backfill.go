@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// from〜to（YYYY-MM-DD）のうち取り込みが完了した日付
+func (d *DefaultRepository) GetBackfillCheckpoints(db *sql.DB, from, to string) (map[string]bool, error) {
+	query := `
+			SELECT DATE_FORMAT(date, '%Y-%m-%d')
+			FROM backfill_checkpoints
+			WHERE date BETWEEN ? AND ?
+			`
+	rows, err := db.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch backfill checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	done := map[string]bool{}
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, fmt.Errorf("failed to scan backfill checkpoint row: %w", err)
+		}
+		done[date] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch backfill checkpoints: %w", err)
+	}
+	return done, nil
+}
+
+// date（YYYY-MM-DD）の取り込みが完了したことを記録する
+func (d *DefaultRepository) SaveBackfillCheckpoint(db *sql.DB, date string, games int) error {
+	query := `
+			INSERT INTO backfill_checkpoints (date, games)
+			VALUES (?, ?)
+			ON DUPLICATE KEY UPDATE games = VALUES(games), completed_at = CURRENT_TIMESTAMP
+			`
+	if _, err := db.Exec(query, date, games); err != nil {
+		return fmt.Errorf("failed to save backfill checkpoint: %w", err)
+	}
+	return nil
+}
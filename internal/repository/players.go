@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"baseball_report/internal/models"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// 選手一覧で並び替えできる成績（sortパラメータの値とカラム）
+var PlayerSortColumns = map[string]string{
+	"avg":   "s.bat_avg",
+	"obp":   "s.bat_obp",
+	"slg":   "s.bat_slg",
+	"ops":   "s.bat_ops",
+	"hits":  "s.bat_hits",
+	"hr":    "s.bat_home_runs",
+	"rbi":   "s.bat_rbi",
+	"sb":    "s.bat_stolen_bases",
+	"era":   "s.pit_era",
+	"wins":  "s.pit_wins",
+	"saves": "s.pit_saves",
+	"holds": "s.pit_holds",
+	"so":    "s.pit_strikeouts",
+}
+
+// 選手の登録・更新
+const upsertPlayerQuery = `
+			INSERT INTO players (id, name, team, number, position)
+			VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				name = VALUES(name),
+				team = VALUES(team),
+				number = VALUES(number),
+				position = VALUES(position)
+			`
+
+// シーズン成績の登録・更新（一覧に載っていない打撃・投球の成績はNULLにする）
+const upsertPlayerStatsQuery = `
+			INSERT INTO player_stats (
+				player_id, season,
+				bat_games, bat_plate_appearances, bat_at_bats, bat_hits, bat_home_runs, bat_rbi, bat_stolen_bases,
+				bat_walks, bat_strikeouts, bat_avg, bat_obp, bat_slg, bat_ops,
+				pit_games, pit_wins, pit_losses, pit_saves, pit_holds, pit_innings, pit_strikeouts,
+				pit_walks, pit_earned_runs, pit_era
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				bat_games = VALUES(bat_games),
+				bat_plate_appearances = VALUES(bat_plate_appearances),
+				bat_at_bats = VALUES(bat_at_bats),
+				bat_hits = VALUES(bat_hits),
+				bat_home_runs = VALUES(bat_home_runs),
+				bat_rbi = VALUES(bat_rbi),
+				bat_stolen_bases = VALUES(bat_stolen_bases),
+				bat_walks = VALUES(bat_walks),
+				bat_strikeouts = VALUES(bat_strikeouts),
+				bat_avg = VALUES(bat_avg),
+				bat_obp = VALUES(bat_obp),
+				bat_slg = VALUES(bat_slg),
+				bat_ops = VALUES(bat_ops),
+				pit_games = VALUES(pit_games),
+				pit_wins = VALUES(pit_wins),
+				pit_losses = VALUES(pit_losses),
+				pit_saves = VALUES(pit_saves),
+				pit_holds = VALUES(pit_holds),
+				pit_innings = VALUES(pit_innings),
+				pit_strikeouts = VALUES(pit_strikeouts),
+				pit_walks = VALUES(pit_walks),
+				pit_earned_runs = VALUES(pit_earned_runs),
+				pit_era = VALUES(pit_era)
+			`
+
+// 選手と成績の取得に使用するSELECT句
+const playerQuery = `
+			SELECT
+				p.id, p.name, p.team, p.number, p.position, s.season,
+				s.bat_games, s.bat_plate_appearances, s.bat_at_bats, s.bat_hits, s.bat_home_runs, s.bat_rbi, s.bat_stolen_bases,
+				s.bat_walks, s.bat_strikeouts, s.bat_avg, s.bat_obp, s.bat_slg, s.bat_ops,
+				s.pit_games, s.pit_wins, s.pit_losses, s.pit_saves, s.pit_holds, s.pit_innings, s.pit_strikeouts,
+				s.pit_walks, s.pit_earned_runs, s.pit_era
+			FROM
+				players p
+			`
+
+// チームの選手とseasonの成績を保存する
+// 再実行しても重複せず、1つのトランザクションで登録する
+func (d *DefaultRepository) SavePlayers(db *sql.DB, season int, players []models.Player) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Commit後のRollbackは何もしない
+	defer tx.Rollback()
+
+	for _, p := range players {
+		if _, err := tx.Exec(upsertPlayerQuery, p.ID, p.Name, p.Team, p.Number, p.Position); err != nil {
+			return fmt.Errorf("failed to upsert player: %w", err)
+		}
+		args := append([]interface{}{p.ID, season}, battingArgs(p.Batting)...)
+		args = append(args, pitchingArgs(p.Pitching)...)
+		if _, err := tx.Exec(upsertPlayerStatsQuery, args...); err != nil {
+			return fmt.Errorf("failed to upsert player stats: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit players: %w", err)
+	}
+	return nil
+}
+
+// 打撃成績のカラムの値（成績がない場合はすべてNULL）
+func battingArgs(b *models.BattingStats) []interface{} {
+	if b == nil {
+		return make([]interface{}, 13)
+	}
+	return []interface{}{
+		b.Games, b.PlateAppearances, b.AtBats, b.Hits, b.HomeRuns, b.RBI, b.StolenBases,
+		b.Walks, b.Strikeouts, nullFloatArg(b.Avg), nullFloatArg(b.OBP), nullFloatArg(b.SLG), nullFloatArg(b.OPS),
+	}
+}
+
+// 投球成績のカラムの値（成績がない場合はすべてNULL）
+func pitchingArgs(p *models.PitchingStats) []interface{} {
+	if p == nil {
+		return make([]interface{}, 10)
+	}
+	return []interface{}{
+		p.Games, p.Wins, p.Losses, p.Saves, p.Holds, p.Innings, p.Strikeouts,
+		p.Walks, p.EarnedRuns, nullFloatArg(p.ERA),
+	}
+}
+
+// nilの場合はNULLにする
+func nullFloatArg(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// 選手とseasonの成績を取得（seasonが0の場合は最新のシーズン）
+// 選手が存在しない場合はErrNotFound、成績が未登録の場合は打撃・投球の成績がnilの選手を返す
+func (d *DefaultRepository) GetPlayer(db *sql.DB, id int, season int) (*models.Player, error) {
+	query := playerQuery + "LEFT JOIN player_stats s ON s.player_id = p.id"
+	args := []interface{}{}
+	if season != 0 {
+		query += " AND s.season = ?"
+		args = append(args, season)
+	}
+	query += " WHERE p.id = ? ORDER BY s.season DESC LIMIT 1"
+	args = append(args, id)
+
+	player, err := scanPlayer(db.QueryRow(query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch player: %w", err)
+	}
+	if player.Season == 0 {
+		player.Season = season
+	}
+	return player, nil
+}
+
+// チームの選手とseasonの成績をsort（PlayerSortColumnsのキー）の順に取得
+// 成績がない選手は最後にする
+func (d *DefaultRepository) GetTeamPlayers(db *sql.DB, team string, season int, sort string, desc bool) ([]models.Player, error) {
+	column, ok := PlayerSortColumns[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", sort)
+	}
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	query := playerQuery + fmt.Sprintf(`
+			LEFT JOIN player_stats s ON s.player_id = p.id AND s.season = ?
+			WHERE p.team = ?
+			ORDER BY %s IS NULL, %s %s, p.id
+			`, column, column, direction)
+	rows, err := db.Query(query, season, team)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch players: %w", err)
+	}
+	defer rows.Close()
+
+	players := []models.Player{}
+	for rows.Next() {
+		player, err := scanPlayer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player row: %w", err)
+		}
+		player.Season = season
+		players = append(players, *player)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch players: %w", err)
+	}
+	return players, nil
+}
+
+// 選手と成績の1行を読み取る
+func scanPlayer(row interface{ Scan(...interface{}) error }) (*models.Player, error) {
+	var p models.Player
+	//成績が未登録、または一覧に載っていない場合はNULLになる
+	var season sql.NullInt64
+	var bat [9]sql.NullInt64
+	var batRates [4]sql.NullFloat64
+	var pit [5]sql.NullInt64
+	var innings sql.NullString
+	var pitCounts [3]sql.NullInt64
+	var era sql.NullFloat64
+	err := row.Scan(
+		&p.ID, &p.Name, &p.Team, &p.Number, &p.Position, &season,
+		&bat[0], &bat[1], &bat[2], &bat[3], &bat[4], &bat[5], &bat[6], &bat[7], &bat[8],
+		&batRates[0], &batRates[1], &batRates[2], &batRates[3],
+		&pit[0], &pit[1], &pit[2], &pit[3], &pit[4], &innings,
+		&pitCounts[0], &pitCounts[1], &pitCounts[2], &era,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.Season = int(season.Int64)
+	if bat[0].Valid {
+		p.Batting = &models.BattingStats{
+			Games:            int(bat[0].Int64),
+			PlateAppearances: int(bat[1].Int64),
+			AtBats:           int(bat[2].Int64),
+			Hits:             int(bat[3].Int64),
+			HomeRuns:         int(bat[4].Int64),
+			RBI:              int(bat[5].Int64),
+			StolenBases:      int(bat[6].Int64),
+			Walks:            int(bat[7].Int64),
+			Strikeouts:       int(bat[8].Int64),
+			Avg:              nullFloat(batRates[0]),
+			OBP:              nullFloat(batRates[1]),
+			SLG:              nullFloat(batRates[2]),
+			OPS:              nullFloat(batRates[3]),
+		}
+	}
+	if pit[0].Valid {
+		p.Pitching = &models.PitchingStats{
+			Games:      int(pit[0].Int64),
+			Wins:       int(pit[1].Int64),
+			Losses:     int(pit[2].Int64),
+			Saves:      int(pit[3].Int64),
+			Holds:      int(pit[4].Int64),
+			Innings:    innings.String,
+			Strikeouts: int(pitCounts[0].Int64),
+			Walks:      int(pitCounts[1].Int64),
+			EarnedRuns: int(pitCounts[2].Int64),
+			ERA:        nullFloat(era),
+		}
+	}
+	return &p, nil
+}
+
+// NULLの場合はnilにする
+func nullFloat(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
@@ -1,16 +1,27 @@
 package repository
 
 import (
+	"baseball_report/internal/models"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+// ErrNotFound 対象のレコードが存在しない
+var ErrNotFound = errors.New("record not found")
+
 // Repository インターフェース
 type Repository interface {
-	GetMatch(db *sql.DB, query string) ([]map[string]interface{}, error)
+	GetMatch(db *sql.DB, query string, args ...interface{}) ([]models.Match, error)
 	InsertData(db *sql.DB, query string, args ...interface{}) (int, error)
 	UpdateData(db *sql.DB, query string, args ...interface{}) (int, error)
-	GetMatchScoreLive(db *sql.DB) ([]map[string]interface{}, error)
+	GetMatchScoreLive(db *sql.DB) ([]models.LiveMatch, error)
+	SaveSchedule(db *sql.DB, matches []models.Match) ([]int, error)
+	RemoveMissingMatches(db *sql.DB, date string, ids []int) (int, error)
+	SavePlayers(db *sql.DB, season int, players []models.Player) error
+	GetBackfillCheckpoints(db *sql.DB, from, to string) (map[string]bool, error)
+	SaveBackfillCheckpoint(db *sql.DB, date string, games int) error
 }
 
 // DefaultRepository 実装
@@ -41,170 +52,340 @@ func (d *DefaultRepository) UpdateData(db *sql.DB, query string, args ...interfa
 }
 
 // バックエンド側でDB検索する際に使用
-func (d *DefaultRepository) GetMatch(db *sql.DB, query string) ([]map[string]interface{}, error) {
-	rows, err := db.Query(query)
+// 条件の値はクエリに埋め込まず、プレースホルダ（?）とargsで渡す
+func (d *DefaultRepository) GetMatch(db *sql.DB, query string, args ...interface{}) ([]models.Match, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch match: %w", err)
 	}
 	defer rows.Close()
 
-	var matches []map[string]interface{} //空のスライスを定義
+	var matches []models.Match //空のスライスを定義
 	for rows.Next() {
-		var id int
-		var date string
-		var home string
-		var away string
-		var league string
-		var stadium string
-		var starttime string
-		var link string
-		if err := rows.Scan(&id, &date, &home, &away, &league, &stadium, &starttime, &link); err != nil {
+		var match models.Match
+		if err := rows.Scan(&match.ID, &match.Date, &match.Home, &match.Away, &match.League, &match.Stadium, &match.StartTime, &match.Link); err != nil {
 			return nil, fmt.Errorf("failed to scan match row: %w", err)
 		}
-		//試合情報をマップに格納、スライスに追加
-		matches = append(matches, map[string]interface{}{
-			"id":        id,
-			"date":      date,
-			"home":      home,
-			"away":      away,
-			"league":    league,
-			"stadium":   stadium,
-			"starttime": starttime,
-			"link":      link,
-		})
-
+		matches = append(matches, match)
 	}
 	return matches, nil
 
 }
 
 // 試合情報API出力
-func (d *DefaultRepository) GetMatchAPI(db *sql.DB, todate string) ([]map[string]interface{}, error) {
-	query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
-	rows, err := db.Query(query)
+// from〜toの期間（両端を含む）の試合を取得、leagueが空でなければリーグで絞り込む
+// 日程から消えた試合は含めない
+func (d *DefaultRepository) GetMatchAPI(db *sql.DB, from string, to string, league string) ([]models.Match, error) {
+	query := "SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed'"
+	args := []interface{}{from, to}
+	if league != "" {
+		query += " AND league = ?"
+		args = append(args, league)
+	}
+	query += " ORDER BY date, starttime, id"
+
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch match: %w", err)
 	}
 	defer rows.Close()
 
-	var matches []map[string]interface{} //空のスライスを定義
+	var matches []models.Match //空のスライスを定義
 	for rows.Next() {
-		var id int
-		var date string
-		var home string
-		var away string
-		var league string
-		var stadium string
-		var starttime string
-		if err := rows.Scan(&id, &date, &home, &away, &league, &stadium, &starttime); err != nil {
+		var match models.Match
+		//移動していない試合はNULLになる
+		var originalDate sql.NullString
+		if err := rows.Scan(&match.ID, &match.Date, &match.Home, &match.Away, &match.League, &match.Stadium, &match.StartTime, &match.Status, &originalDate); err != nil {
 			return nil, fmt.Errorf("failed to scan match row: %w", err)
 		}
-		//試合情報をマップに格納、スライスに追加
-		matches = append(matches, map[string]interface{}{
-			"id":        id,
-			"date":      date,
-			"home":      home,
-			"away":      away,
-			"league":    league,
-			"stadium":   stadium,
-			"starttime": starttime,
-		})
-
+		if originalDate.Valid {
+			match.OriginalDate = &originalDate.String
+		}
+		matches = append(matches, match)
 	}
 	return matches, nil
 
 }
 
 // スコア情報を取得
-func (d *DefaultRepository) GetScore(db *sql.DB, id string) ([]map[string]interface{}, error) {
-	query := "SELECT home_score, away_score, batter, inning, result, match_id FROM scores WHERE match_id ='" + id + "'"
-	rows, err := db.Query(query)
+func (d *DefaultRepository) GetScore(db *sql.DB, matchID int) ([]models.Score, error) {
+	query := "SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?"
+	rows, err := db.Query(query, matchID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch match: %w", err)
 	}
 	defer rows.Close()
 
-	var score []map[string]interface{} //空のスライスを定義
+	var score []models.Score //空のスライスを定義
 	for rows.Next() {
-		var match_id int
-		var home_score string
-		var away_score string
-		var batter string
-		var inning string
-		var result string
-
-		if err := rows.Scan(&home_score, &away_score, &batter, &inning, &result, &match_id); err != nil {
+		var sc models.Score
+		//試合中でない場合はNULLになる
+		var pitcher sql.NullString
+		var balls, strikes, outs sql.NullInt64
+		var onFirst, onSecond, onThird sql.NullBool
+		if err := rows.Scan(&sc.HomeScore, &sc.AwayScore, &sc.Batter, &sc.Inning, &sc.Result, &sc.MatchID,
+			&pitcher, &balls, &strikes, &outs, &onFirst, &onSecond, &onThird); err != nil {
 			return nil, fmt.Errorf("failed to scan match row: %w", err)
 		}
-		//試合情報をマップに格納、スライスに追加
-		score = append(score, map[string]interface{}{
-			"match_id":   match_id,
-			"home_score": home_score,
-			"away_score": away_score,
-			"batter":     batter,
-			"inning":     inning,
-			"result":     result,
-		})
+		if pitcher.Valid {
+			sc.Pitcher = &pitcher.String
+		}
+		sc.Balls = nullInt(balls)
+		sc.Strikes = nullInt(strikes)
+		sc.Outs = nullInt(outs)
+		if onFirst.Valid || onSecond.Valid || onThird.Valid {
+			sc.Bases = &models.Bases{First: onFirst.Bool, Second: onSecond.Bool, Third: onThird.Bool}
+		}
+		score = append(score, sc)
 	}
 	return score, nil
 
 }
 
-// スコア情報を取得
-func (d *DefaultRepository) GetMatchScoreLive(db *sql.DB) ([]map[string]interface{}, error) {
-	query := `
-			SELECT 
-				m.id, 
-				m.date, 
-				m.home, 
-				m.away, 
-				m.league, 
-				m.stadium, 
+// NULLの場合はnilにする
+func nullInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+// 終了していない試合の取得に使用するクエリ
+const liveMatchQuery = `
+			SELECT
+				m.id,
+				m.date,
+				m.home,
+				m.away,
+				m.league,
+				m.stadium,
 				m.starttime,
 				m.link,
-				s.inning
-			FROM 
+				s.inning,
+				s.home_score,
+				s.away_score,
+				s.batter,
+				s.result
+			FROM
 				matches m
-			LEFT JOIN 
+			LEFT JOIN
 				scores s ON m.id = s.match_id
-			WHERE 
-				m.date = CURDATE() AND
-				m.starttime <= CURTIME() AND
-				(s.inning <> '試合終了' AND s.inning <> '試合中止')
+			WHERE
+				m.date BETWEEN CURDATE() - INTERVAL 1 DAY AND CURDATE() AND
+				m.status <> 'removed' AND
+				(s.inning IS NULL OR s.inning NOT IN ('試合終了', '試合中止'))
+			ORDER BY m.date, m.starttime, m.id
+			`
+
+// 試合詳細の取得に使用するSELECT句
+const matchDetailQuery = `
+			SELECT
+				m.id,
+				m.date,
+				m.home,
+				m.away,
+				m.league,
+				m.stadium,
+				m.starttime,
+				s.inning,
+				s.home_score,
+				s.away_score,
+				s.batter,
+				s.result
+			FROM
+				matches m
+			LEFT JOIN
+				scores s ON m.id = s.match_id
+			`
+
+// 試合詳細の1行を読み取る
+func scanMatchDetail(row interface{ Scan(...interface{}) error }) (*models.MatchDetail, error) {
+	var match models.MatchDetail
+	//スコアが未登録の場合はNULLになる
+	var inning, homeScore, awayScore, batter, result sql.NullString
+	err := row.Scan(
+		&match.ID, &match.Date, &match.Home, &match.Away, &match.League, &match.Stadium, &match.StartTime,
+		&inning, &homeScore, &awayScore, &batter, &result,
+	)
+	if err != nil {
+		return nil, err
+	}
+	match.Inning = inning.String
+	match.HomeScore = homeScore.String
+	match.AwayScore = awayScore.String
+	match.Batter = batter.String
+	match.Result = result.String
+	return &match, nil
+}
+
+// 試合詳細を取得
+// 試合が存在しない場合はErrNotFoundを返す
+func (d *DefaultRepository) GetMatchDetail(db *sql.DB, id int) (*models.MatchDetail, error) {
+	query := matchDetailQuery + `
+			WHERE
+				m.id = ?
+			LIMIT 1
 			`
-	rows, err := db.Query(query)
+	match, err := scanMatchDetail(db.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch match: %w", err)
+		return nil, fmt.Errorf("failed to fetch match detail: %w", err)
+	}
+	return match, nil
+}
+
+// 複数の試合詳細を取得
+// idsに含まれる試合と、date当日にleagueで行われる試合（日程から消えた試合を除く）をまとめて返す
+func (d *DefaultRepository) GetMatchDetails(db *sql.DB, ids []int, league string, date string) ([]models.MatchDetail, error) {
+	var conds []string
+	var args []interface{}
+	if len(ids) != 0 {
+		conds = append(conds, "m.id IN (?"+strings.Repeat(", ?", len(ids)-1)+")")
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	if league != "" {
+		conds = append(conds, "(m.league = ? AND m.date = ? AND m.status <> 'removed')")
+		args = append(args, league, date)
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+
+	query := matchDetailQuery + `
+			WHERE
+				` + strings.Join(conds, " OR ") + `
+			ORDER BY m.date, m.starttime, m.id
+			`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch match detail: %w", err)
 	}
 	defer rows.Close()
 
-	var matches []map[string]interface{} //空のスライスを定義
+	var matches []models.MatchDetail
 	for rows.Next() {
-		var id int
-		var date string
-		var home string
-		var away string
-		var league string
-		var stadium string
-		var starttime string
-		var link string
-		var result string
-		if err := rows.Scan(&id, &date, &home, &away, &league, &stadium, &starttime, &link, &result); err != nil {
+		match, err := scanMatchDetail(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan match row: %w", err)
 		}
-		//試合情報をマップに格納、スライスに追加
-		matches = append(matches, map[string]interface{}{
-			"id":        id,
-			"date":      date,
-			"home":      home,
-			"away":      away,
-			"league":    league,
-			"stadium":   stadium,
-			"starttime": starttime,
-			"link":      link,
-			"result":    result,
-		})
+		matches = append(matches, *match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch match detail: %w", err)
+	}
+	return matches, nil
+}
+
+// 試合進捗の変化の履歴を古い順に取得
+func (d *DefaultRepository) GetScoreEvents(db *sql.DB, matchID int) ([]models.ScoreEvent, error) {
+	query := `
+			SELECT id, inning, home_score, away_score, batter, result, created_at
+			FROM score_events
+			WHERE match_id = ?
+			ORDER BY id
+			`
+	rows, err := db.Query(query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch score events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.ScoreEvent{}
+	for rows.Next() {
+		var ev models.ScoreEvent
+		if err := rows.Scan(&ev.ID, &ev.Inning, &ev.HomeScore, &ev.AwayScore, &ev.Batter, &ev.Result, &ev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan score event row: %w", err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// 試合のラインスコアを取得
+// 試合が存在しない場合はErrNotFound、ラインスコアが未登録の場合はイニングが空のラインスコアを返す
+func (d *DefaultRepository) GetLineScore(db *sql.DB, matchID int) (*models.MatchLineScore, error) {
+	query := `
+			SELECT m.home, m.away, l.side, l.innings, l.runs, l.hits, l.errors
+			FROM matches m
+			LEFT JOIN line_scores l ON m.id = l.match_id
+			WHERE m.id = ?
+			`
+	rows, err := db.Query(query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch line score: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	lineScore := &models.MatchLineScore{
+		MatchID: matchID,
+		Away:    models.LineScore{Innings: []string{}},
+		Home:    models.LineScore{Innings: []string{}},
+	}
+	for rows.Next() {
+		found = true
+		var home, away string
+		//ラインスコアが未登録の場合はNULLになる
+		var side, innings, runs, hits, errs sql.NullString
+		if err := rows.Scan(&home, &away, &side, &innings, &runs, &hits, &errs); err != nil {
+			return nil, fmt.Errorf("failed to scan line score row: %w", err)
+		}
+		lineScore.Away.Team = away
+		lineScore.Home.Team = home
 
+		line := models.LineScore{Innings: []string{}, Runs: runs.String, Hits: hits.String, Errors: errs.String}
+		if innings.String != "" {
+			line.Innings = strings.Split(innings.String, ",")
+		}
+		switch side.String {
+		case "away":
+			line.Team = away
+			lineScore.Away = line
+		case "home":
+			line.Team = home
+			lineScore.Home = line
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch line score: %w", err)
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	return lineScore, nil
+}
+
+// 前日・当日の終了していない試合と現在のスコア情報を取得
+// 日付をまたいだナイターも対象にするため前日分を含める。取得するタイミングはスケジューラで判断する
+func (d *DefaultRepository) GetMatchScoreLive(db *sql.DB) ([]models.LiveMatch, error) {
+	rows, err := db.Query(liveMatchQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch match: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.LiveMatch //空のスライスを定義
+	for rows.Next() {
+		var match models.LiveMatch
+		//スコアが未登録の場合はNULLになる
+		var inning, homeScore, awayScore, batter, result sql.NullString
+		if err := rows.Scan(
+			&match.ID, &match.Date, &match.Home, &match.Away, &match.League, &match.Stadium, &match.StartTime, &match.Link,
+			&inning, &homeScore, &awayScore, &batter, &result,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan match row: %w", err)
+		}
+		match.Inning = inning.String
+		match.HomeScore = homeScore.String
+		match.AwayScore = awayScore.String
+		match.Batter = batter.String
+		match.Result = result.String
+		matches = append(matches, match)
 	}
 	return matches, nil
 
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"baseball_report/internal/models"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"regexp"
 	"testing"
@@ -119,7 +121,7 @@ func TestGetMatch(t *testing.T) {
 	t.Run("Success to get match", func(t *testing.T) {
 		//クエリ実行でテーブルからデータが取得されていること
 		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime, link FROM matches WHERE date ='" + todate + "'"
+		query := "SELECT id, date, home, away, league, stadium, starttime, link FROM matches WHERE date = ?"
 
 		//モックの結果を定義
 		rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "link"}).
@@ -127,35 +129,17 @@ func TestGetMatch(t *testing.T) {
 			AddRow(2, todate, "Dodgers", "Giants", "パ・リーグ", "Dodger Stadium", "18:30", "https://example.com/match/2")
 
 			// モックの期待値を設定
-		mock.ExpectQuery(query).WillReturnRows(rows)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(todate).WillReturnRows(rows)
 
 		// 関数を実行
-		result, err := repo.GetMatch(db, query)
+		result, err := repo.GetMatch(db, query, todate)
 
 		// エラーが発生しないことを確認
 		assert.NoError(t, err)
 		// 返却結果が期待通りであることを確認
-		expected := []map[string]interface{}{
-			{
-				"id":        1,
-				"date":      todate,
-				"home":      "Yankees",
-				"away":      "Red Sox",
-				"league":    "セ・リーグ",
-				"stadium":   "Yankee Stadium",
-				"starttime": "19:00",
-				"link":      "https://example.com/match/1",
-			},
-			{
-				"id":        2,
-				"date":      todate,
-				"home":      "Dodgers",
-				"away":      "Giants",
-				"league":    "パ・リーグ",
-				"stadium":   "Dodger Stadium",
-				"starttime": "18:30",
-				"link":      "https://example.com/match/2",
-			},
+		expected := []models.Match{
+			{ID: 1, Date: todate, Home: "Yankees", Away: "Red Sox", League: "セ・リーグ", Stadium: "Yankee Stadium", StartTime: "19:00", Link: "https://example.com/match/1"},
+			{ID: 2, Date: todate, Home: "Dodgers", Away: "Giants", League: "パ・リーグ", Stadium: "Dodger Stadium", StartTime: "18:30", Link: "https://example.com/match/2"},
 		}
 		assert.Equal(t, expected, result)
 
@@ -168,31 +152,22 @@ func TestGetMatch(t *testing.T) {
 	t.Run("Success to get match for starttime", func(t *testing.T) {
 		//クエリ実行でテーブルからデータが取得されていること
 		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime, link FROM matches WHERE date ='" + todate + "'" + " AND starttime < CURTIME()"
+		query := "SELECT id, date, home, away, league, stadium, starttime, link FROM matches WHERE date = ? AND starttime < CURTIME()"
 
 		//モックの結果を定義
 		rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "link"}).
 			AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "17:00", "https://example.com/match/1")
 
 			// モックの期待値を設定
-		mock.ExpectQuery(query).WillReturnRows(rows)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(todate).WillReturnRows(rows)
 
 		// 関数を実行
-		result, err := repo.GetMatch(db, query)
+		result, err := repo.GetMatch(db, query, todate)
 		// エラーが発生しないことを確認
 		assert.NoError(t, err)
 		// 返却結果が期待通りであることを確認
-		expected := []map[string]interface{}{
-			{
-				"id":        1,
-				"date":      todate,
-				"home":      "Yankees",
-				"away":      "Red Sox",
-				"league":    "セ・リーグ",
-				"stadium":   "Yankee Stadium",
-				"starttime": "17:00",
-				"link":      "https://example.com/match/1",
-			},
+		expected := []models.Match{
+			{ID: 1, Date: todate, Home: "Yankees", Away: "Red Sox", League: "セ・リーグ", Stadium: "Yankee Stadium", StartTime: "17:00", Link: "https://example.com/match/1"},
 		}
 		assert.Equal(t, expected, result)
 
@@ -252,42 +227,27 @@ func TestGetMatchAPI(t *testing.T) {
 
 	t.Run("Success to get match", func(t *testing.T) {
 		//クエリ実行でテーブルからデータが取得されていること
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		//モックの結果を定義
-		rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime"}).
-			AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00").
-			AddRow(2, todate, "Dodgers", "Giants", "パ・リーグ", "Dodger Stadium", "18:30")
+		rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"}).
+			AddRow(1, todate, "Yankees", "Red Sox", "セ・リーグ", "Yankee Stadium", "19:00", "scheduled", nil).
+			AddRow(2, todate, "Dodgers", "Giants", "パ・リーグ", "Dodger Stadium", "18:30", "rescheduled", "2025-04-01")
 
 			// モックの期待値を設定
-		mock.ExpectQuery(query).WillReturnRows(rows)
+		mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnRows(rows)
 
 		// 関数を実行
-		result, err := repo.GetMatchAPI(db, todate)
+		result, err := repo.GetMatchAPI(db, todate, todate, "")
 
 		// エラーが発生しないことを確認
 		assert.NoError(t, err)
 		// 返却結果が期待通りであることを確認
-		expected := []map[string]interface{}{
-			{
-				"id":        1,
-				"date":      todate,
-				"home":      "Yankees",
-				"away":      "Red Sox",
-				"league":    "セ・リーグ",
-				"stadium":   "Yankee Stadium",
-				"starttime": "19:00",
-			},
-			{
-				"id":        2,
-				"date":      todate,
-				"home":      "Dodgers",
-				"away":      "Giants",
-				"league":    "パ・リーグ",
-				"stadium":   "Dodger Stadium",
-				"starttime": "18:30",
-			},
+		originalDate := "2025-04-01"
+		expected := []models.Match{
+			{ID: 1, Date: todate, Home: "Yankees", Away: "Red Sox", League: "セ・リーグ", Stadium: "Yankee Stadium", StartTime: "19:00", Status: models.MatchScheduled},
+			{ID: 2, Date: todate, Home: "Dodgers", Away: "Giants", League: "パ・リーグ", Stadium: "Dodger Stadium", StartTime: "18:30", Status: models.MatchRescheduled, OriginalDate: &originalDate},
 		}
 		assert.Equal(t, expected, result)
 
@@ -296,16 +256,31 @@ func TestGetMatchAPI(t *testing.T) {
 
 	})
 
+	// 期間・リーグ指定
+	t.Run("Success to get match with range and league", func(t *testing.T) {
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' AND league = ? ORDER BY date, starttime, id")
+
+		rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "league", "stadium", "starttime", "status", "original_date"}).
+			AddRow(5, "2025-04-02", "阪神", "巨人", "セ・リーグ", "甲子園", "18:00", "scheduled", nil)
+		mock.ExpectQuery(query).WithArgs("2025-04-01", "2025-04-07", "セ・リーグ").WillReturnRows(rows)
+
+		result, err := repo.GetMatchAPI(db, "2025-04-01", "2025-04-07", "セ・リーグ")
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "2025-04-02", result[0].Date)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	// Failed to get match
 	t.Run("Failed to get match", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		// クエリ実行時にエラーを返す
-		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("query failed"))
+		mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnError(fmt.Errorf("query failed"))
 
 		// 関数を実行
-		result, err := repo.GetMatchAPI(db, todate)
+		result, err := repo.GetMatchAPI(db, todate, todate, "")
 
 		// エラーが期待通りであることを確認
 		assert.Error(t, err)
@@ -318,17 +293,17 @@ func TestGetMatchAPI(t *testing.T) {
 
 	// 行のスキャン失敗パターン
 	t.Run("Failed to scan", func(t *testing.T) {
-		todate := time.Now().Format("2006/01/02")
-		query := "SELECT id, date, home, away, league, stadium, starttime FROM matches WHERE date ='" + todate + "'"
+		todate := time.Now().Format("2006-01-02")
+		query := regexp.QuoteMeta("SELECT id, date, home, away, league, stadium, starttime, status, original_date FROM matches WHERE date BETWEEN ? AND ? AND status <> 'removed' ORDER BY date, starttime, id")
 
 		// 不正なデータ（型不一致）を返すモック
 		rows := sqlmock.NewRows([]string{"id", "date", "home", "away", "stadium", "starttime", "status"}).
 			AddRow("invalid_id", time.Now(), "Yankees", "Red Sox", "Yankee Stadium", "19:00", "Scheduled")
 
-		mock.ExpectQuery(query).WillReturnRows(rows)
+		mock.ExpectQuery(query).WithArgs(todate, todate).WillReturnRows(rows)
 
 		// 関数を実行
-		result, err := repo.GetMatchAPI(db, todate)
+		result, err := repo.GetMatchAPI(db, todate, todate, "")
 
 		// エラーが期待通りであることを確認
 		assert.Error(t, err)
@@ -349,53 +324,45 @@ func TestGetScore(t *testing.T) {
 	defer db.Close()
 
 	t.Run("Success to get score result=試合中", func(t *testing.T) {
-		matchID := "7"
-		query := "SELECT home_score, away_score, batter, inning, result, match_id FROM scores WHERE match_id ='" + matchID + "'"
+		matchID := 7
+		query := regexp.QuoteMeta("SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?")
 
-		rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id"}).
-			AddRow("2", "1", "山田", "3回裏", "ホームラン", 7)
+		rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id", "pitcher", "balls", "strikes", "outs", "on_first", "on_second", "on_third"}).
+			AddRow("2", "1", "山田", "3回裏", "ホームラン", 7, "佐藤", 2, 1, 1, true, false, true)
 
-		mock.ExpectQuery(query).WillReturnRows(rows)
+		mock.ExpectQuery(query).WithArgs(matchID).WillReturnRows(rows)
 
 		result, err := repo.GetScore(db, matchID)
 		assert.NoError(t, err)
 
-		expected := []map[string]interface{}{
-			{
-				"match_id":   7,
-				"home_score": "2",
-				"away_score": "1",
-				"batter":     "山田",
-				"inning":     "3回裏",
-				"result":     "ホームラン",
-			},
+		pitcher, balls, strikes, outs := "佐藤", 2, 1, 1
+		expected := []models.Score{
+			{MatchID: 7, HomeScore: "2", AwayScore: "1", Batter: "山田", Inning: "3回裏", Result: "ホームラン",
+				Situation: models.Situation{
+					Pitcher: &pitcher, Balls: &balls, Strikes: &strikes, Outs: &outs,
+					Bases: &models.Bases{First: true, Second: false, Third: true},
+				}},
 		}
 
 		assert.Equal(t, expected, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	//試合中でない場合、試合中の状況はnil
 	t.Run("Success to get score result=試合前", func(t *testing.T) {
-		matchID := "7"
-		query := "SELECT home_score, away_score, batter, inning, result, match_id FROM scores WHERE match_id ='" + matchID + "'"
+		matchID := 7
+		query := regexp.QuoteMeta("SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?")
 
-		rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id"}).
-			AddRow("0", "0", "テスト", "試合前", "試合前", 7)
+		rows := sqlmock.NewRows([]string{"home_score", "away_score", "batter", "inning", "result", "match_id", "pitcher", "balls", "strikes", "outs", "on_first", "on_second", "on_third"}).
+			AddRow("0", "0", "テスト", "試合前", "試合前", 7, nil, nil, nil, nil, nil, nil, nil)
 
-		mock.ExpectQuery(query).WillReturnRows(rows)
+		mock.ExpectQuery(query).WithArgs(matchID).WillReturnRows(rows)
 
 		result, err := repo.GetScore(db, matchID)
 		assert.NoError(t, err)
 
-		expected := []map[string]interface{}{
-			{
-				"match_id":   7,
-				"home_score": "0",
-				"away_score": "0",
-				"batter":     "テスト",
-				"inning":     "試合前",
-				"result":     "試合前",
-			},
+		expected := []models.Score{
+			{MatchID: 7, HomeScore: "0", AwayScore: "0", Batter: "テスト", Inning: "試合前", Result: "試合前"},
 		}
 
 		assert.Equal(t, expected, result)
@@ -403,10 +370,10 @@ func TestGetScore(t *testing.T) {
 	})
 
 	t.Run("Fail to get score", func(t *testing.T) {
-		matchID := "7"
-		query := "SELECT home_score, away_score, batter, inning, result, match_id FROM scores WHERE match_id ='" + matchID + "'"
+		matchID := 7
+		query := regexp.QuoteMeta("SELECT home_score, away_score, batter, inning, result, match_id, pitcher, balls, strikes, outs, on_first, on_second, on_third FROM scores WHERE match_id = ?")
 
-		mock.ExpectQuery(query).WillReturnError(sql.ErrConnDone)
+		mock.ExpectQuery(query).WithArgs(matchID).WillReturnError(sql.ErrConnDone)
 
 		result, err := repo.GetScore(db, matchID)
 		assert.Error(t, err)
@@ -424,76 +391,54 @@ func TestGetMatchScoreLive(t *testing.T) {
 	defer db.Close()
 
 	t.Run("Success to get ongoing matches", func(t *testing.T) {
-		query := `
-			SELECT 
-				m.id, 
-				m.date, 
-				m.home, 
-				m.away, 
-				m.league, 
-				m.stadium, 
-				m.starttime,
-				m.link,
-				s.inning
-			FROM 
-				matches m
-			LEFT JOIN 
-				scores s ON m.id = s.match_id
-			WHERE 
-				m.date = CURDATE() AND
-				m.starttime <= CURTIME() AND
-				(s.inning <> '試合終了' AND s.inning <> '試合中止')
-			`
+		query := liveMatchQuery
 		rows := sqlmock.NewRows([]string{
-			"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning",
-		}).AddRow(1, "2025-06-09", "チームA", "チームB", "セリーグ", "東京ドーム", "22:00:00", "http://example.com", "試合中")
+			"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning", "home_score", "away_score", "batter", "result",
+		}).AddRow(1, "2025-06-09", "チームA", "チームB", "セリーグ", "東京ドーム", "18:05:00", "http://example.com", "3回表", "1", "0", "山田", "三振")
 
 		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows)
 
 		results, err := repo.GetMatchScoreLive(db)
 		assert.NoError(t, err)
 
-		expected := []map[string]interface{}{
+		expected := []models.LiveMatch{
 			{
-				"id":        1,
-				"date":      "2025-06-01",
-				"home":      "チームA",
-				"away":      "チームB",
-				"league":    "セリーグ",
-				"stadium":   "東京ドーム",
-				"starttime": "18:05:00",
-				"link":      "http://example.com",
-				"inning":    "試合中",
+				Match:     models.Match{ID: 1, Date: "2025-06-09", Home: "チームA", Away: "チームB", League: "セリーグ", Stadium: "東京ドーム", StartTime: "18:05:00", Link: "http://example.com"},
+				Inning:    "3回表",
+				HomeScore: "1",
+				AwayScore: "0",
+				Batter:    "山田",
+				Result:    "三振",
 			},
 		}
 		assert.Equal(t, expected, results)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Success with no score row", func(t *testing.T) {
+		//スコア未登録の列はNULLでも空文字として扱う
+		rows := sqlmock.NewRows([]string{
+			"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning", "home_score", "away_score", "batter", "result",
+		}).AddRow(2, "2025-06-09", "チームC", "チームD", "パリーグ", "京セラドーム", "18:00:00", "http://example.com/2", nil, nil, nil, nil, nil)
+
+		mock.ExpectQuery(`FROM\s+matches m\s+LEFT JOIN`).WillReturnRows(rows)
+
+		results, err := repo.GetMatchScoreLive(db)
+		assert.NoError(t, err)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, 2, results[0].ID)
+			assert.Equal(t, "http://example.com/2", results[0].Link)
+			assert.Equal(t, "", results[0].Inning)
+			assert.Equal(t, "", results[0].HomeScore)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Success with no results", func(t *testing.T) {
-		query := `
-			SELECT 
-				m.id, 
-				m.date, 
-				m.home, 
-				m.away, 
-				m.league, 
-				m.stadium, 
-				m.starttime,
-				m.link,
-				s.inning
-			FROM 
-				matches m
-			LEFT JOIN 
-				scores s ON m.id = s.match_id
-			WHERE 
-				m.date = CURDATE() AND
-				m.starttime <= CURTIME() AND
-				(s.inning <> '試合終了' AND s.inning <> '試合中止')
-			`
+		query := liveMatchQuery
 
 		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(sqlmock.NewRows([]string{
-			"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning",
+			"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning", "home_score", "away_score", "batter", "result",
 		}))
 
 		results, err := repo.GetMatchScoreLive(db)
@@ -503,26 +448,7 @@ func TestGetMatchScoreLive(t *testing.T) {
 	})
 
 	t.Run("Fail to query", func(t *testing.T) {
-		query := `
-			SELECT 
-				m.id, 
-				m.date, 
-				m.home, 
-				m.away, 
-				m.league, 
-				m.stadium, 
-				m.starttime,
-				m.link,
-				s.inning
-			FROM 
-				matches m
-			LEFT JOIN 
-				scores s ON m.id = s.match_id
-			WHERE 
-				m.date = CURDATE() AND
-				m.starttime <= CURTIME() AND
-				(s.inning <> '試合終了' AND s.inning <> '試合中止')
-			`
+		query := liveMatchQuery
 
 		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
 
@@ -532,3 +458,750 @@ func TestGetMatchScoreLive(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestGetMatchDetail(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	query := `LEFT JOIN\s+scores s ON m.id = s.match_id\s+WHERE\s+m.id = \?`
+	columns := []string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}
+
+	t.Run("Success to get match detail", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "2025-04-06", "ヤクルト", "中日", "セ・リーグ", "神宮", "13:00:00", "試合終了", "5", "3", "", "")
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+
+		result, err := repo.GetMatchDetail(db, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.ID)
+		assert.Equal(t, "ヤクルト", result.Home)
+		assert.Equal(t, "試合終了", result.Inning)
+		assert.Equal(t, "5", result.HomeScore)
+		assert.Equal(t, "3", result.AwayScore)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(2).WillReturnRows(sqlmock.NewRows(columns))
+
+		result, err := repo.GetMatchDetail(db, 2)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to query", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(3).WillReturnError(sql.ErrConnDone)
+
+		result, err := repo.GetMatchDetail(db, 3)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetMatchDetails(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}
+
+	t.Run("Success to get by ids and league", func(t *testing.T) {
+		query := regexp.QuoteMeta("m.id IN (?, ?) OR (m.league = ? AND m.date = ? AND m.status <> 'removed')")
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "2025-04-06", "ヤクルト", "中日", "セ・リーグ", "神宮", "13:00:00", "2回表", "0", "0", "細川", "").
+			AddRow(2, "2025-04-06", "広島", "DeNA", "セ・リーグ", "マツダスタジアム", "13:00:00", nil, nil, nil, nil, nil)
+		mock.ExpectQuery(query).WithArgs(1, 2, "セ・リーグ", "2025-04-06").WillReturnRows(rows)
+
+		result, err := repo.GetMatchDetails(db, []int{1, 2}, "セ・リーグ", "2025-04-06")
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "細川", result[0].Batter)
+		assert.Equal(t, "", result[1].Inning)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No condition", func(t *testing.T) {
+		result, err := repo.GetMatchDetails(db, nil, "", "2025-04-06")
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to query", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("m.id IN (?)")).WithArgs(3).WillReturnError(sql.ErrConnDone)
+
+		result, err := repo.GetMatchDetails(db, []int{3}, "", "2025-04-06")
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//読み込みの途中で失敗した場合は途中までの結果を返さない
+	t.Run("Fail to read rows", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(4, "2025-04-06", "ヤクルト", "中日", "セ・リーグ", "神宮", "13:00:00", "2回表", "0", "0", "細川", "").
+			AddRow(5, "2025-04-06", "広島", "DeNA", "セ・リーグ", "マツダスタジアム", "13:00:00", nil, nil, nil, nil, nil).
+			RowError(1, sql.ErrConnDone)
+		mock.ExpectQuery(regexp.QuoteMeta("m.id IN (?, ?)")).WithArgs(4, 5).WillReturnRows(rows)
+
+		result, err := repo.GetMatchDetails(db, []int{4, 5}, "", "2025-04-06")
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Insert webhook", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO webhooks (url, secret, events) VALUES (?, ?, ?)")).
+			WithArgs("https://example.com", "secret", "game_start,game_end").
+			WillReturnResult(sqlmock.NewResult(5, 1))
+
+		id, err := repo.InsertWebhook(db, models.Webhook{URL: "https://example.com", Secret: "secret", Events: []string{"game_start", "game_end"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Get webhooks", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "url", "secret", "events", "created_at"}).
+			AddRow(1, "https://example.com", "secret", "game_start,game_end", "2025-04-06 12:00:00")
+		mock.ExpectQuery(regexp.QuoteMeta("FROM webhooks WHERE active = TRUE")).WillReturnRows(rows)
+
+		hooks, err := repo.GetWebhooks(db)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Webhook{
+			{ID: 1, URL: "https://example.com", Secret: "secret", Events: []string{"game_start", "game_end"}, CreatedAt: "2025-04-06 12:00:00"},
+		}, hooks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Delete webhook not found", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE webhooks SET active = FALSE")).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteWebhook(db, 2)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Insert dead letter", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO webhook_dead_letters")).
+			WithArgs(1, "game_end", `{"type":"game_end"}`, 5, "unexpected status: 503").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		_, err := repo.InsertDeadLetter(db, models.DeadLetter{WebhookID: 1, Event: "game_end", Payload: `{"type":"game_end"}`, Attempts: 5, LastError: "unexpected status: 503"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetScoreEvents(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	query := `FROM score_events\s+WHERE match_id = \?\s+ORDER BY id`
+
+	t.Run("Success to get score events", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "inning", "home_score", "away_score", "batter", "result", "created_at"}).
+			AddRow(1, "1回表", "0", "0", "山田", "", "2025-04-06 18:01:00").
+			AddRow(2, "1回表", "0", "0", "山田", "三振", "2025-04-06 18:02:00")
+		mock.ExpectQuery(query).WithArgs(7).WillReturnRows(rows)
+
+		events, err := repo.GetScoreEvents(db, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ScoreEvent{
+			{ID: 1, Inning: "1回表", HomeScore: "0", AwayScore: "0", Batter: "山田", Result: "", CreatedAt: "2025-04-06 18:01:00"},
+			{ID: 2, Inning: "1回表", HomeScore: "0", AwayScore: "0", Batter: "山田", Result: "三振", CreatedAt: "2025-04-06 18:02:00"},
+		}, events)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to query", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(7).WillReturnError(sql.ErrConnDone)
+
+		events, err := repo.GetScoreEvents(db, 7)
+		assert.Error(t, err)
+		assert.Nil(t, events)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetLineScore(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	query := `LEFT JOIN line_scores l ON m.id = l.match_id\s+WHERE m.id = \?`
+	columns := []string{"home", "away", "side", "innings", "runs", "hits", "errors"}
+
+	t.Run("Success to get line score", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("巨人", "阪神", "away", "0,3,0", "3", "5", "0").
+			AddRow("巨人", "阪神", "home", "4,0,", "4", "6", "1"))
+
+		lineScore, err := repo.GetLineScore(db, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.MatchLineScore{
+			MatchID: 1,
+			Away:    models.LineScore{Team: "阪神", Innings: []string{"0", "3", "0"}, Runs: "3", Hits: "5", Errors: "0"},
+			Home:    models.LineScore{Team: "巨人", Innings: []string{"4", "0", ""}, Runs: "4", Hits: "6", Errors: "1"},
+		}, lineScore)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//ラインスコアが未登録の場合はイニングが空
+	t.Run("Line score not registered", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(2).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("広島", "DeNA", nil, nil, nil, nil, nil))
+
+		lineScore, err := repo.GetLineScore(db, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "DeNA", lineScore.Away.Team)
+		assert.Equal(t, []string{}, lineScore.Home.Innings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Match not found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(99).WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetLineScore(db, 99)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveSchedule(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	matchQuery := `INSERT INTO matches .+ ON DUPLICATE KEY UPDATE\s+id = LAST_INSERT_ID\(id\)`
+	scoreQuery := `INSERT INTO scores \(match_id\)\s+VALUES \(\?\)\s+ON DUPLICATE KEY UPDATE`
+	schedule := []models.Match{
+		{Date: "2025-04-06", Home: "ヤクルト", Away: "中日", League: "セ・リーグ", Stadium: "神宮", StartTime: "13:00", Link: "https://example.com/1/score",
+			HomeTeamID: "swallows", AwayTeamID: "dragons", StadiumID: "jingu"},
+	}
+
+	t.Run("Success to save schedule", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(matchQuery).
+			WithArgs("2025-04-06", "ヤクルト", "中日", "神宮", "13:00", "https://example.com/1/score", "セ・リーグ", "swallows", "dragons", "jingu").
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectExec(scoreQuery).WithArgs(int64(3)).WillReturnResult(sqlmock.NewResult(8, 1))
+		mock.ExpectCommit()
+
+		ids, err := repo.SaveSchedule(db, schedule)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3}, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//登録されていないチーム・球場のIDはNULL
+	t.Run("Unknown stadium", func(t *testing.T) {
+		local := schedule[0]
+		local.Stadium, local.StadiumID = "富山", ""
+		mock.ExpectBegin()
+		mock.ExpectExec(matchQuery).
+			WithArgs("2025-04-06", "ヤクルト", "中日", "富山", "13:00", "https://example.com/1/score", "セ・リーグ", "swallows", "dragons", nil).
+			WillReturnResult(sqlmock.NewResult(4, 1))
+		mock.ExpectExec(scoreQuery).WithArgs(int64(4)).WillReturnResult(sqlmock.NewResult(9, 1))
+		mock.ExpectCommit()
+
+		_, err := repo.SaveSchedule(db, []models.Match{local})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback when match upsert fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(matchQuery).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		ids, err := repo.SaveSchedule(db, schedule)
+		assert.Error(t, err)
+		assert.Nil(t, ids)
+		assert.Contains(t, err.Error(), "failed to upsert match")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to begin", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+
+		ids, err := repo.SaveSchedule(db, schedule)
+		assert.Error(t, err)
+		assert.Nil(t, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRemoveMissingMatches(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	query := regexp.QuoteMeta("WHERE date = ? AND status <> 'removed' AND id NOT IN (?, ?)")
+
+	t.Run("Success to remove missing matches", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("2025-04-06", 3, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		n, err := repo.RemoveMissingMatches(db, "2025-04-06", []int{3, 4})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//試合がない日はその日の試合をすべてremovedにする
+	t.Run("Empty ids", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("WHERE date = ? AND status <> 'removed'") + "$").WithArgs("2025-04-06").WillReturnResult(sqlmock.NewResult(0, 2))
+
+		n, err := repo.RemoveMissingMatches(db, "2025-04-06", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to update", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.RemoveMissingMatches(db, "2025-04-06", []int{3, 4})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to remove missing matches")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// 選手と成績のカラム
+var playerColumns = []string{
+	"id", "name", "team", "number", "position", "season",
+	"bat_games", "bat_plate_appearances", "bat_at_bats", "bat_hits", "bat_home_runs", "bat_rbi", "bat_stolen_bases",
+	"bat_walks", "bat_strikeouts", "bat_avg", "bat_obp", "bat_slg", "bat_ops",
+	"pit_games", "pit_wins", "pit_losses", "pit_saves", "pit_holds", "pit_innings", "pit_strikeouts",
+	"pit_walks", "pit_earned_runs", "pit_era",
+}
+
+func TestSavePlayers(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	playerQuery := `INSERT INTO players .+ ON DUPLICATE KEY UPDATE`
+	statsQuery := `INSERT INTO player_stats .+ ON DUPLICATE KEY UPDATE`
+	era := 2.5
+	players := []models.Player{
+		{ID: 1000001, Name: "投手 一郎", Team: "阪神", Number: "11", Position: models.PositionPitcher,
+			Pitching: &models.PitchingStats{Games: 20, Wins: 8, Losses: 5, Innings: "108.1", Strikeouts: 100, Walks: 30, EarnedRuns: 30, ERA: &era}},
+	}
+
+	//打撃成績がない選手は打撃のカラムをNULLにする
+	t.Run("Success to save players", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(playerQuery).WithArgs(1000001, "投手 一郎", "阪神", "11", models.PositionPitcher).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(statsQuery).
+			WithArgs(1000001, 2025,
+				nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+				20, 8, 5, 0, 0, "108.1", 100, 30, 30, 2.5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.SavePlayers(db, 2025, players)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback when stats upsert fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(playerQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(statsQuery).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err := repo.SavePlayers(db, 2025, players)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to upsert player stats")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetPlayer(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Latest season", func(t *testing.T) {
+		mock.ExpectQuery(`LEFT JOIN player_stats s ON s.player_id = p.id WHERE p.id = \? ORDER BY s.season DESC LIMIT 1`).
+			WithArgs(1100001).
+			WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(
+				1100001, "村上 宗隆", "ヤクルト", "55", models.PositionBatter, 2025,
+				140, 600, 510, 159, 40, 110, 5, 80, 130, 0.312, 0.42, 0.6, 1.02,
+				nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+
+		player, err := repo.GetPlayer(db, 1100001, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2025, player.Season)
+		assert.Equal(t, 40, player.Batting.HomeRuns)
+		assert.Equal(t, 0.312, *player.Batting.Avg)
+		assert.Nil(t, player.Pitching)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//指定したシーズンの成績が未登録の場合は成績がnil
+	t.Run("Season not registered", func(t *testing.T) {
+		row := []driver.Value{1100001, "村上 宗隆", "ヤクルト", "55", models.PositionBatter}
+		mock.ExpectQuery(`AND s.season = \? WHERE p.id = \?`).
+			WithArgs(2020, 1100001).
+			WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(append(row, make([]driver.Value, 24)...)...))
+
+		player, err := repo.GetPlayer(db, 1100001, 2020)
+		assert.NoError(t, err)
+		assert.Equal(t, 2020, player.Season)
+		assert.Nil(t, player.Batting)
+		assert.Nil(t, player.Pitching)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Player not found", func(t *testing.T) {
+		mock.ExpectQuery(`WHERE p.id = \?`).WithArgs(99).WillReturnRows(sqlmock.NewRows(playerColumns))
+
+		_, err := repo.GetPlayer(db, 99, 0)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTeamPlayers(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Sort by era", func(t *testing.T) {
+		mock.ExpectQuery(`WHERE p.team = \?\s+ORDER BY s.pit_era IS NULL, s.pit_era ASC, p.id`).
+			WithArgs(2025, "阪神").
+			WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(
+				1000001, "投手 一郎", "阪神", "11", models.PositionPitcher, 2025,
+				nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+				20, 8, 5, 0, 0, "108.1", 100, 30, 30, 2.5))
+
+		players, err := repo.GetTeamPlayers(db, "阪神", 2025, "era", false)
+		assert.NoError(t, err)
+		if assert.Len(t, players, 1) {
+			assert.Equal(t, "108.1", players[0].Pitching.Innings)
+			assert.Nil(t, players[0].Batting)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//成績がない選手も最後に含める
+	t.Run("Players without stats", func(t *testing.T) {
+		mock.ExpectQuery(`LEFT JOIN player_stats s ON s.player_id = p.id AND s.season = \?\s+WHERE p.team = \?\s+ORDER BY s.bat_avg IS NULL, s.bat_avg DESC, p.id`).
+			WithArgs(2025, "阪神").
+			WillReturnRows(sqlmock.NewRows(playerColumns).
+				AddRow(1000003, "野手 三郎", "阪神", "5", models.PositionBatter, 2025,
+					100, 400, 360, 90, 10, 50, 3, 30, 80, 0.25, 0.31, 0.4, 0.71,
+					nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+				AddRow(1000004, "新人 四郎", "阪神", "00", models.PositionBatter, nil,
+					nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+					nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+
+		players, err := repo.GetTeamPlayers(db, "阪神", 2025, "avg", true)
+		assert.NoError(t, err)
+		if assert.Len(t, players, 2) {
+			assert.Equal(t, 1000003, players[0].ID)
+			assert.NotNil(t, players[0].Batting)
+			assert.Equal(t, 1000004, players[1].ID)
+			assert.Equal(t, 2025, players[1].Season)
+			assert.Nil(t, players[1].Batting)
+			assert.Nil(t, players[1].Pitching)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No players", func(t *testing.T) {
+		mock.ExpectQuery(`ORDER BY s.bat_home_runs IS NULL, s.bat_home_runs DESC`).
+			WithArgs(2025, "巨人").
+			WillReturnRows(sqlmock.NewRows(playerColumns))
+
+		players, err := repo.GetTeamPlayers(db, "巨人", 2025, "hr", true)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Player{}, players)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown sort", func(t *testing.T) {
+		_, err := repo.GetTeamPlayers(db, "巨人", 2025, "war", true)
+		assert.Error(t, err)
+	})
+}
+
+// 順位表に含める公式戦の条件
+var regularSeason = RegularSeason{Leagues: []string{"セ・リーグ", "パ・リーグ"}, Interleague: "交流戦"}
+
+func TestGetUnrecordedGames(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}
+
+	t.Run("Success to get by match id", func(t *testing.T) {
+		query := regexp.QuoteMeta("s.inning = '試合終了' AND r.match_id IS NULL AND m.status <> 'removed' AND (m.league IN (?, ?) OR m.league LIKE ?) AND m.id = ?")
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "2025-04-06", "ヤクルト", "中日", "セ・リーグ", "神宮", "13:00:00", "試合終了", "3", "2", "", "")
+		mock.ExpectQuery(query).WithArgs("セ・リーグ", "パ・リーグ", "%交流戦%", 1).WillReturnRows(rows)
+
+		result, err := repo.GetUnrecordedGames(db, 1, regularSeason)
+		assert.NoError(t, err)
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, "3", result[0].HomeScore)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to query", func(t *testing.T) {
+		mock.ExpectQuery(`m.league LIKE \?\) ORDER BY m.date, m.id`).WithArgs("セ・リーグ", "パ・リーグ", "%交流戦%").WillReturnError(sql.ErrConnDone)
+
+		result, err := repo.GetUnrecordedGames(db, 0, regularSeason)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveGameResults(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	query := `INSERT INTO game_results .+ ON DUPLICATE KEY UPDATE`
+	results := []models.GameResult{
+		{MatchID: 1, Date: "2025-04-06", Team: "ヤクルト", Opponent: "中日", Home: true, Runs: 3, OpponentRuns: 2, Result: models.ResultWin},
+		{MatchID: 1, Date: "2025-04-06", Team: "中日", Opponent: "ヤクルト", Home: false, Runs: 2, OpponentRuns: 3, Result: models.ResultLoss},
+	}
+
+	t.Run("Success to save results", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WithArgs(1, "ヤクルト", "中日", "2025-04-06", true, 3, 2, "W").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(query).WithArgs(1, "中日", "ヤクルト", "2025-04-06", false, 2, 3, "L").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.SaveGameResults(db, results))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback when upsert fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err := repo.SaveGameResults(db, results)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to upsert game result")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRegularSeason_cond(t *testing.T) {
+	t.Run("Leagues and interleague", func(t *testing.T) {
+		cond, args := regularSeason.cond()
+		assert.Equal(t, "(m.league IN (?, ?) OR m.league LIKE ?)", cond)
+		assert.Equal(t, []interface{}{"セ・リーグ", "パ・リーグ", "%交流戦%"}, args)
+	})
+
+	t.Run("Leagues only", func(t *testing.T) {
+		cond, args := RegularSeason{Leagues: []string{"セ・リーグ"}}.cond()
+		assert.Equal(t, "(m.league IN (?))", cond)
+		assert.Equal(t, []interface{}{"セ・リーグ"}, args)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		// チームが未登録の場合はどの試合も公式戦として扱わない
+		cond, args := RegularSeason{}.cond()
+		assert.Equal(t, "FALSE", cond)
+		assert.Nil(t, args)
+	})
+}
+
+func TestGetGameResults(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"match_id", "date", "team", "opponent", "home", "runs", "opponent_runs", "result"}).
+		AddRow(1, "2025-04-06", "ヤクルト", "中日", true, 3, 2, "W")
+	//オープン戦・ポストシーズンと日程から消えた試合は含めない
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE r.date BETWEEN ? AND ? AND m.status <> 'removed' AND (m.league IN (?, ?) OR m.league LIKE ?)")).
+		WithArgs("2025-01-01", "2025-04-30", "セ・リーグ", "パ・リーグ", "%交流戦%").WillReturnRows(rows)
+
+	result, err := repo.GetGameResults(db, "2025-01-01", "2025-04-30", regularSeason)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.GameResult{
+		{MatchID: 1, Date: "2025-04-06", Team: "ヤクルト", Opponent: "中日", Home: true, Runs: 3, OpponentRuns: 2, Result: models.ResultWin},
+	}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTeamGames(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"id", "date", "home", "away", "league", "stadium", "starttime", "inning", "home_score", "away_score", "batter", "result"}
+
+	t.Run("Success", func(t *testing.T) {
+		query := regexp.QuoteMeta("(m.home IN (?, ?) OR m.away IN (?, ?)) AND m.date BETWEEN ? AND ?")
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "2025-04-06", "DeNA", "中日", "セ・リーグ", "横浜", "13:00:00", "試合終了", "3", "2", "", "").
+			AddRow(2, "2025-04-07", "阪神", "DeNA", "セ・リーグ", "甲子園", "18:00:00", nil, nil, nil, nil, nil)
+		mock.ExpectQuery(query).WithArgs("DeNA", "横浜DeNA", "DeNA", "横浜DeNA", "2025-01-01", "2025-12-31").WillReturnRows(rows)
+
+		result, err := repo.GetTeamGames(db, []string{"DeNA", "横浜DeNA"}, "2025-01-01", "2025-12-31")
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to query", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("m.home IN (?)")).WillReturnError(sql.ErrConnDone)
+
+		result, err := repo.GetTeamGames(db, []string{"DeNA"}, "2025-01-01", "2025-12-31")
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBackfillCheckpoints(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Get checkpoints", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE date BETWEEN ? AND ?")).WithArgs("2024-03-29", "2024-10-06").
+			WillReturnRows(sqlmock.NewRows([]string{"date"}).AddRow("2024-03-29").AddRow("2024-03-30"))
+
+		done, err := repo.GetBackfillCheckpoints(db, "2024-03-29", "2024-10-06")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"2024-03-29": true, "2024-03-30": true}, done)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Save checkpoint", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO backfill_checkpoints .+ ON DUPLICATE KEY UPDATE`).WithArgs("2024-03-29", 6).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SaveBackfillCheckpoint(db, "2024-03-29", 6))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to save checkpoint", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO backfill_checkpoints`).WillReturnError(sql.ErrConnDone)
+
+		err := repo.SaveBackfillCheckpoint(db, "2024-03-29", 6)
+		assert.ErrorContains(t, err, "failed to save backfill checkpoint")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTeams(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Get teams", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, name_en, abbreviation, league FROM teams ORDER BY league, id")).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "name", "name_en", "abbreviation", "league"}).
+				AddRow("giants", "巨人", "Yomiuri Giants", "G", "セ・リーグ").
+				AddRow("swallows", "ヤクルト", "Tokyo Yakult Swallows", "S", "セ・リーグ"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT alias, team_id FROM team_aliases ORDER BY team_id, alias")).WillReturnRows(
+			sqlmock.NewRows([]string{"alias", "team_id"}).AddRow("ジャイアンツ", "giants").AddRow("読売", "giants"))
+
+		teams, err := repo.GetTeams(db)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Team{
+			{ID: "giants", Name: "巨人", NameEn: "Yomiuri Giants", Abbreviation: "G", League: "セ・リーグ", Aliases: []string{"ジャイアンツ", "読売"}},
+			{ID: "swallows", Name: "ヤクルト", NameEn: "Tokyo Yakult Swallows", Abbreviation: "S", League: "セ・リーグ", Aliases: []string{}},
+		}, teams)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to fetch aliases", func(t *testing.T) {
+		mock.ExpectQuery("FROM teams").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "name_en", "abbreviation", "league"}))
+		mock.ExpectQuery("FROM team_aliases").WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.GetTeams(db)
+		assert.ErrorContains(t, err, "failed to fetch team aliases")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetStadiums(t *testing.T) {
+	repo := &DefaultRepository{}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	//本拠地のない球場はTeamIDが空
+	t.Run("Get stadiums", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, name_en, team_id FROM stadiums ORDER BY id")).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "name", "name_en", "team_id"}).
+				AddRow("koshien", "甲子園", "Hanshin Koshien Stadium", "tigers").
+				AddRow("toyama", "富山", "Toyama Alpen Stadium", nil))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT alias, stadium_id FROM stadium_aliases ORDER BY stadium_id, alias")).WillReturnRows(
+			sqlmock.NewRows([]string{"alias", "stadium_id"}).AddRow("阪神甲子園球場", "koshien"))
+
+		stadiums, err := repo.GetStadiums(db)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Stadium{
+			{ID: "koshien", Name: "甲子園", NameEn: "Hanshin Koshien Stadium", TeamID: "tigers", Aliases: []string{"阪神甲子園球場"}},
+			{ID: "toyama", Name: "富山", NameEn: "Toyama Alpen Stadium", Aliases: []string{}},
+		}, stadiums)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fail to fetch", func(t *testing.T) {
+		mock.ExpectQuery("FROM stadiums").WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.GetStadiums(db)
+		assert.ErrorContains(t, err, "failed to fetch stadiums")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"baseball_report/internal/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// 試合情報の登録・更新
+// linkまたは(date, home, away)が既存の試合と一致した場合は開始時刻・球場・リーグ・チームと球場のIDを更新し、既存のidを返す
+// 同じlinkの試合が別の日に移動した場合はrescheduledにして元の日付を残し、日程から消えた試合が再び載った場合はscheduledに戻す
+// （MySQLは左から順に代入するため、dateより先にoriginal_date・statusを更新する）
+const upsertMatchQuery = `
+			INSERT INTO matches (date, home, away, stadium, starttime, link, league, home_team_id, away_team_id, stadium_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				id = LAST_INSERT_ID(id),
+				original_date = IF(date <> VALUES(date), COALESCE(original_date, date), original_date),
+				status = IF(date <> VALUES(date), 'rescheduled', IF(status = 'removed', 'scheduled', status)),
+				date = VALUES(date),
+				stadium = VALUES(stadium),
+				starttime = VALUES(starttime),
+				league = VALUES(league),
+				home_team_id = VALUES(home_team_id),
+				away_team_id = VALUES(away_team_id),
+				stadium_id = VALUES(stadium_id)
+			`
+
+// 試合進捗の初期行を登録（登録済みの場合は何もしない）
+const insertScoreQuery = `
+			INSERT INTO scores (match_id)
+			VALUES (?)
+			ON DUPLICATE KEY UPDATE match_id = match_id
+			`
+
+// 試合日程を保存し、各試合のidを返す
+// 再実行しても重複せず、matchesとscoresは1つのトランザクションで登録する
+func (d *DefaultRepository) SaveSchedule(db *sql.DB, matches []models.Match) ([]int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Commit後のRollbackは何もしない
+	defer tx.Rollback()
+
+	ids := make([]int, 0, len(matches))
+	for _, match := range matches {
+		result, err := tx.Exec(upsertMatchQuery, match.Date, match.Home, match.Away, match.Stadium, match.StartTime, match.Link, match.League,
+			nullStringArg(match.HomeTeamID), nullStringArg(match.AwayTeamID), nullStringArg(match.StadiumID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert match: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		if _, err := tx.Exec(insertScoreQuery, id); err != nil {
+			return nil, fmt.Errorf("failed to insert score: %w", err)
+		}
+		ids = append(ids, int(id))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit schedule: %w", err)
+	}
+	return ids, nil
+}
+
+// date（YYYY-MM-DD）の試合のうちidsに含まれない試合（日程から消えた試合）をremovedにし、件数を返す
+// idsが空の場合はその日の試合をすべてremovedにする（試合がないと表示された日の日程のみ渡す）
+func (d *DefaultRepository) RemoveMissingMatches(db *sql.DB, date string, ids []int) (int, error) {
+	query := `
+			UPDATE matches SET status = 'removed'
+			WHERE date = ? AND status <> 'removed'`
+	if len(ids) != 0 {
+		query += ` AND id NOT IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+	}
+	args := []interface{}{date}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove missing matches: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+// 空文字をNULLとして登録する（登録されていないチーム・球場のID）
+func nullStringArg(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"baseball_report/internal/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// 試合結果の登録・更新（同じ試合を再登録しても重複しない）
+const upsertGameResultQuery = `
+			INSERT INTO game_results (match_id, team, opponent, date, home, runs, opponent_runs, result)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				runs = VALUES(runs),
+				opponent_runs = VALUES(opponent_runs),
+				result = VALUES(result)
+			`
+
+// RegularSeason 順位表に含める公式戦の試合の条件（オープン戦・ポストシーズンを除く）
+type RegularSeason struct {
+	// リーグ戦の見出し（リーグ名）
+	Leagues []string
+	// 交流戦の見出しに含まれる文字列
+	Interleague string
+}
+
+// 公式戦の条件のSQLと引数
+func (rs RegularSeason) cond() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if len(rs.Leagues) != 0 {
+		conds = append(conds, "m.league IN (?"+strings.Repeat(", ?", len(rs.Leagues)-1)+")")
+		for _, league := range rs.Leagues {
+			args = append(args, league)
+		}
+	}
+	if rs.Interleague != "" {
+		conds = append(conds, "m.league LIKE ?")
+		args = append(args, "%"+rs.Interleague+"%")
+	}
+	if len(conds) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args
+}
+
+// 試合結果が未登録の終了したseasonの条件に合う公式戦の試合を取得（matchIDが0の場合はすべての試合）
+func (d *DefaultRepository) GetUnrecordedGames(db *sql.DB, matchID int, season RegularSeason) ([]models.MatchDetail, error) {
+	cond, args := season.cond()
+	query := matchDetailQuery + `
+			LEFT JOIN
+				game_results r ON m.id = r.match_id
+			WHERE
+				s.inning = '試合終了' AND r.match_id IS NULL AND m.status <> 'removed' AND ` + cond + `
+			`
+	if matchID != 0 {
+		query += " AND m.id = ?"
+		args = append(args, matchID)
+	}
+	query += " ORDER BY m.date, m.id"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch unrecorded games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []models.MatchDetail
+	for rows.Next() {
+		game, err := scanMatchDetail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match row: %w", err)
+		}
+		games = append(games, *game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch unrecorded games: %w", err)
+	}
+	return games, nil
+}
+
+// 試合結果を保存する（1つのトランザクションで登録する）
+func (d *DefaultRepository) SaveGameResults(db *sql.DB, results []models.GameResult) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Commit後のRollbackは何もしない
+	defer tx.Rollback()
+
+	for _, r := range results {
+		if _, err := tx.Exec(upsertGameResultQuery, r.MatchID, r.Team, r.Opponent, r.Date, r.Home, r.Runs, r.OpponentRuns, r.Result); err != nil {
+			return fmt.Errorf("failed to upsert game result: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit game results: %w", err)
+	}
+	return nil
+}
+
+// from〜to（YYYY-MM-DD）のseasonの条件に合う公式戦の試合結果を日付順に取得（日程から消えた試合を除く）
+func (d *DefaultRepository) GetGameResults(db *sql.DB, from, to string, season RegularSeason) ([]models.GameResult, error) {
+	cond, condArgs := season.cond()
+	query := `
+			SELECT r.match_id, r.date, r.team, r.opponent, r.home, r.runs, r.opponent_runs, r.result
+			FROM game_results r
+			JOIN matches m ON m.id = r.match_id
+			WHERE r.date BETWEEN ? AND ? AND m.status <> 'removed' AND ` + cond + `
+			ORDER BY r.date, r.match_id
+			`
+	rows, err := db.Query(query, append([]interface{}{from, to}, condArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch game results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.GameResult
+	for rows.Next() {
+		var r models.GameResult
+		if err := rows.Scan(&r.MatchID, &r.Date, &r.Team, &r.Opponent, &r.Home, &r.Runs, &r.OpponentRuns, &r.Result); err != nil {
+			return nil, fmt.Errorf("failed to scan game result row: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch game results: %w", err)
+	}
+	return results, nil
+}
+
+// namesのいずれかのチームがホームまたはアウェイのfrom〜to（YYYY-MM-DD）の試合詳細を日付順に取得（日程から消えた試合を除く）
+func (d *DefaultRepository) GetTeamGames(db *sql.DB, names []string, from, to string) ([]models.MatchDetail, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	in := "(?" + strings.Repeat(", ?", len(names)-1) + ")"
+	query := matchDetailQuery + `
+			WHERE
+				(m.home IN ` + in + ` OR m.away IN ` + in + `) AND m.date BETWEEN ? AND ? AND m.status <> 'removed'
+			ORDER BY m.date, m.starttime, m.id
+			`
+	args := make([]interface{}, 0, len(names)*2+2)
+	for i := 0; i < 2; i++ {
+		for _, name := range names {
+			args = append(args, name)
+		}
+	}
+	args = append(args, from, to)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch team games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []models.MatchDetail
+	for rows.Next() {
+		game, err := scanMatchDetail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match row: %w", err)
+		}
+		games = append(games, *game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch team games: %w", err)
+	}
+	return games, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"baseball_report/internal/models"
+	"database/sql"
+	"fmt"
+)
+
+// チームと別表記を全件取得（リーグ、IDの順）
+func (d *DefaultRepository) GetTeams(db *sql.DB) ([]models.Team, error) {
+	rows, err := db.Query("SELECT id, name, name_en, abbreviation, league FROM teams ORDER BY league, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	position := map[string]int{}
+	for rows.Next() {
+		t := models.Team{Aliases: []string{}}
+		if err := rows.Scan(&t.ID, &t.Name, &t.NameEn, &t.Abbreviation, &t.League); err != nil {
+			return nil, fmt.Errorf("failed to scan team row: %w", err)
+		}
+		position[t.ID] = len(teams)
+		teams = append(teams, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch teams: %w", err)
+	}
+
+	aliases, err := getAliases(db, "SELECT alias, team_id FROM team_aliases ORDER BY team_id, alias")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch team aliases: %w", err)
+	}
+	for id, names := range aliases {
+		if i, ok := position[id]; ok {
+			teams[i].Aliases = names
+		}
+	}
+	return teams, nil
+}
+
+// 球場と別表記を全件取得（IDの順）
+func (d *DefaultRepository) GetStadiums(db *sql.DB) ([]models.Stadium, error) {
+	rows, err := db.Query("SELECT id, name, name_en, team_id FROM stadiums ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stadiums: %w", err)
+	}
+	defer rows.Close()
+
+	var stadiums []models.Stadium
+	position := map[string]int{}
+	for rows.Next() {
+		s := models.Stadium{Aliases: []string{}}
+		var teamID sql.NullString
+		if err := rows.Scan(&s.ID, &s.Name, &s.NameEn, &teamID); err != nil {
+			return nil, fmt.Errorf("failed to scan stadium row: %w", err)
+		}
+		s.TeamID = teamID.String
+		position[s.ID] = len(stadiums)
+		stadiums = append(stadiums, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch stadiums: %w", err)
+	}
+
+	aliases, err := getAliases(db, "SELECT alias, stadium_id FROM stadium_aliases ORDER BY stadium_id, alias")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stadium aliases: %w", err)
+	}
+	for id, names := range aliases {
+		if i, ok := position[id]; ok {
+			stadiums[i].Aliases = names
+		}
+	}
+	return stadiums, nil
+}
+
+// 別表記をIDごとにまとめて取得
+func getAliases(db *sql.DB, query string) (map[string][]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := map[string][]string{}
+	for rows.Next() {
+		var alias, id string
+		if err := rows.Scan(&alias, &id); err != nil {
+			return nil, err
+		}
+		aliases[id] = append(aliases[id], alias)
+	}
+	return aliases, rows.Err()
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"baseball_report/internal/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Webhookを登録
+func (d *DefaultRepository) InsertWebhook(db *sql.DB, hook models.Webhook) (int, error) {
+	query := "INSERT INTO webhooks (url, secret, events) VALUES (?, ?, ?)"
+	return d.InsertData(db, query, hook.URL, hook.Secret, strings.Join(hook.Events, ","))
+}
+
+// 有効なWebhookを全件取得
+func (d *DefaultRepository) GetWebhooks(db *sql.DB) ([]models.Webhook, error) {
+	query := "SELECT id, url, secret, events, created_at FROM webhooks WHERE active = TRUE ORDER BY id"
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []models.Webhook
+	for rows.Next() {
+		var hook models.Webhook
+		var events string
+		if err := rows.Scan(&hook.ID, &hook.URL, &hook.Secret, &events, &hook.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook row: %w", err)
+		}
+		hook.Events = []string{}
+		if events != "" {
+			hook.Events = strings.Split(events, ",")
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// Webhookを無効化
+// 送信失敗の記録を残すため行は削除しない
+func (d *DefaultRepository) DeleteWebhook(db *sql.DB, id int) error {
+	query := "UPDATE webhooks SET active = FALSE WHERE id = ? AND active = TRUE"
+	count, err := d.UpdateData(db, query, id)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 再送上限に達した通知を記録
+func (d *DefaultRepository) InsertDeadLetter(db *sql.DB, dl models.DeadLetter) (int, error) {
+	query := `
+			INSERT INTO webhook_dead_letters (webhook_id, event, payload, attempts, last_error)
+			VALUES (?, ?, ?, ?, ?)
+			`
+	return d.InsertData(db, query, dl.WebhookID, dl.Event, dl.Payload, dl.Attempts, dl.LastError)
+}
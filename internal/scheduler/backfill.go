@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/internal/source"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// 過去の日程の取り込みで、取得元へのリクエストの間隔の既定値
+const DefaultBackfillInterval = 3 * time.Second
+
+// リクエストの間隔の下限（テストで差し替えられるよう変数にする）
+var minBackfillInterval = time.Second
+
+// Backfill from〜toの各日の日程と、各試合の最終的な試合進捗・ラインスコアを取り込む
+// 取り込みが完了した日はbackfill_checkpointsに記録し、再実行時は飛ばす（失敗した日は次回やり直す）
+// 取得元の負荷を抑えるため、ページの取得ごとにinterval（1秒未満の場合は1秒）空ける
+func Backfill(ctx context.Context, from, to time.Time, interval time.Duration) error {
+	today := now()
+	if from.After(to) {
+		return fmt.Errorf("from %s is after to %s", from.Format(dateLayout), to.Format(dateLayout))
+	}
+	if !to.Before(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, to.Location())) {
+		return fmt.Errorf("to %s must be before today", to.Format(dateLayout))
+	}
+
+	src, err := sources()
+	if err != nil {
+		log.Println(fmt.Errorf("failed to get source: %w", err))
+		return err
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		log.Println(fmt.Errorf("failed to check to connect database: %w", err))
+		return err
+	}
+	defer db.Close()
+
+	done, err := repo.GetBackfillCheckpoints(db, from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	p := &pacer{interval: max(interval, minBackfillInterval)}
+	var errs []error
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(dateLayout)
+		if done[date] {
+			log.Println("Skip backfilled date:", date)
+			continue
+		}
+		games, err := backfillDate(ctx, db, src, p, d)
+		if err == nil {
+			err = repo.SaveBackfillCheckpoint(db, date, games)
+		}
+		if err != nil {
+			log.Println(fmt.Errorf("failed to backfill %s: %w", date, err))
+			errs = append(errs, fmt.Errorf("%s: %w", date, err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		log.Println("Backfilled:", date, games, "games")
+	}
+	return errors.Join(errs...)
+}
+
+// 1日分の日程と各試合の試合進捗を取り込み、試合数を返す
+// 失敗した試合があっても残りの試合は取り込む
+func backfillDate(ctx context.Context, db *sql.DB, src source.Source, p *pacer, date time.Time) (int, error) {
+	if err := p.wait(ctx); err != nil {
+		return 0, err
+	}
+	sctx, cancel := context.WithTimeout(ctx, scheduleTimeout)
+	schedule, err := src.Schedule(sctx, date)
+	cancel()
+	if err != nil {
+		recordParseFailure(db, err)
+		return 0, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	ids, err := saveSchedule(db, date, schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for i, match := range schedule {
+		if err := p.wait(ctx); err != nil {
+			return 0, err
+		}
+		live := models.LiveMatch{Match: match}
+		live.ID = ids[i]
+		sctx, cancel := context.WithTimeout(ctx, scoreTimeout)
+		_, err := updateScore(sctx, db, live)
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(schedule), errors.Join(errs...)
+}
+
+// 取得元へのリクエストの間隔を空ける
+type pacer struct {
+	interval time.Duration
+	last     time.Time
+}
+
+// 前回のリクエストからintervalが経つまで待つ
+func (p *pacer) wait(ctx context.Context) error {
+	if !p.last.IsZero() {
+		if d := p.interval - time.Since(p.last); d > 0 {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+	p.last = time.Now()
+	return nil
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"baseball_report/internal/source"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// 同じURLの解析失敗を記録する間隔（ページが直るまで毎回HTMLを保存しないため）
+const parseFailureInterval = 30 * time.Minute
+
+// URLごとの解析失敗を記録した時刻（ワーカーから並行に呼ばれる）
+var (
+	failureMu   sync.Mutex
+	lastFailure = map[string]time.Time{}
+)
+
+// 記録する時期か（記録する場合は時刻を更新する）
+func shouldRecordFailure(url string, at time.Time) bool {
+	failureMu.Lock()
+	defer failureMu.Unlock()
+	for u, last := range lastFailure {
+		if at.Sub(last) >= parseFailureInterval {
+			delete(lastFailure, u)
+		}
+	}
+	if _, ok := lastFailure[url]; ok {
+		return false
+	}
+	lastFailure[url] = at
+	return true
+}
+
+// 解析に失敗したページをparse_failuresテーブルに記録する（解析以外のエラーは記録しない）
+func recordParseFailure(db *sql.DB, err error) {
+	var f *source.ParseFailure
+	if !errors.As(err, &f) || !shouldRecordFailure(f.URL, now()) {
+		return
+	}
+	var matchID interface{}
+	if f.MatchID != 0 {
+		matchID = f.MatchID
+	}
+	query := `
+				INSERT INTO parse_failures (source, page, url, match_id, field, error, html)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+				`
+	if _, err := repo.InsertData(db, query, f.Source, f.Page, f.URL, matchID, f.Field, f.Err.Error(), f.HTML); err != nil {
+		log.Println(fmt.Errorf("failed to insert parse failure: %w", err))
+	}
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"baseball_report/internal/models"
+	"sync"
+	"time"
+)
+
+// 試合開始の何分前から取得を始めるか
+const preGameWindow = 15 * time.Minute
+
+// 試合開始前の取得間隔（中止や開始時刻の変更を拾う）
+const preGameInterval = 5 * time.Minute
+
+// 試合中の取得間隔
+const liveInterval = 1 * time.Minute
+
+// 開始からこの時間を過ぎても終了しない試合は取得をやめる（延長・中断を含めた上限）
+const maxGameDuration = 6 * time.Hour
+
+// 現在時刻（テストで差し替える）
+var now = time.Now
+
+// 試合ごとの前回の取得時刻（cron以外からGetScoresを呼んだ場合も同時に実行されうる）
+var (
+	pollMu     sync.Mutex
+	lastPolled = map[int]time.Time{}
+)
+
+// 試合の開始日時を求める
+// dateはYYYY-MM-DD（parseTime有効時はRFC3339）、starttimeはHH:MM:SSまたはHH:MM
+func matchStart(match models.LiveMatch, loc *time.Location) (time.Time, bool) {
+	if len(match.Date) < len("2006-01-02") {
+		return time.Time{}, false
+	}
+	date := match.Date[:len("2006-01-02")]
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, date+" "+match.StartTime, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// 試合の開始時刻から取得間隔を決める
+// 取得対象外（開始前の時間帯、または上限時間を超えた）の場合はfalseを返す
+func pollInterval(match models.LiveMatch, at time.Time) (time.Duration, bool) {
+	start, ok := matchStart(match, at.Location())
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case at.Before(start.Add(-preGameWindow)):
+		return 0, false
+	case at.Before(start):
+		return preGameInterval, true
+	case at.After(start.Add(maxGameDuration)):
+		return 0, false
+	default:
+		return liveInterval, true
+	}
+}
+
+// 取得する時期になった試合を返す
+// 対象外になった試合は前回の取得時刻を破棄する
+func duePolls(matches []models.LiveMatch, at time.Time) []models.LiveMatch {
+	pollMu.Lock()
+	defer pollMu.Unlock()
+	var due []models.LiveMatch
+	active := map[int]bool{}
+	for _, match := range matches {
+		interval, ok := pollInterval(match, at)
+		if !ok {
+			continue
+		}
+		active[match.ID] = true
+		if last, polled := lastPolled[match.ID]; polled && at.Sub(last) < interval {
+			continue
+		}
+		due = append(due, match)
+	}
+	for id := range lastPolled {
+		if !active[id] {
+			delete(lastPolled, id)
+		}
+	}
+	return due
+}
+
+// 取得した試合の取得時刻を記録する
+func markPolled(matches []models.LiveMatch, at time.Time) {
+	pollMu.Lock()
+	defer pollMu.Unlock()
+	for _, match := range matches {
+		lastPolled[match.ID] = at
+	}
+}
+
+// 以降取得しない試合の取得時刻を破棄する
+func forgetPolled(id int) {
+	pollMu.Lock()
+	defer pollMu.Unlock()
+	delete(lastPolled, id)
+}
+
+// 試合が終了（中止）したか
+func isFinished(inning string) bool {
+	return inning == "試合終了" || inning == "試合中止"
+}
@@ -1,12 +1,20 @@
 package scheduler
 
 import (
+	"baseball_report/internal/cache"
 	db "baseball_report/internal/config"
-	"baseball_report/internal/fetcher"
+	"baseball_report/internal/feed"
+	"baseball_report/internal/models"
 	"baseball_report/internal/repository"
+	"baseball_report/internal/source"
 	"baseball_report/utils"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -15,7 +23,26 @@ import (
 // 依存関係を抽象化するためインターフェース化
 var repo repository.Repository = &repository.DefaultRepository{}
 var connect db.DBHandler = &db.DBService{}
-var scraper utils.URLHandler = &utils.URLService{}
+var scraper utils.URLHandler = &utils.URLService{Transport: pageCache}
+
+// 試合情報の取得元（環境変数SCRAPE_SOURCESで選択し、scraperで取得する）
+var sources = func() (source.Source, error) { return source.FromEnv(scraper) }
+
+// 取得したページのキャッシュ（内容が変わっていなければ解析・DB更新を省く）
+var pageCache = cache.Default
+var broker feed.Publisher = feed.Default
+
+// 日程の取得の制限時間（再試行を含む）
+const scheduleTimeout = 2 * time.Minute
+
+// 日付の形式（YYYY-MM-DD）
+const dateLayout = "2006-01-02"
+
+// 先の日程を取得する日数の既定値と上限
+const (
+	defaultPrefetchDays = 7
+	maxPrefetchDays     = 31
+)
 
 // 日次スケジューラをここで設定
 func StartDailyFetch(c *cron.Cron) (cron.EntryID, error) {
@@ -30,6 +57,10 @@ func StartDailyFetch(c *cron.Cron) (cron.EntryID, error) {
 		if err != nil {
 			log.Println("Failed task at:", time.Now(), err)
 		}
+		// 当日の取得に失敗しても先の日程は取得する
+		if err := PrefetchSchedules(); err != nil {
+			log.Println("Failed to prefetch schedules at:", time.Now(), err)
+		}
 		log.Println("Next task GetMatchScheduletoday:", c.Entries())
 	})
 	if err != nil {
@@ -40,60 +71,123 @@ func StartDailyFetch(c *cron.Cron) (cron.EntryID, error) {
 
 // 当日の試合情報を取得しテーブルに登録
 func GetMatchScheduletoday() error {
-	todate := time.Now().Format("2006-01-02")
-	url := "https://baseball.yahoo.co.jp/npb/schedule/?date=" + todate
-
-	res, err := scraper.GetURL(url)
+	src, err := sources()
 	if err != nil {
-		log.Println(fmt.Errorf("failed to get URL: %w", err))
+		log.Println(fmt.Errorf("failed to get source: %w", err))
 		return err
 	}
 
-	doc, err := scraper.GetBody(res)
+	n, err := importSchedule(src, now())
 	if err != nil {
-		log.Println(fmt.Errorf("failed to get body: %w", err))
 		return err
 	}
+	if n == 0 {
+		log.Println("There's no game today", time.Now())
+	}
+	log.Println("Get matches", n, "games")
 
-	matches, err := fetcher.GetMatchSchedule(doc)
+	return nil
+}
+
+// 翌日から先の日程を取得しテーブルに登録（日数は環境変数SCHEDULE_PREFETCH_DAYS）
+// 取得のたびに開始時刻・球場の変更と、日程から消えた・別の日に移動した試合を反映する
+// 失敗した日があっても残りの日は取得する
+func PrefetchSchedules() error {
+	days := prefetchDays()
+	if days == 0 {
+		return nil
+	}
+	src, err := sources()
 	if err != nil {
-		log.Println(fmt.Errorf("failed to get match schedule: %w", err))
+		log.Println(fmt.Errorf("failed to get source: %w", err))
 		return err
 	}
 
-	// 試合がある場合はテーブルに格納
-	if len(matches) != 0 {
-		query_matches := `
-			INSERT INTO matches (date, home, away, stadium, starttime, link, league) 
-			VALUES (?, ?, ?, ?, ?, ?, ?)
-			`
-		query_scores := `
-			INSERT INTO scores (match_id)
-			VALUES (?)
-			`
-		// DB接続
-		db, err := connect.ConnectOnly()
+	today := now()
+	var errs []error
+	for i := 1; i <= days; i++ {
+		date := today.AddDate(0, 0, i)
+		n, err := importSchedule(src, date)
 		if err != nil {
-			log.Println(fmt.Errorf("failed to check to connect database: %w", err))
-			return err
+			errs = append(errs, fmt.Errorf("%s: %w", date.Format(dateLayout), err))
+			continue
 		}
-		for _, match := range matches {
-			// matchesテーブルに追加
-			id, err := repo.InsertData(db, query_matches, match[0], match[1], match[2], match[3], match[5], match[6], match[7])
-			if err != nil {
-				return err
-			}
-			// scoresテーブルに追加
-			_, err = repo.InsertData(db, query_scores, id)
-			if err != nil {
-				return err
+		log.Println("Prefetched schedule:", date.Format(dateLayout), n, "games")
+	}
+	return errors.Join(errs...)
+}
+
+// 先の日程を取得する日数（環境変数SCHEDULE_PREFETCH_DAYS、0〜31。0の場合は取得しない）
+func prefetchDays() int {
+	v := os.Getenv("SCHEDULE_PREFETCH_DAYS")
+	if v == "" {
+		return defaultPrefetchDays
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Printf("invalid SCHEDULE_PREFETCH_DAYS=%q, using %d", v, defaultPrefetchDays)
+		return defaultPrefetchDays
+	}
+	if n > maxPrefetchDays {
+		return maxPrefetchDays
+	}
+	return n
+}
+
+// dateの日程を取得しテーブルに登録し、試合数を返す
+func importSchedule(src source.Source, date time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), scheduleTimeout)
+	defer cancel()
+
+	schedule, err := src.Schedule(ctx, date)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to get schedule: %w", err))
+		if errors.As(err, new(*source.ParseFailure)) {
+			if db, cerr := connect.ConnectOnly(); cerr == nil {
+				recordParseFailure(db, err)
+				db.Close()
 			}
-			log.Println("Get Match Today:", id, match[1], "vs", match[2])
 		}
-	} else {
-		log.Println("There's no game today", time.Now())
+		return 0, err
 	}
-	log.Println("Get matches", len(matches), "games")
 
-	return err
+	// DB接続（試合がない日も、その日の登録済みの試合を日程から消すため接続する）
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		log.Println(fmt.Errorf("failed to check to connect database: %w", err))
+		return 0, err
+	}
+	defer db.Close()
+
+	ids, err := saveSchedule(db, date, schedule)
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	for i, id := range ids {
+		log.Println("Get Match:", date.Format(dateLayout), id, schedule[i].Home, "vs", schedule[i].Away)
+	}
+	return len(schedule), nil
+}
+
+// dateの日程をテーブルに格納し（登録済みの試合は開始時刻・球場を更新）、その日の日程から消えた試合をremovedにする
+// 試合がない日（scheduleが空）はその日の試合をすべてremovedにする。別の日に移動した試合は移動先の日の登録でrescheduledになる
+// 取得・解析に失敗した日は呼ばない
+func saveSchedule(db *sql.DB, date time.Time, schedule []models.Match) ([]int, error) {
+	var ids []int
+	if len(schedule) != 0 {
+		var err error
+		ids, err = repo.SaveSchedule(db, schedule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save schedule: %w", err)
+		}
+	}
+	removed, err := repo.RemoveMissingMatches(db, date.Format(dateLayout), ids)
+	if err != nil {
+		return nil, err
+	}
+	if removed != 0 {
+		log.Println("Removed from schedule:", date.Format(dateLayout), removed, "games")
+	}
+	return ids, nil
 }
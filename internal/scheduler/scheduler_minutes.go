@@ -1,36 +1,73 @@
 package scheduler
 
 import (
-	"baseball_report/internal/fetcher"
+	"baseball_report/internal/feed"
+	"baseball_report/internal/models"
+	"baseball_report/internal/source"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
+// 試合進捗の取得周期（各試合を取得するかはpollIntervalで判断）
+const minutesSpec = "@every 30s"
+
+// 試合進捗を同時に取得する数の既定値と上限
+const defaultScoreWorkers = 4
+const maxScoreWorkers = 16
+
+// 1回の取得全体の制限時間（再試行を含む）
+const scoreTimeout = 2 * time.Minute
+
+// 試合進捗の取得をスケジューラに登録
+// 前回の取得が終わっていない場合は次の周期をスキップする
 func StartMinutesFetch(c *cron.Cron) (cron.EntryID, error) {
-	id, err := c.AddFunc("* 12 * * *", func() {
+	job := cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
 		defer func() {
 			if r := recover(); r != nil {
 				log.Println("panic recovered in cron task:", r)
 			}
 		}()
-		log.Println("Executing task at:", time.Now())
 		err := GetScores()
 		if err != nil {
 			log.Println("Failed task at:", time.Now(), err)
 		}
-		log.Println("Next task GetScoreSchedule:", c.Entries())
-	})
+	}))
+	id, err := c.AddJob(minutesSpec, job)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
+// 同時に取得する数（環境変数SCORE_WORKERS、1〜16）
+func scoreWorkers() int {
+	v := os.Getenv("SCORE_WORKERS")
+	if v == "" {
+		return defaultScoreWorkers
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		log.Printf("invalid SCORE_WORKERS=%q, using %d", v, defaultScoreWorkers)
+		return defaultScoreWorkers
+	}
+	if n > maxScoreWorkers {
+		return maxScoreWorkers
+	}
+	return n
+}
+
 // 試合進捗を取得しテーブル更新
+// 取得する時期になった試合をワーカーで並行に取得し、失敗した試合があっても残りの試合は更新する
 func GetScores() error {
 	// DB接続
 	db, err := connect.ConnectOnly()
@@ -38,46 +75,162 @@ func GetScores() error {
 		log.Println(fmt.Errorf("failed to check to connect database: %w", err))
 		return err
 	}
+	defer db.Close()
 
-	//開始中の試合情報を取得
-	matches, err := repo.GetMatchScoreLive(db)
+	//終了していない試合情報を取得し、取得する時期になった試合に絞る
+	live, err := repo.GetMatchScoreLive(db)
 	if err != nil {
 		log.Println(fmt.Errorf("failed to get to match: %w", err))
 		return err
 	}
+	at := now()
+	matches := duePolls(live, at)
+	if len(matches) == 0 {
+		return nil
+	}
 	log.Println("Get Matching :", len(matches))
-	for _, match := range matches {
-		//試合速報からデータを取得
-		res, err := scraper.GetURL(match["link"].(string))
-		if err != nil {
-			log.Println(fmt.Errorf("failed to get URL: %w", err))
-			return err
+	markPolled(matches, at)
+
+	ctx, cancel := context.WithTimeout(context.Background(), scoreTimeout)
+	defer cancel()
+
+	workers := min(scoreWorkers(), len(matches))
+	finished := make([]bool, len(matches))
+	errs := make([]error, len(matches))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				finished[i], errs[i] = updateScore(ctx, db, matches[i])
+			}
+		}()
+	}
+	for i := range matches {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	//終了した試合は以降取得しない
+	for i, match := range matches {
+		if finished[i] {
+			forgetPolled(match.ID)
+			log.Println("Game finished:", match.ID)
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		doc, err := scraper.GetBody(res)
+// 1試合の試合進捗を取得してテーブルを更新し、試合が終了したかを返す
+func updateScore(ctx context.Context, db *sql.DB, match models.LiveMatch) (finished bool, err error) {
+	// 取得後の処理に失敗した場合は、次回ページが変わっていなくても処理し直す
+	defer func() {
 		if err != nil {
-			log.Println(fmt.Errorf("failed to get body: %w", err))
-			return err
+			pageCache.Forget(match.Link)
+		}
+	}()
+	// ワーカー内のpanicはcronで回収されないためここでエラーにする
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic recovered in match %d: %v", match.ID, r)
+			log.Println(err)
 		}
+	}()
 
-		score, err := fetcher.GetMatchScore(doc)
-		if err != nil {
-			log.Println(fmt.Errorf("failed to get match score: %w", err))
-			return err
+	//試合速報からデータを取得
+	src, err := sources()
+	if err != nil {
+		log.Println(fmt.Errorf("failed to get source: %w", err))
+		return false, fmt.Errorf("match %d: %w", match.ID, err)
+	}
+	live, err := src.LiveScore(ctx, match)
+	// 前回の取得からページが変わっていなければ更新しない
+	if errors.Is(err, source.ErrNotModified) {
+		return false, nil
+	}
+	if err != nil {
+		log.Println(fmt.Errorf("failed to get live score: %w", err))
+		recordParseFailure(db, err)
+		return false, fmt.Errorf("match %d: %w", match.ID, err)
+	}
+	score := live.Score
+	query := `
+				UPDATE scores SET home_score = ?, away_score = ?, batter = ?, inning = ?, result = ?,
+				pitcher = ?, balls = ?, strikes = ?, outs = ?, on_first = ?, on_second = ?, on_third = ? WHERE match_id = ?
+				`
+	idStr := strconv.Itoa(match.ID)
+	onFirst, onSecond, onThird := baseColumns(score.Bases)
+	id, err := repo.UpdateData(db, query, score.HomeScore, score.AwayScore, score.Batter, score.Inning, score.Result,
+		score.Pitcher, score.Balls, score.Strikes, score.Outs, onFirst, onSecond, onThird, idStr)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to upfate : %w", err))
+		return false, fmt.Errorf("match %d: %w", match.ID, err)
+	}
+	log.Println("Updated Score:", id, score.HomeScore, "-", score.AwayScore, score.Batter, score.Inning, score.Result)
+
+	// ラインスコアが表示されていれば更新
+	if len(live.LineScore) == 2 {
+		if err := saveLineScore(db, match.ID, live.LineScore); err != nil {
+			log.Println(fmt.Errorf("failed to save line score: %w", err))
+			return false, fmt.Errorf("match %d: %w", match.ID, err)
 		}
-		query := `
-				UPDATE scores SET home_score = ?, away_score = ?, batter = ?, inning = ?, result = ? WHERE match_id = ?
+	}
+
+	// 進捗に変化があれば購読者に通知
+	prev := feed.ScoreState{
+		Inning:    match.Inning,
+		HomeScore: match.HomeScore,
+		AwayScore: match.AwayScore,
+		Batter:    match.Batter,
+		Result:    match.Result,
+	}
+	next := feed.ScoreState{Inning: score.Inning, HomeScore: score.HomeScore, AwayScore: score.AwayScore, Batter: score.Batter, Result: score.Result}
+	if changed := feed.Diff(prev, next); len(changed) != 0 {
+		// 変化の履歴をscore_eventsテーブルに追加
+		query_event := `
+				INSERT INTO score_events (match_id, inning, home_score, away_score, batter, result)
+				VALUES (?, ?, ?, ?, ?, ?)
 				`
-		idInt := match["id"].(int)
-		idStr := strconv.Itoa(idInt)
-		id, err := repo.UpdateData(db, query, score[0][1], score[0][2], score[0][3], score[0][0], score[0][4], idStr)
+		_, err = repo.InsertData(db, query_event, match.ID, next.Inning, next.HomeScore, next.AwayScore, next.Batter, next.Result)
 		if err != nil {
-			log.Println(fmt.Errorf("failed to upfate : %w", err))
-			return err
+			log.Println(fmt.Errorf("failed to insert score event: %w", err))
 		}
-		log.Println("Updated Score:", id, score[0][1], "-", score[0][2], score[0][3], score[0][0], score[0][4])
-		log.Println("Sleeping 10 second")
-		time.Sleep(10 * time.Second)
+
+		broker.Publish(feed.ScoreEvent{
+			MatchID:  match.ID,
+			League:   match.League,
+			Home:     match.Home,
+			Away:     match.Away,
+			Score:    next,
+			Previous: prev,
+			Changed:  changed,
+		})
+	}
+	return isFinished(next.Inning), nil
+}
+
+// ラインスコア（先攻・後攻の順）をline_scoresテーブルに登録・更新
+func saveLineScore(db *sql.DB, matchID int, lines []models.LineScore) error {
+	query := `
+				INSERT INTO line_scores (match_id, side, innings, runs, hits, errors)
+				VALUES (?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE innings = VALUES(innings), runs = VALUES(runs), hits = VALUES(hits), errors = VALUES(errors)
+				`
+	away, home := lines[0], lines[1]
+	_, err := repo.InsertData(db, query,
+		matchID, "away", strings.Join(away.Innings, ","), away.Runs, away.Hits, away.Errors,
+		matchID, "home", strings.Join(home.Innings, ","), home.Runs, home.Hits, home.Errors,
+	)
+	return err
+}
+
+// 走者の有無をon_first・on_second・on_thirdの値にする（走者の情報がない場合はNULL）
+func baseColumns(bases *models.Bases) (first, second, third interface{}) {
+	if bases == nil {
+		return nil, nil, nil
 	}
-	return nil
+	return bases.First, bases.Second, bases.Third
 }
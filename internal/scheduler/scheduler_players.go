@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"baseball_report/internal/source"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 選手成績の取得（前日のナイターが反映された後の早朝に1日1回）
+const playersSpec = "30 5 * * *"
+
+// 選手成績の取得の制限時間（全チーム分）
+const playersTimeout = 10 * time.Minute
+
+// 選手成績の日次スケジューラを設定
+func StartPlayersFetch(c *cron.Cron) (cron.EntryID, error) {
+	job := cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("panic recovered in cron task:", r)
+			}
+		}()
+		err := GetPlayerStats()
+		if err != nil {
+			log.Println("Failed task at:", time.Now(), err)
+		}
+	}))
+	id, err := c.AddJob(playersSpec, job)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// 各チームの選手と今シーズンの成績を取得しテーブルに登録
+// 失敗したチームがあっても残りのチームは取得する
+func GetPlayerStats() error {
+	src, err := sources()
+	if err != nil {
+		log.Println(fmt.Errorf("failed to get source: %w", err))
+		return err
+	}
+	// Chainは選手成績に対応する取得元がない場合にチームが空になる
+	ps, ok := src.(source.PlayerSource)
+	if !ok || len(ps.Teams()) == 0 {
+		err := fmt.Errorf("source %s does not support players", src.Name())
+		log.Println(err)
+		return err
+	}
+
+	db, err := connect.ConnectOnly()
+	if err != nil {
+		log.Println(fmt.Errorf("failed to check to connect database: %w", err))
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), playersTimeout)
+	defer cancel()
+
+	season := now().Year()
+	var errs []error
+	for _, team := range ps.Teams() {
+		players, err := ps.Players(ctx, team)
+		if err != nil {
+			log.Println(fmt.Errorf("failed to get players of %s: %w", team, err))
+			recordParseFailure(db, err)
+			errs = append(errs, fmt.Errorf("%s: %w", team, err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if err := repo.SavePlayers(db, season, players); err != nil {
+			log.Println(fmt.Errorf("failed to save players of %s: %w", team, err))
+			errs = append(errs, fmt.Errorf("%s: %w", team, err))
+			continue
+		}
+		log.Println("Get players:", team, len(players), "players")
+	}
+	return errors.Join(errs...)
+}
@@ -1,13 +1,20 @@
 package scheduler
 
 import (
+	"baseball_report/internal/cache"
+	"baseball_report/internal/feed"
+	"baseball_report/internal/metrics"
+	"baseball_report/internal/models"
+	"baseball_report/internal/source"
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"io"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -41,6 +48,10 @@ func (m *MockURLHandler) GetURL(url string) (*http.Response, error) {
 	return nil, nil
 }
 
+func (m *MockURLHandler) GetURLContext(ctx context.Context, url string) (*http.Response, error) {
+	return m.GetURL(url)
+}
+
 func (m *MockURLHandler) GetBody(res *http.Response) (*goquery.Document, error) {
 	if m.MockGetBody != nil {
 		return m.MockGetBody(res)
@@ -72,13 +83,30 @@ func TestStartDailyFetch_Success(t *testing.T) {
 func TestGetMatchScheduletoday_Success(t *testing.T) {
 	todate := time.Now().Format("2006/01/02")
 	query_match := `
-	INSERT INTO matches (date, home, away, stadium, starttime, link, league) 
-	VALUES (?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO matches (date, home, away, stadium, starttime, link, league, home_team_id, away_team_id, stadium_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		id = LAST_INSERT_ID(id),
+		original_date = IF(date <> VALUES(date), COALESCE(original_date, date), original_date),
+		status = IF(date <> VALUES(date), 'rescheduled', IF(status = 'removed', 'scheduled', status)),
+		date = VALUES(date),
+		stadium = VALUES(stadium),
+		starttime = VALUES(starttime),
+		league = VALUES(league),
+		home_team_id = VALUES(home_team_id),
+		away_team_id = VALUES(away_team_id),
+		stadium_id = VALUES(stadium_id)
 	`
 	query_score := `
 	INSERT INTO scores (match_id)
 	VALUES (?)
+	ON DUPLICATE KEY UPDATE match_id = match_id
 	`
+	query_remove := `
+	UPDATE matches SET status = 'removed'
+	WHERE date = ? AND status <> 'removed' AND id NOT IN (?, ?)
+	`
+	today := time.Now().Format("2006-01-02")
 	// ログ出力のキャプチャ
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
@@ -123,8 +151,9 @@ func TestGetMatchScheduletoday_Success(t *testing.T) {
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+				mock.ExpectBegin()
 				mock.ExpectExec(query_match).
-					WithArgs(todate, "Lions", "Giants", "beruna", "12:00", "test1/score", "Interleague").
+					WithArgs(todate, "西武", "巨人", "beruna", "12:00", "test1/score", "Interleague", "lions", "giants", nil).
 					WillReturnResult(sqlmock.NewResult(1, 1)) // match_id=1
 
 				mock.ExpectExec(query_score).
@@ -132,12 +161,16 @@ func TestGetMatchScheduletoday_Success(t *testing.T) {
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectExec(query_match).
-					WithArgs(todate, "Fighters", "Hawks", "escon", "18:00", "test2/score", "Interleague").
+					WithArgs(todate, "日本ハム", "ソフトバンク", "escon", "18:00", "test2/score", "Interleague", "fighters", "hawks", nil).
 					WillReturnResult(sqlmock.NewResult(2, 1)) // match_id=2
 
 				mock.ExpectExec(query_score).
 					WithArgs(2).
 					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec(query_remove).
+					WithArgs(today, 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
 
 				return db, nil
 			},
@@ -149,6 +182,72 @@ func TestGetMatchScheduletoday_Success(t *testing.T) {
 		assert.Contains(t, buf.String(), "Get matches")
 
 	})
+	//再実行（登録済みの試合は既存のidで更新され、重複して登録されない）
+	t.Run("Rerun updates existing games", func(t *testing.T) {
+		buf.Reset()
+
+		var mock sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				var db *sql.DB
+				db, mock, _ = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+				mock.ExpectBegin()
+				// 既存行の更新はaffected rows=2、LAST_INSERT_ID(id)で既存のidが返る
+				mock.ExpectExec(query_match).
+					WithArgs(todate, "西武", "巨人", "beruna", "12:00", "test1/score", "Interleague", "lions", "giants", nil).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				// scoresは登録済みのため変更なし
+				mock.ExpectExec(query_score).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectExec(query_match).
+					WithArgs(todate, "日本ハム", "ソフトバンク", "escon", "18:00", "test2/score", "Interleague", "fighters", "hawks", nil).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				mock.ExpectExec(query_score).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(2, 0))
+				mock.ExpectCommit()
+				// 日程から消えた試合をremovedにする
+				mock.ExpectExec(query_remove).
+					WithArgs(today, 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db, nil
+			},
+		}
+
+		err := GetMatchScheduletoday()
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "Get Match: "+today+" 1 西武 vs 巨人")
+		assert.Contains(t, buf.String(), "Get Match: "+today+" 2 日本ハム vs ソフトバンク")
+		assert.Contains(t, buf.String(), "Removed from schedule: "+today+" 1 games")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	//scoresの登録に失敗した場合はmatchesもロールバック
+	t.Run("Rollback when score insert fails", func(t *testing.T) {
+		buf.Reset()
+
+		var mock sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				var db *sql.DB
+				db, mock, _ = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+				mock.ExpectBegin()
+				mock.ExpectExec(query_match).
+					WithArgs(todate, "西武", "巨人", "beruna", "12:00", "test1/score", "Interleague", "lions", "giants", nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(query_score).
+					WithArgs(1).
+					WillReturnError(errors.New("DB insert failed"))
+				mock.ExpectRollback()
+				return db, nil
+			},
+		}
+
+		err := GetMatchScheduletoday()
+		assert.Error(t, err)
+		assert.Contains(t, buf.String(), "failed to save schedule: failed to insert score: DB insert failed")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 	t.Run("Get Nogame", func(t *testing.T) {
 		//スクレイピング処理をモック化
 		scraper = &MockURLHandler{
@@ -166,12 +265,25 @@ func TestGetMatchScheduletoday_Success(t *testing.T) {
 				return doc, nil
 			},
 		}
+		//試合がない日はその日の試合をすべて日程から消す
+		var mock sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				var db *sql.DB
+				db, mock, _ = sqlmock.New()
+				mock.ExpectExec(`UPDATE matches SET status = 'removed'\s+WHERE date = \? AND status <> 'removed'$`).
+					WithArgs(today).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return db, nil
+			},
+		}
 		//関数実行
 		err := GetMatchScheduletoday()
 		assert.NoError(t, err)
 
 		//ログ結果が期待値と一致している
 		assert.Contains(t, buf.String(), "There's no game today")
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 }
@@ -282,7 +394,15 @@ func TestGetscore(t *testing.T) {
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
 
+	// 12:00開始の試合の試合中の時刻に固定
+	today := time.Now()
+	now = func() time.Time {
+		return time.Date(today.Year(), today.Month(), today.Day(), 12, 30, 0, 0, time.Local)
+	}
+	defer func() { now = time.Now }()
+
 	t.Run("Success Update Score", func(t *testing.T) {
+		lastPolled = map[int]time.Time{}
 
 		//スクレイピング処理をモック化
 		scraper = &MockURLHandler{
@@ -325,6 +445,23 @@ func TestGetscore(t *testing.T) {
 					<div id="result">
 						左2塁打
 					</div>
+
+					<!-- 投手・カウント・走者 -->
+					<table id="pit">
+						<tr>
+							<td><a href="/player2">佐藤</a></td>
+						</tr>
+					</table>
+					<div id="sbo">
+						<p class="b"><b>●●</b></p>
+						<p class="s"><b>●</b></p>
+						<p class="o"><b></b></p>
+					</div>
+					<div id="base">
+						<div id="base1"><a href="/player3">田中</a></div>
+						<div id="base2"></div>
+						<div id="base3"></div>
+					</div>
 				</body>
 				`))
 				return doc, nil
@@ -335,30 +472,41 @@ func TestGetscore(t *testing.T) {
 		todate := time.Now().Format("2006-01-02")
 
 		query_match := `
-					SELECT 
-						m.id, 
-						m.date, 
-						m.home, 
-						m.away, 
-						m.league, 
-						m.stadium, 
+					SELECT
+						m.id,
+						m.date,
+						m.home,
+						m.away,
+						m.league,
+						m.stadium,
 						m.starttime,
 						m.link,
-						s.inning
-					FROM 
+						s.inning,
+						s.home_score,
+						s.away_score,
+						s.batter,
+						s.result
+					FROM
 						matches m
-					LEFT JOIN 
+					LEFT JOIN
 						scores s ON m.id = s.match_id
-					WHERE 
-						m.date = CURDATE() AND
-						m.starttime <= CURTIME() AND
-						(s.inning <> '試合終了' AND s.inning <> '試合中止')
+					WHERE
+						m.date BETWEEN CURDATE() - INTERVAL 1 DAY AND CURDATE() AND
+						m.status <> 'removed' AND
+						(s.inning IS NULL OR s.inning NOT IN ('試合終了', '試合中止'))
+					ORDER BY m.date, m.starttime, m.id
 					`
 
 		query_score := `
-	UPDATE scores SET home_score = ?, away_score = ?, batter = ?, inning = ?, result = ? WHERE match_id = ?
+	UPDATE scores SET home_score = ?, away_score = ?, batter = ?, inning = ?, result = ?,
+	pitcher = ?, balls = ?, strikes = ?, outs = ?, on_first = ?, on_second = ?, on_third = ? WHERE match_id = ?
 `
 
+		query_event := `
+				INSERT INTO score_events (match_id, inning, home_score, away_score, batter, result)
+				VALUES (?, ?, ?, ?, ?, ?)
+				`
+
 		connect = &MockDBHandler{
 			MockConnectOnly: func() (*sql.DB, error) {
 				db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
@@ -366,28 +514,49 @@ func TestGetscore(t *testing.T) {
 				// SELECT クエリのモック
 				mock.ExpectQuery(query_match).
 					WillReturnRows(sqlmock.NewRows([]string{
-						"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning",
+						"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning", "home_score", "away_score", "batter", "result",
 					}).AddRow(
-						1, todate, "Lions", "Giants", "Interleague", "beruna", "12:00:00", "test1/score", "試合前",
+						1, todate, "Lions", "Giants", "Interleague", "beruna", "12:00:00", "test1/score", "試合前", "0", "0", "", "",
 					))
 
 				// UPDATE クエリのモック
 				mock.ExpectExec(query_score).
-					WithArgs("2", "1", "山田", "2回裏", "左2塁打", "1"). // match["id"] は int → 文字列に変換されている
+					WithArgs("2", "1", "山田", "2回裏", "左2塁打", "佐藤", 2, 1, 0, true, false, false, "1"). // match.ID は int → 文字列に変換されている
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				// 変化があったため履歴を追加
+				mock.ExpectExec(query_event).
+					WithArgs(1, "2回裏", "2", "1", "山田", "左2塁打").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				return db, nil
 			},
 		}
 
+		//変化の通知を購読
+		b := feed.NewBroker(10)
+		broker = b
+		sub := b.Subscribe(10)
+
 		//対象の関数を実行
 		err := GetScores()
 		assert.NoError(t, err)
 
 		assert.Contains(t, buf.String(), "Updated Score: 1 2 - 1")
+		assert.NotContains(t, buf.String(), "failed to insert score event")
+
+		//変化した項目が通知されている
+		if assert.Len(t, sub.C, 1) {
+			ev := <-sub.C
+			assert.Equal(t, 1, ev.MatchID)
+			assert.Equal(t, "Interleague", ev.League)
+			assert.Equal(t, feed.ScoreState{Inning: "2回裏", HomeScore: "2", AwayScore: "1", Batter: "山田", Result: "左2塁打"}, ev.Score)
+			assert.Equal(t, []string{"inning", "home_score", "away_score", "batter", "result"}, ev.Changed)
+		}
 	})
 
 	t.Run("Error_GetURL", func(t *testing.T) {
+		lastPolled = map[int]time.Time{}
 		scraper = &MockURLHandler{
 			MockGetURL: func(url string) (*http.Response, error) {
 				return nil, errors.New("failed to fetch URL")
@@ -401,6 +570,7 @@ func TestGetscore(t *testing.T) {
 	})
 
 	t.Run("Error_GetBody", func(t *testing.T) {
+		lastPolled = map[int]time.Time{}
 		scraper = &MockURLHandler{
 			MockGetURL: func(url string) (*http.Response, error) {
 				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("mock html"))}, nil
@@ -418,6 +588,7 @@ func TestGetscore(t *testing.T) {
 	})
 
 	t.Run("Error_DBConnect", func(t *testing.T) {
+		lastPolled = map[int]time.Time{}
 		scraper = &MockURLHandler{
 			MockGetURL: func(url string) (*http.Response, error) {
 				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("mock html"))}, nil
@@ -452,6 +623,232 @@ func TestGetscore(t *testing.T) {
 	})
 }
 
+// 複数試合の並行取得：1試合が失敗しても残りの試合は更新される
+// 解析結果が不正な試合はparse_failuresに記録される
+func TestGetScores_Concurrent(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	today := time.Now()
+	now = func() time.Time {
+		return time.Date(today.Year(), today.Month(), today.Day(), 18, 30, 0, 0, time.Local)
+	}
+	defer func() { now = time.Now }()
+	broker = feed.NewBroker(10)
+
+	scoreHTML := `
+		<div class="live"><em>5回表</em></div>
+		<table>
+			<tr><td class="nm">A</td><td>3</td></tr>
+			<tr><td class="nm">B</td><td>4</td></tr>
+		</table>
+		<table id="batt"><tr><td><a href="/p">佐藤</a></td></tr></table>
+		<div id="result">見逃し三振</div>`
+
+	scraper = &MockURLHandler{
+		MockGetURL: func(url string) (*http.Response, error) {
+			if url == "fail/score" {
+				return nil, errors.New("connection reset")
+			}
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(url))}, nil
+		},
+		MockGetBody: func(res *http.Response) (*goquery.Document, error) {
+			body, _ := io.ReadAll(res.Body)
+			//スコア表のないページ
+			if string(body) == "panic/score" {
+				return goquery.NewDocumentFromReader(strings.NewReader("<div></div>"))
+			}
+			return goquery.NewDocumentFromReader(strings.NewReader(scoreHTML))
+		},
+	}
+
+	var mock sqlmock.Sqlmock
+	connect = &MockDBHandler{
+		MockConnectOnly: func() (*sql.DB, error) {
+			var db *sql.DB
+			db, mock, _ = sqlmock.New()
+			mock.MatchExpectationsInOrder(false)
+			mock.ExpectQuery(`FROM\s+matches m`).WillReturnRows(sqlmock.NewRows([]string{
+				"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning", "home_score", "away_score", "batter", "result",
+			}).
+				AddRow(1, today.Format("2006-01-02"), "A", "B", "セ・リーグ", "X", "18:00:00", "fail/score", "4回裏", "3", "4", "", "").
+				AddRow(2, today.Format("2006-01-02"), "C", "D", "セ・リーグ", "Y", "18:00:00", "ok/score", "4回裏", "4", "3", "", "").
+				AddRow(3, today.Format("2006-01-02"), "E", "F", "パ・リーグ", "Z", "18:00:00", "panic/score", "4回裏", "0", "0", "", ""))
+			mock.ExpectExec(`UPDATE scores`).WithArgs("4", "3", "佐藤", "5回表", "見逃し三振", nil, nil, nil, nil, nil, nil, nil, "2").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(`INSERT INTO score_events`).WithArgs(2, "5回表", "4", "3", "佐藤", "見逃し三振").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(`INSERT INTO parse_failures`).WithArgs("yahoo", "score", "panic/score", 3, "score.cell", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			return db, nil
+		},
+	}
+	lastPolled = map[int]time.Time{}
+	lastFailure = map[string]time.Time{}
+	failures := metrics.ParseFailures.Value("yahoo", "score", "score.cell")
+
+	err := GetScores()
+
+	//失敗した試合のエラーはまとめて返る
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "match 1: failed to get URL: connection reset")
+	assert.Contains(t, err.Error(), "match 3: failed to get match score: failed to parse score page panic/score")
+	assert.Equal(t, failures+1, metrics.ParseFailures.Value("yahoo", "score", "score.cell"))
+	//成功した試合は更新されている
+	assert.Contains(t, buf.String(), "Updated Score: 1 4 - 3")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// ページが前回から変わっていない場合は解析・DB更新をしない
+func TestGetScores_Unchanged(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	today := time.Now()
+	now = func() time.Time {
+		return time.Date(today.Year(), today.Month(), today.Day(), 18, 30, 0, 0, time.Local)
+	}
+	defer func() { now = time.Now }()
+
+	scraper = &MockURLHandler{
+		MockGetURL: func(url string) (*http.Response, error) {
+			header := http.Header{}
+			header.Set(cache.StatusHeader, cache.StatusUnchanged)
+			return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader("cached"))}, nil
+		},
+		MockGetBody: func(res *http.Response) (*goquery.Document, error) {
+			return nil, errors.New("unchanged page parsed")
+		},
+	}
+
+	var mock sqlmock.Sqlmock
+	connect = &MockDBHandler{
+		MockConnectOnly: func() (*sql.DB, error) {
+			var db *sql.DB
+			db, mock, _ = sqlmock.New()
+			mock.ExpectQuery(`FROM\s+matches m`).WillReturnRows(sqlmock.NewRows([]string{
+				"id", "date", "home", "away", "league", "stadium", "starttime", "link", "inning", "home_score", "away_score", "batter", "result",
+			}).AddRow(1, today.Format("2006-01-02"), "A", "B", "セ・リーグ", "X", "18:00:00", "same/score", "4回裏", "3", "4", "", ""))
+			//UPDATEは実行されない
+			return db, nil
+		},
+	}
+	lastPolled = map[int]time.Time{}
+
+	err := GetScores()
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "unchanged page parsed")
+	assert.NotContains(t, buf.String(), "Updated Score")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScoreWorkers(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", defaultScoreWorkers},
+		{"2", 2},
+		{"100", maxScoreWorkers},
+		{"0", defaultScoreWorkers},
+		{"abc", defaultScoreWorkers},
+	}
+	for _, tt := range tests {
+		t.Setenv("SCORE_WORKERS", tt.env)
+		assert.Equal(t, tt.want, scoreWorkers(), tt.env)
+	}
+}
+
+func TestStartMinutesFetch_Success(t *testing.T) {
+	c := cron.New(cron.WithLocation(time.Local))
+
+	id, err := StartMinutesFetch(c)
+	assert.NoError(t, err)
+
+	c.Start()
+	defer c.Stop()
+
+	//30秒ごとに実行される
+	entry := c.Entry(id)
+	assert.False(t, entry.Next.IsZero())
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), entry.Next, 2*time.Second)
+}
+
+// 試合開始時刻からの取得間隔
+func TestPollInterval(t *testing.T) {
+	day := time.Date(2025, 4, 6, 0, 0, 0, 0, time.Local)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	match := models.LiveMatch{Match: models.Match{ID: 1, Date: "2025-04-06", StartTime: "18:00:00"}}
+
+	tests := []struct {
+		name     string
+		match    models.LiveMatch
+		at       time.Time
+		interval time.Duration
+		active   bool
+	}{
+		{"before window", match, at(17, 44), 0, false},
+		{"pre-game", match, at(17, 50), preGameInterval, true},
+		{"in play", match, at(18, 0), liveInterval, true},
+		{"in play late", match, at(21, 30), liveInterval, true},
+		{"night game after midnight", models.LiveMatch{Match: models.Match{Date: "2025-04-06", StartTime: "19:00:00"}}, at(24, 10), liveInterval, true},
+		{"over max duration", match, at(24, 1), 0, false},
+		{"parseTime date and HH:MM", models.LiveMatch{Match: models.Match{Date: "2025-04-06T00:00:00+09:00", StartTime: "13:00"}}, at(13, 5), liveInterval, true},
+		{"invalid starttime", models.LiveMatch{Match: models.Match{Date: "2025-04-06", StartTime: "未定"}}, at(13, 0), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, active := pollInterval(tt.match, tt.at)
+			assert.Equal(t, tt.active, active)
+			assert.Equal(t, tt.interval, interval)
+		})
+	}
+}
+
+// 前回の取得時刻から取得対象を判定
+func TestDuePolls(t *testing.T) {
+	at := time.Date(2025, 4, 6, 18, 30, 0, 0, time.Local)
+	live := models.LiveMatch{Match: models.Match{ID: 1, Date: "2025-04-06", StartTime: "18:00:00"}}
+	pre := models.LiveMatch{Match: models.Match{ID: 2, Date: "2025-04-06", StartTime: "18:40:00"}}
+	later := models.LiveMatch{Match: models.Match{ID: 3, Date: "2025-04-06", StartTime: "19:00:00"}}
+
+	lastPolled = map[int]time.Time{
+		1: at.Add(-30 * time.Second), // 試合中、1分経過していない
+		2: at.Add(-5 * time.Minute),  // 試合前、5分経過
+		9: at.Add(-time.Minute),      // 対象外になった試合
+	}
+	due := duePolls([]models.LiveMatch{live, pre, later}, at)
+
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, 2, due[0].ID)
+	}
+	//対象外の試合の取得時刻は破棄される
+	_, ok := lastPolled[9]
+	assert.False(t, ok)
+
+	//1分後には試合中の試合も対象になる（試合前の試合は取得済み）
+	lastPolled[2] = at
+	due = duePolls([]models.LiveMatch{live, pre, later}, at.Add(time.Minute))
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, 1, due[0].ID)
+	}
+
+	//同時に実行しても取得時刻の記録が壊れない（-raceで確認）
+	t.Run("Concurrent polls", func(t *testing.T) {
+		lastPolled = map[int]time.Time{}
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				matches := duePolls([]models.LiveMatch{live, pre}, at)
+				markPolled(matches, at)
+				forgetPolled(live.ID)
+			}()
+		}
+		wg.Wait()
+		_, ok := lastPolled[live.ID]
+		assert.False(t, ok)
+	})
+}
+
 func TestGetscore2(t *testing.T) {
 	GetScores()
 }
@@ -459,3 +856,386 @@ func TestGetscore2(t *testing.T) {
 func TestGetmatches(t *testing.T) {
 	GetMatchScheduletoday()
 }
+
+// 同じURLの解析失敗は間隔を空けて記録する
+func TestShouldRecordFailure(t *testing.T) {
+	lastFailure = map[string]time.Time{}
+	at := time.Date(2025, 4, 6, 18, 30, 0, 0, time.Local)
+
+	assert.True(t, shouldRecordFailure("a/score", at))
+	assert.False(t, shouldRecordFailure("a/score", at.Add(time.Minute)))
+	assert.True(t, shouldRecordFailure("b/score", at.Add(time.Minute)))
+	assert.True(t, shouldRecordFailure("a/score", at.Add(parseFailureInterval)))
+}
+
+// ラインスコアは先攻・後攻の2行をまとめて登録・更新する
+func TestSaveLineScore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO line_scores`).
+		WithArgs(1, "away", "0,3,0", "3", "5", "0", 1, "home", "4,0,X", "4", "6", "1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = saveLineScore(db, 1, []models.LineScore{
+		{Team: "阪神", Innings: []string{"0", "3", "0"}, Runs: "3", Hits: "5", Errors: "0"},
+		{Team: "巨人", Innings: []string{"4", "0", "X"}, Runs: "4", Hits: "6", Errors: "1"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// 選手成績に対応したテスト用の取得元
+type mockPlayerSource struct {
+	players map[string][]models.Player
+	errs    map[string]error
+}
+
+func (m *mockPlayerSource) Name() string { return "mock" }
+
+func (m *mockPlayerSource) Schedule(ctx context.Context, date time.Time) ([]models.Match, error) {
+	return nil, nil
+}
+
+func (m *mockPlayerSource) LiveScore(ctx context.Context, match models.LiveMatch) (source.Live, error) {
+	return source.Live{}, nil
+}
+
+func (m *mockPlayerSource) Teams() []string { return []string{"阪神", "巨人"} }
+
+func (m *mockPlayerSource) Players(ctx context.Context, team string) ([]models.Player, error) {
+	return m.players[team], m.errs[team]
+}
+
+func TestStartPlayersFetch_Success(t *testing.T) {
+	c := cron.New(cron.WithLocation(time.Local))
+
+	id, err := StartPlayersFetch(c)
+	assert.NoError(t, err)
+
+	c.Start()
+	defer c.Stop()
+
+	assert.False(t, c.Entry(id).Next.IsZero())
+}
+
+// 失敗したチームがあっても残りのチームの選手成績は登録する
+func TestGetPlayerStats(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	now = func() time.Time { return time.Date(2025, 6, 1, 5, 30, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+	origSources, origConnect := sources, connect
+	defer func() { sources, connect = origSources, origConnect }()
+
+	sources = func() (source.Source, error) {
+		return &mockPlayerSource{
+			players: map[string][]models.Player{"阪神": {{ID: 1000001, Name: "投手 一郎", Team: "阪神", Number: "11", Position: models.PositionPitcher}}},
+			errs:    map[string]error{"巨人": errors.New("timeout")},
+		}, nil
+	}
+	db, mock, _ := sqlmock.New()
+	connect = &MockDBHandler{MockConnectOnly: func() (*sql.DB, error) { return db, nil }}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO players`).WithArgs(1000001, "投手 一郎", "阪神", "11", models.PositionPitcher).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO player_stats`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := GetPlayerStats()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "巨人: timeout")
+	assert.Contains(t, buf.String(), "Get players: 阪神 1 players")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// 選手成績に対応していない取得元
+func TestGetPlayerStats_Unsupported(t *testing.T) {
+	origSources := sources
+	defer func() { sources = origSources }()
+	sources = func() (source.Source, error) { return source.Chain{}, nil }
+
+	err := GetPlayerStats()
+	assert.Error(t, err)
+}
+
+// 過去の日程の取り込み：完了済みの日は飛ばし、取り込んだ日を記録する
+func TestBackfill(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+	minBackfillInterval = time.Millisecond
+	defer func() { minBackfillInterval = time.Second }()
+	broker = feed.NewBroker(10)
+
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 4, 2, 0, 0, 0, 0, time.Local)
+	scheduleHTML := `
+		<div class="bb-score">
+			<h2 class="bb-score__title">セ・リーグ</h2>
+			<div class="bb-score__item">
+				<div class="bb-score__homeLogo">巨人</div>
+				<div class="bb-score__awayLogo">阪神</div>
+				<div class="bb-score__venue">東京ドーム</div>
+				<div class="bb-score__link">試合終了</div>
+				<div class="bb-score__status">18:00</div>
+				<a class="bb-score__content" href="test1/index"></a>
+			</div>
+		</div>`
+	scoreHTML := `
+		<div class="live"><em>試合終了</em></div>
+		<table>
+			<tr><td class="nm">阪神</td><td>2</td></tr>
+			<tr><td class="nm">巨人</td><td>3</td></tr>
+		</table>`
+
+	var requested []string
+	newScraper := func(scoreErr error) *MockURLHandler {
+		requested = nil
+		return &MockURLHandler{
+			MockGetURL: func(url string) (*http.Response, error) {
+				requested = append(requested, url)
+				if url == "test1/score" && scoreErr != nil {
+					return nil, scoreErr
+				}
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(url))}, nil
+			},
+			MockGetBody: func(res *http.Response) (*goquery.Document, error) {
+				body, _ := io.ReadAll(res.Body)
+				if string(body) == "test1/score" {
+					return goquery.NewDocumentFromReader(strings.NewReader(scoreHTML))
+				}
+				return goquery.NewDocumentFromReader(strings.NewReader(scheduleHTML))
+			},
+		}
+	}
+
+	t.Run("Skip checkpointed dates", func(t *testing.T) {
+		scraper = newScraper(nil)
+		var mock sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				var db *sql.DB
+				db, mock, _ = sqlmock.New()
+				mock.ExpectQuery(`FROM backfill_checkpoints`).WithArgs("2024-04-01", "2024-04-02").
+					WillReturnRows(sqlmock.NewRows([]string{"date"}).AddRow("2024-04-01"))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO matches`).
+					WithArgs("2024/04/02", "巨人", "阪神", "東京ドーム", "18:00", "test1/score", "セ・リーグ", "giants", "tigers", "tokyo-dome").
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectExec(`INSERT INTO scores`).WithArgs(5).WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec(`UPDATE matches SET status = 'removed'`).WithArgs("2024-04-02", 5).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`UPDATE scores`).WithArgs("3", "2", "", "試合終了", "", nil, nil, nil, nil, nil, nil, nil, "5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO score_events`).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO backfill_checkpoints`).WithArgs("2024-04-02", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				return db, nil
+			},
+		}
+
+		err := Backfill(context.Background(), from, to, 0)
+		assert.NoError(t, err)
+		//2024-04-01は取得しない
+		assert.Equal(t, []string{"https://baseball.yahoo.co.jp/npb/schedule/?date=2024-04-02", "test1/score"}, requested)
+		assert.Contains(t, buf.String(), "Backfilled: 2024-04-02 1 games")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//試合進捗の取得に失敗した日は記録せず、次回やり直す
+	t.Run("Do not checkpoint failed dates", func(t *testing.T) {
+		scraper = newScraper(errors.New("connection reset"))
+		var mock sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				var db *sql.DB
+				db, mock, _ = sqlmock.New()
+				mock.ExpectQuery(`FROM backfill_checkpoints`).WillReturnRows(sqlmock.NewRows([]string{"date"}).AddRow("2024-04-01"))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO matches`).WillReturnResult(sqlmock.NewResult(5, 2))
+				mock.ExpectExec(`INSERT INTO scores`).WillReturnResult(sqlmock.NewResult(5, 0))
+				mock.ExpectCommit()
+				mock.ExpectExec(`UPDATE matches SET status = 'removed'`).WillReturnResult(sqlmock.NewResult(0, 0))
+				return db, nil
+			},
+		}
+
+		err := Backfill(context.Background(), from, to, 0)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "2024-04-02: match 5: failed to get URL: connection reset")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		assert.ErrorContains(t, Backfill(context.Background(), to, from, 0), "is after")
+		assert.ErrorContains(t, Backfill(context.Background(), from, time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local), 0), "must be before today")
+	})
+}
+
+// 前回のリクエストからintervalが経つまで待つ
+func TestPacer(t *testing.T) {
+	p := &pacer{interval: 50 * time.Millisecond}
+	start := time.Now()
+	assert.NoError(t, p.wait(context.Background()))
+	assert.NoError(t, p.wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	//中断された場合は待たずに終わる
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, p.wait(ctx), context.Canceled)
+}
+
+// 翌日から先の日程を取得し、失敗した日があっても残りの日は取得する
+func TestPrefetchSchedules(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	now = func() time.Time { return time.Date(2025, 6, 1, 0, 1, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+
+	scheduleHTML := `
+		<div class="bb-score">
+			<h2 class="bb-score__title">セ・リーグ</h2>
+			<div class="bb-score__item">
+				<div class="bb-score__homeLogo">巨人</div>
+				<div class="bb-score__awayLogo">阪神</div>
+				<div class="bb-score__venue">東京ドーム</div>
+				<div class="bb-score__link">試合前</div>
+				<div class="bb-score__status">18:00</div>
+				<a class="bb-score__content" href="test1/index"></a>
+			</div>
+		</div>`
+	var requested []string
+	scraper = &MockURLHandler{
+		MockGetURL: func(url string) (*http.Response, error) {
+			requested = append(requested, url)
+			if strings.HasSuffix(url, "2025-06-03") {
+				return nil, errors.New("connection reset")
+			}
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(url))}, nil
+		},
+		MockGetBody: func(res *http.Response) (*goquery.Document, error) {
+			return goquery.NewDocumentFromReader(strings.NewReader(scheduleHTML))
+		},
+	}
+
+	t.Run("Prefetch next days", func(t *testing.T) {
+		t.Setenv("SCHEDULE_PREFETCH_DAYS", "3")
+		requested = nil
+		var mocks []sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				db, mock, _ := sqlmock.New()
+				date := []string{"2025-06-02", "2025-06-04"}[len(mocks)]
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO matches`).
+					WithArgs(strings.ReplaceAll(date, "-", "/"), "巨人", "阪神", "東京ドーム", "18:00", "test1/score", "セ・リーグ", "giants", "tigers", "tokyo-dome").
+					WillReturnResult(sqlmock.NewResult(5, 2))
+				mock.ExpectExec(`INSERT INTO scores`).WithArgs(5).WillReturnResult(sqlmock.NewResult(5, 0))
+				mock.ExpectCommit()
+				mock.ExpectExec(`UPDATE matches SET status = 'removed'`).WithArgs(date, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+				mocks = append(mocks, mock)
+				return db, nil
+			},
+		}
+
+		err := PrefetchSchedules()
+		assert.ErrorContains(t, err, "2025-06-03: ")
+		assert.Equal(t, []string{
+			"https://baseball.yahoo.co.jp/npb/schedule/?date=2025-06-02",
+			"https://baseball.yahoo.co.jp/npb/schedule/?date=2025-06-03",
+			"https://baseball.yahoo.co.jp/npb/schedule/?date=2025-06-04",
+		}, requested)
+		assert.Contains(t, buf.String(), "Prefetched schedule: 2025-06-02 1 games")
+		assert.Contains(t, buf.String(), "Prefetched schedule: 2025-06-04 1 games")
+		if assert.Len(t, mocks, 2) {
+			for _, mock := range mocks {
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		}
+	})
+
+	//唯一の試合が日程から消えた日は、その試合をremovedにする
+	t.Run("Only game removed", func(t *testing.T) {
+		t.Setenv("SCHEDULE_PREFETCH_DAYS", "1")
+		buf.Reset()
+		scraper = &MockURLHandler{
+			MockGetURL: func(url string) (*http.Response, error) {
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(url))}, nil
+			},
+			MockGetBody: func(res *http.Response) (*goquery.Document, error) {
+				return goquery.NewDocumentFromReader(strings.NewReader(`<div class="bb-score"><div class="bb-noData">試合はありません。</div></div>`))
+			},
+		}
+		var mock sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				var db *sql.DB
+				db, mock, _ = sqlmock.New()
+				mock.ExpectExec(`UPDATE matches SET status = 'removed'\s+WHERE date = \? AND status <> 'removed'$`).
+					WithArgs("2025-06-02").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db, nil
+			},
+		}
+
+		assert.NoError(t, PrefetchSchedules())
+		assert.Contains(t, buf.String(), "Removed from schedule: 2025-06-02 1 games")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//ページの構成が変わった場合は試合がない日として扱わない
+	t.Run("Unparsable page", func(t *testing.T) {
+		t.Setenv("SCHEDULE_PREFETCH_DAYS", "1")
+		scraper = &MockURLHandler{
+			MockGetURL: func(url string) (*http.Response, error) {
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(url))}, nil
+			},
+			MockGetBody: func(res *http.Response) (*goquery.Document, error) {
+				return goquery.NewDocumentFromReader(strings.NewReader(`<div class="schedule"></div>`))
+			},
+		}
+		var mock sqlmock.Sqlmock
+		connect = &MockDBHandler{
+			MockConnectOnly: func() (*sql.DB, error) {
+				var db *sql.DB
+				db, mock, _ = sqlmock.New()
+				//解析の失敗のみ記録し、日程は変更しない
+				mock.ExpectExec(`INSERT INTO parse_failures`).WillReturnResult(sqlmock.NewResult(1, 1))
+				return db, nil
+			},
+		}
+
+		assert.ErrorContains(t, PrefetchSchedules(), "2025-06-02: ")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	//0日の場合は取得しない
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv("SCHEDULE_PREFETCH_DAYS", "0")
+		requested = nil
+		assert.NoError(t, PrefetchSchedules())
+		assert.Empty(t, requested)
+	})
+}
+
+func TestPrefetchDays(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", defaultPrefetchDays},
+		{"0", 0},
+		{"14", 14},
+		{"100", maxPrefetchDays},
+		{"-1", defaultPrefetchDays},
+		{"abc", defaultPrefetchDays},
+	}
+	for _, tt := range tests {
+		t.Setenv("SCHEDULE_PREFETCH_DAYS", tt.env)
+		assert.Equal(t, tt.want, prefetchDays(), tt.env)
+	}
+}
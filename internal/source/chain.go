@@ -0,0 +1,99 @@
+package source
+
+import (
+	"baseball_report/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Chain 先頭の取得元から順に試し、失敗した場合は次の取得元を使う
+type Chain []Source
+
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, src := range c {
+		names[i] = src.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (c Chain) Schedule(ctx context.Context, date time.Time) ([]models.Match, error) {
+	var errs []error
+	for _, src := range c {
+		matches, err := src.Schedule(ctx, date)
+		if err == nil {
+			return matches, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		log.Println(fmt.Errorf("source %s failed to get schedule: %w", src.Name(), err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// 変化なし（ErrNotModified）は取得に成功したものとして次の取得元は使わない
+func (c Chain) LiveScore(ctx context.Context, match models.LiveMatch) (Live, error) {
+	var errs []error
+	for _, src := range c {
+		live, err := src.LiveScore(ctx, match)
+		if err == nil || errors.Is(err, ErrNotModified) {
+			return live, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		log.Println(fmt.Errorf("source %s failed to get live score of match %d: %w", src.Name(), match.ID, err))
+	}
+	return Live{}, errors.Join(errs...)
+}
+
+// 選手成績に対応する取得元のチーム（重複は除く）
+func (c Chain) Teams() []string {
+	var teams []string
+	seen := map[string]bool{}
+	for _, src := range c {
+		ps, ok := src.(PlayerSource)
+		if !ok {
+			continue
+		}
+		for _, team := range ps.Teams() {
+			if !seen[team] {
+				seen[team] = true
+				teams = append(teams, team)
+			}
+		}
+	}
+	return teams
+}
+
+// 選手成績に対応する取得元を順に試す
+func (c Chain) Players(ctx context.Context, team string) ([]models.Player, error) {
+	var errs []error
+	for _, src := range c {
+		ps, ok := src.(PlayerSource)
+		if !ok || !slices.Contains(ps.Teams(), team) {
+			continue
+		}
+		players, err := ps.Players(ctx, team)
+		if err == nil {
+			return players, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		log.Println(fmt.Errorf("source %s failed to get players of %s: %w", src.Name(), team, err))
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no source supports players of %s", team)
+	}
+	return nil, errors.Join(errs...)
+}
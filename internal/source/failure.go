@@ -0,0 +1,66 @@
+package source
+
+import (
+	"baseball_report/internal/fetcher"
+	"baseball_report/internal/metrics"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 保存するHTMLの上限
+const maxSnapshotSize = 1 << 20
+
+// ParseFailure 取得したページの解析結果が不正（ページの構造が変わった可能性がある）
+// 調査用に取得したHTMLを保持する
+type ParseFailure struct {
+	Source  string
+	Page    string // schedule|score|players
+	URL     string
+	MatchID int // 日程ページの場合は0
+	Field   string
+	HTML    string
+	Err     error
+}
+
+func (f *ParseFailure) Error() string {
+	return fmt.Sprintf("failed to parse %s page %s: %v", f.Page, f.URL, f.Err)
+}
+
+func (f *ParseFailure) Unwrap() error {
+	return f.Err
+}
+
+// 解析のエラーがページの構造によるものならParseFailureにして件数を数える
+func parseFailure(source, page, url string, matchID int, doc *goquery.Document, err error) error {
+	var perr *fetcher.ParseError
+	if !errors.As(err, &perr) {
+		return err
+	}
+	metrics.ParseFailures.Inc(source, page, perr.Field)
+
+	html, _ := doc.Html()
+	return &ParseFailure{
+		Source:  source,
+		Page:    page,
+		URL:     url,
+		MatchID: matchID,
+		Field:   perr.Field,
+		HTML:    truncateHTML(html, maxSnapshotSize),
+		Err:     err,
+	}
+}
+
+// htmlをsizeバイト以内に切り詰める
+// utf8mb4の列に保存できるよう、マルチバイト文字の途中では切らない
+func truncateHTML(html string, size int) string {
+	if len(html) <= size {
+		return html
+	}
+	for size > 0 && !utf8.RuneStart(html[size]) {
+		size--
+	}
+	return html[:size]
+}
@@ -0,0 +1,88 @@
+package source
+
+import (
+	"baseball_report/utils"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 既定の取得元
+const defaultSources = "yahoo"
+
+// Factory スクレイパーから取得元を作る
+type Factory func(scraper utils.URLHandler) Source
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Factory{}
+)
+
+// 取得元を名前で登録する（各実装のinitから呼ぶ）
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("source: duplicate registration " + name)
+	}
+	registry[name] = factory
+}
+
+// 登録済みの取得元の名前
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return namesLocked()
+}
+
+// 名前の順に優先する取得元を作る（2つ以上の場合は失敗時に次の取得元を使うChain）
+func New(names []string, scraper utils.URLHandler) (Source, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no source specified")
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	chain := make(Chain, 0, len(names))
+	for _, name := range names {
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown source %q (available: %s)", name, strings.Join(namesLocked(), ", "))
+		}
+		chain = append(chain, factory(scraper))
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
+
+func namesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 環境変数SCRAPE_SOURCES（カンマ区切り、先頭が優先）から取得元を作る
+// 未設定の場合はyahoo（現在登録されている取得元はyahooのみ）
+func FromEnv(scraper utils.URLHandler) (Source, error) {
+	v := os.Getenv("SCRAPE_SOURCES")
+	if strings.TrimSpace(v) == "" {
+		v = defaultSources
+	}
+	var names []string
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	src, err := New(names, scraper)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCRAPE_SOURCES=%q: %w", v, err)
+	}
+	return src, nil
+}
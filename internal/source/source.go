@@ -0,0 +1,37 @@
+package source
+
+import (
+	"baseball_report/internal/models"
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotModified 前回の取得からページの内容が変わっていない
+var ErrNotModified = errors.New("not modified")
+
+// Source 試合日程・試合進捗の取得元（提供元ごとのURLとHTMLの解析を隠す）
+type Source interface {
+	// 取得元の名前（SCRAPE_SOURCESで指定する値）
+	Name() string
+	// dateの試合日程を返す（試合がない場合は空）
+	Schedule(ctx context.Context, date time.Time) ([]models.Match, error)
+	// 試合の進捗を返す（前回の取得から変わっていなければErrNotModified）
+	// 提供元によってはLinkを使わず日付と対戦カードで試合を探す
+	LiveScore(ctx context.Context, match models.LiveMatch) (Live, error)
+}
+
+// PlayerSource 選手成績の取得元（対応していない取得元もある）
+type PlayerSource interface {
+	// 選手成績を取得できるチーム
+	Teams() []string
+	// teamの所属選手と今シーズンの成績を返す
+	Players(ctx context.Context, team string) ([]models.Player, error)
+}
+
+// Live 試合速報ページから取得した試合進捗
+type Live struct {
+	Score models.Score
+	// 先攻・後攻の順（表示されていない場合は空）
+	LineScore []models.LineScore
+}
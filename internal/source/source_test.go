@@ -0,0 +1,159 @@
+package source
+
+import (
+	"baseball_report/internal/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// テスト用の取得元
+type mockSource struct {
+	name     string
+	schedule []models.Match
+	live     Live
+	err      error
+	calls    int
+}
+
+func (m *mockSource) Name() string { return m.name }
+
+func (m *mockSource) Schedule(ctx context.Context, date time.Time) ([]models.Match, error) {
+	m.calls++
+	return m.schedule, m.err
+}
+
+func (m *mockSource) LiveScore(ctx context.Context, match models.LiveMatch) (Live, error) {
+	m.calls++
+	return m.live, m.err
+}
+
+// 選手成績に対応したテスト用の取得元
+type mockPlayerSource struct {
+	mockSource
+	teams   []string
+	players []models.Player
+}
+
+func (m *mockPlayerSource) Teams() []string { return m.teams }
+
+func (m *mockPlayerSource) Players(ctx context.Context, team string) ([]models.Player, error) {
+	m.calls++
+	return m.players, m.err
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Run("Default is yahoo", func(t *testing.T) {
+		t.Setenv("SCRAPE_SOURCES", "")
+		src, err := FromEnv(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "yahoo", src.Name())
+	})
+
+	t.Run("Primary and fallback", func(t *testing.T) {
+		t.Setenv("SCRAPE_SOURCES", " yahoo , yahoo ")
+		src, err := FromEnv(nil)
+		assert.NoError(t, err)
+		assert.IsType(t, Chain{}, src)
+		assert.Equal(t, "yahoo,yahoo", src.Name())
+	})
+
+	t.Run("Unknown source", func(t *testing.T) {
+		t.Setenv("SCRAPE_SOURCES", "yahoo,unknown")
+		_, err := FromEnv(nil)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown source "unknown"`)
+	})
+}
+
+func TestChain_Schedule(t *testing.T) {
+	t.Run("Fallback on error", func(t *testing.T) {
+		primary := &mockSource{name: "a", err: errors.New("timeout")}
+		fallback := &mockSource{name: "b", schedule: []models.Match{{Home: "Lions", Away: "Giants"}}}
+
+		matches, err := Chain{primary, fallback}.Schedule(context.Background(), time.Now())
+		assert.NoError(t, err)
+		assert.Len(t, matches, 1)
+		assert.Equal(t, 1, fallback.calls)
+	})
+
+	t.Run("All sources failed", func(t *testing.T) {
+		primary := &mockSource{name: "a", err: errors.New("timeout")}
+		fallback := &mockSource{name: "b", err: errors.New("not found")}
+
+		_, err := Chain{primary, fallback}.Schedule(context.Background(), time.Now())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "a: timeout")
+		assert.Contains(t, err.Error(), "b: not found")
+	})
+}
+
+func TestChain_LiveScore(t *testing.T) {
+	t.Run("Primary succeeded", func(t *testing.T) {
+		primary := &mockSource{name: "a", live: Live{Score: models.Score{Inning: "5回表"}}}
+		fallback := &mockSource{name: "b"}
+
+		live, err := Chain{primary, fallback}.LiveScore(context.Background(), models.LiveMatch{})
+		assert.NoError(t, err)
+		assert.Equal(t, "5回表", live.Score.Inning)
+		assert.Equal(t, 0, fallback.calls)
+	})
+
+	//変化なしは失敗として扱わない
+	t.Run("Not modified", func(t *testing.T) {
+		primary := &mockSource{name: "a", err: ErrNotModified}
+		fallback := &mockSource{name: "b"}
+
+		_, err := Chain{primary, fallback}.LiveScore(context.Background(), models.LiveMatch{})
+		assert.ErrorIs(t, err, ErrNotModified)
+		assert.Equal(t, 0, fallback.calls)
+	})
+
+	t.Run("Fallback on error", func(t *testing.T) {
+		primary := &mockSource{name: "a", err: errors.New("layout changed")}
+		fallback := &mockSource{name: "b", live: Live{Score: models.Score{Inning: "6回裏"}}}
+
+		live, err := Chain{primary, fallback}.LiveScore(context.Background(), models.LiveMatch{})
+		assert.NoError(t, err)
+		assert.Equal(t, "6回裏", live.Score.Inning)
+	})
+}
+
+func TestChain_Players(t *testing.T) {
+	t.Run("Fallback on error", func(t *testing.T) {
+		primary := &mockPlayerSource{mockSource: mockSource{name: "a", err: errors.New("timeout")}, teams: []string{"阪神"}}
+		fallback := &mockPlayerSource{mockSource: mockSource{name: "b"}, teams: []string{"阪神", "巨人"}, players: []models.Player{{ID: 1}}}
+		chain := Chain{&mockSource{name: "c"}, primary, fallback}
+
+		assert.Equal(t, []string{"阪神", "巨人"}, chain.Teams())
+		players, err := chain.Players(context.Background(), "阪神")
+		assert.NoError(t, err)
+		assert.Len(t, players, 1)
+		assert.Equal(t, 1, primary.calls)
+	})
+
+	//チームに対応していない取得元は使わない
+	t.Run("Unsupported team", func(t *testing.T) {
+		primary := &mockPlayerSource{mockSource: mockSource{name: "a"}, teams: []string{"阪神"}}
+
+		_, err := Chain{&mockSource{name: "c"}, primary}.Players(context.Background(), "巨人")
+		assert.Error(t, err)
+		assert.Equal(t, 0, primary.calls)
+	})
+}
+
+// マルチバイト文字の途中では切らない
+func TestTruncateHTML(t *testing.T) {
+	html := "<p>試合終了</p>"
+	assert.Equal(t, html, truncateHTML(html, len(html)))
+	//「試」の2バイト目で切る場合は「試」の前まで
+	assert.Equal(t, "<p>", truncateHTML(html, 4))
+	assert.Equal(t, "<p>試", truncateHTML(html, 6))
+	for size := 0; size <= len(html); size++ {
+		assert.True(t, utf8.ValidString(truncateHTML(html, size)), size)
+	}
+}
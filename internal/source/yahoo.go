@@ -0,0 +1,179 @@
+package source
+
+import (
+	"baseball_report/internal/cache"
+	"baseball_report/internal/fetcher"
+	"baseball_report/internal/models"
+	"baseball_report/internal/teams"
+	"baseball_report/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Yahoo!プロ野球の日程ページ
+const yahooScheduleURL = "https://baseball.yahoo.co.jp/npb/schedule/?date="
+
+// Yahoo!プロ野球の選手一覧ページ（チームIDと、kindはp:投手・b:野手）
+const yahooMemberListURL = "https://baseball.yahoo.co.jp/npb/teams/%d/memberlist?kind=%s"
+
+// Yahoo!プロ野球のチームID（チーム名は日程ページの表記）
+var yahooTeams = []struct {
+	name string
+	id   int
+}{
+	{"巨人", 1}, {"ヤクルト", 2}, {"DeNA", 3}, {"中日", 4}, {"阪神", 5}, {"広島", 6},
+	{"西武", 7}, {"日本ハム", 8}, {"ロッテ", 9}, {"オリックス", 11}, {"ソフトバンク", 12}, {"楽天", 376},
+}
+
+func init() {
+	Register("yahoo", func(scraper utils.URLHandler) Source {
+		return &Yahoo{Scraper: scraper}
+	})
+}
+
+// Yahoo Yahoo!プロ野球のページから取得する
+// 試合進捗はScheduleで取得した試合速報ページ（Link）から取得する
+type Yahoo struct {
+	Scraper utils.URLHandler
+}
+
+func (y *Yahoo) Name() string {
+	return "yahoo"
+}
+
+func (y *Yahoo) Schedule(ctx context.Context, date time.Time) ([]models.Match, error) {
+	url := yahooScheduleURL + date.Format("2006-01-02")
+	res, err := y.Scraper.GetURLContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL: %w", err)
+	}
+	// 日程は毎回登録し直すため、変化なしでもキャッシュのボディを解析する
+	doc, err := y.Scraper.GetBody(res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get body: %w", err)
+	}
+	rows, err := fetcher.GetMatchSchedule(doc, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match schedule: %w", parseFailure(y.Name(), "schedule", url, 0, doc, err))
+	}
+
+	matches := make([]models.Match, 0, len(rows))
+	for _, row := range rows {
+		matches = append(matches, teams.Resolve(models.Match{
+			Date:      row[0],
+			Home:      row[1],
+			Away:      row[2],
+			Stadium:   row[3],
+			StartTime: row[5],
+			Link:      row[6],
+			League:    row[7],
+		}))
+	}
+	return matches, nil
+}
+
+func (y *Yahoo) LiveScore(ctx context.Context, match models.LiveMatch) (Live, error) {
+	if match.Link == "" {
+		return Live{}, fmt.Errorf("match %d has no link", match.ID)
+	}
+	res, err := y.Scraper.GetURLContext(ctx, match.Link)
+	if err != nil {
+		return Live{}, fmt.Errorf("failed to get URL: %w", err)
+	}
+	// 前回の取得からページが変わっていなければ解析しない
+	if cache.Unchanged(res) {
+		res.Body.Close()
+		return Live{}, ErrNotModified
+	}
+	doc, err := y.Scraper.GetBody(res)
+	if err != nil {
+		return Live{}, fmt.Errorf("failed to get body: %w", err)
+	}
+	rows, err := fetcher.GetMatchScore(doc)
+	if err != nil {
+		return Live{}, fmt.Errorf("failed to get match score: %w", parseFailure(y.Name(), "score", match.Link, match.ID, doc, err))
+	}
+	lines, err := fetcher.GetLineScore(doc)
+	if err != nil {
+		return Live{}, fmt.Errorf("failed to get line score: %w", parseFailure(y.Name(), "score", match.Link, match.ID, doc, err))
+	}
+	row := rows[0]
+	situation, err := fetcher.GetSituation(doc, row[0])
+	if err != nil {
+		return Live{}, fmt.Errorf("failed to get situation: %w", parseFailure(y.Name(), "score", match.Link, match.ID, doc, err))
+	}
+	return Live{
+		Score: models.Score{
+			MatchID:   match.ID,
+			Inning:    row[0],
+			HomeScore: row[1],
+			AwayScore: row[2],
+			Batter:    row[3],
+			Result:    row[4],
+			Situation: situation,
+		},
+		LineScore: lines,
+	}, nil
+}
+
+func (y *Yahoo) Teams() []string {
+	teams := make([]string, len(yahooTeams))
+	for i, t := range yahooTeams {
+		teams[i] = t.name
+	}
+	return teams
+}
+
+// 投手・野手の選手一覧ページから取得する
+// 両方に載っている選手は1人にまとめる（区分は先に取得した投手）
+func (y *Yahoo) Players(ctx context.Context, team string) ([]models.Player, error) {
+	teamID := 0
+	for _, t := range yahooTeams {
+		if t.name == team {
+			teamID = t.id
+			break
+		}
+	}
+	if teamID == 0 {
+		return nil, fmt.Errorf("unknown team %q", team)
+	}
+
+	var players []models.Player
+	index := map[int]int{}
+	for _, page := range []struct {
+		kind  string
+		parse func(*goquery.Document) ([]models.Player, error)
+	}{{"p", fetcher.GetPitchers}, {"b", fetcher.GetBatters}} {
+		url := fmt.Sprintf(yahooMemberListURL, teamID, page.kind)
+		res, err := y.Scraper.GetURLContext(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get URL: %w", err)
+		}
+		doc, err := y.Scraper.GetBody(res)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get body: %w", err)
+		}
+		list, err := page.parse(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get players: %w", parseFailure(y.Name(), "players", url, 0, doc, err))
+		}
+		for _, p := range list {
+			p.Team = team
+			if i, ok := index[p.ID]; ok {
+				if p.Batting != nil {
+					players[i].Batting = p.Batting
+				}
+				if p.Pitching != nil {
+					players[i].Pitching = p.Pitching
+				}
+				continue
+			}
+			index[p.ID] = len(players)
+			players = append(players, p)
+		}
+	}
+	return players, nil
+}
@@ -0,0 +1,223 @@
+package source
+
+import (
+	"baseball_report/internal/cache"
+	"baseball_report/internal/metrics"
+	"baseball_report/internal/models"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+// スクレイピング処理のモック（レスポンスのボディをHTMLとして返す）
+type mockScraper struct {
+	pages  map[string]string
+	header http.Header
+	urls   []string
+}
+
+func (m *mockScraper) GetURL(url string) (*http.Response, error) {
+	return m.GetURLContext(context.Background(), url)
+}
+
+func (m *mockScraper) GetURLContext(ctx context.Context, url string) (*http.Response, error) {
+	m.urls = append(m.urls, url)
+	page, ok := m.pages[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	header := m.header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader(page))}, nil
+}
+
+func (m *mockScraper) GetBody(res *http.Response) (*goquery.Document, error) {
+	defer res.Body.Close()
+	return goquery.NewDocumentFromReader(res.Body)
+}
+
+func TestYahoo_Schedule(t *testing.T) {
+	date := time.Date(2025, 4, 6, 0, 0, 0, 0, time.Local)
+	scraper := &mockScraper{pages: map[string]string{
+		yahooScheduleURL + "2025-04-06": `
+			<div class="bb-score">
+				<h2 class="bb-score__title">セ・リーグ</h2>
+				<div class="bb-score__item">
+					<div class="bb-score__homeLogo">巨人</div>
+					<div class="bb-score__awayLogo">阪神</div>
+					<div class="bb-score__venue">東京ドーム</div>
+					<div class="bb-score__link">試合前</div>
+					<div class="bb-score__status">18:00</div>
+					<a class="bb-score__content" href="https://baseball.yahoo.co.jp/npb/game/1/index"></a>
+				</div>
+			</div>`,
+	}}
+
+	matches, err := (&Yahoo{Scraper: scraper}).Schedule(context.Background(), date)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Match{{
+		Date:       "2025/04/06",
+		Home:       "巨人",
+		Away:       "阪神",
+		League:     "セ・リーグ",
+		Stadium:    "東京ドーム",
+		StartTime:  "18:00",
+		Link:       "https://baseball.yahoo.co.jp/npb/game/1/score",
+		HomeTeamID: "giants",
+		AwayTeamID: "tigers",
+		StadiumID:  "tokyo-dome",
+	}}, matches)
+}
+
+func TestYahoo_LiveScore(t *testing.T) {
+	match := models.LiveMatch{Match: models.Match{ID: 1, Link: "https://baseball.yahoo.co.jp/npb/game/1/score"}}
+	page := `
+		<div class="live"><em>5回表</em></div>
+		<table>
+			<tr><td class="nm">阪神</td><td>3</td></tr>
+			<tr><td class="nm">巨人</td><td>4</td></tr>
+		</table>
+		<table id="batt"><tr><td><a href="/p">佐藤</a></td></tr></table>
+		<div id="result">見逃し三振</div>`
+	lineScore := `
+		<table id="ing_brd">
+			<thead><tr><th>チーム</th><th>1</th><th>2</th><th>計</th><th>安</th><th>失</th></tr></thead>
+			<tbody>
+				<tr>
+					<td class="bb-gameScoreTable__team">阪神</td>
+					<td class="bb-gameScoreTable__score">0</td><td class="bb-gameScoreTable__score">3</td>
+					<td class="bb-gameScoreTable__total">3</td>
+					<td class="bb-gameScoreTable__data--hits">5</td>
+					<td class="bb-gameScoreTable__data--loss">0</td>
+				</tr>
+				<tr>
+					<td class="bb-gameScoreTable__team">巨人</td>
+					<td class="bb-gameScoreTable__score">4</td><td class="bb-gameScoreTable__score"></td>
+					<td class="bb-gameScoreTable__total">4</td>
+					<td class="bb-gameScoreTable__data--hits">6</td>
+					<td class="bb-gameScoreTable__data--loss">1</td>
+				</tr>
+			</tbody>
+		</table>`
+
+	t.Run("Get score", func(t *testing.T) {
+		scraper := &mockScraper{pages: map[string]string{match.Link: page + lineScore}}
+
+		live, err := (&Yahoo{Scraper: scraper}).LiveScore(context.Background(), match)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Score{MatchID: 1, HomeScore: "4", AwayScore: "3", Batter: "佐藤", Inning: "5回表", Result: "見逃し三振"}, live.Score)
+		assert.Equal(t, []models.LineScore{
+			{Team: "阪神", Innings: []string{"0", "3"}, Runs: "3", Hits: "5", Errors: "0"},
+			{Team: "巨人", Innings: []string{"4", ""}, Runs: "4", Hits: "6", Errors: "1"},
+		}, live.LineScore)
+	})
+
+	//試合前などラインスコアがないページ
+	t.Run("Get score without line score", func(t *testing.T) {
+		scraper := &mockScraper{pages: map[string]string{match.Link: page}}
+
+		live, err := (&Yahoo{Scraper: scraper}).LiveScore(context.Background(), match)
+		assert.NoError(t, err)
+		assert.Equal(t, "5回表", live.Score.Inning)
+		assert.Empty(t, live.LineScore)
+	})
+
+	//キャッシュで変化なしと判定されたページは解析しない
+	t.Run("Not modified", func(t *testing.T) {
+		scraper := &mockScraper{
+			pages:  map[string]string{match.Link: "<div></div>"},
+			header: http.Header{cache.StatusHeader: []string{cache.StatusUnchanged}},
+		}
+
+		_, err := (&Yahoo{Scraper: scraper}).LiveScore(context.Background(), match)
+		assert.ErrorIs(t, err, ErrNotModified)
+	})
+
+	//解析結果が不正な場合はHTMLを保持したParseFailureを返し、件数を数える
+	t.Run("Parse failure", func(t *testing.T) {
+		scraper := &mockScraper{pages: map[string]string{match.Link: `<div class="live"><em>5回表</em></div>`}}
+		count := metrics.ParseFailures.Value("yahoo", "score", "score.cell")
+
+		_, err := (&Yahoo{Scraper: scraper}).LiveScore(context.Background(), match)
+		var failure *ParseFailure
+		if assert.ErrorAs(t, err, &failure) {
+			assert.Equal(t, "score", failure.Page)
+			assert.Equal(t, 1, failure.MatchID)
+			assert.Equal(t, "score.cell", failure.Field)
+			assert.Contains(t, failure.HTML, "5回表")
+		}
+		assert.Equal(t, count+1, metrics.ParseFailures.Value("yahoo", "score", "score.cell"))
+	})
+
+	t.Run("Error_GetURL", func(t *testing.T) {
+		scraper := &mockScraper{pages: map[string]string{}}
+
+		_, err := (&Yahoo{Scraper: scraper}).LiveScore(context.Background(), match)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get URL: not found")
+	})
+}
+
+func TestYahoo_Players(t *testing.T) {
+	pitchers := `
+		<table class="bb-playerTable">
+			<thead><tr><th>背番号</th><th>選手名</th><th>防御率</th><th>登板</th><th>勝利</th><th>敗戦</th><th>セーブ</th><th>ホールド</th><th>投球回</th><th>奪三振</th><th>与四球</th><th>自責点</th></tr></thead>
+			<tbody>
+				<tr><td>11</td><td><a href="/npb/player/1000001/top">投手 一郎</a></td><td>2.50</td><td>20</td><td>8</td><td>5</td><td>0</td><td>0</td><td>108</td><td>100</td><td>30</td><td>30</td></tr>
+				<tr><td>17</td><td><a href="/npb/player/1000002/top">二刀 流</a></td><td>3.00</td><td>10</td><td>4</td><td>2</td><td>0</td><td>0</td><td>60</td><td>70</td><td>20</td><td>20</td></tr>
+			</tbody>
+		</table>`
+	batters := `
+		<table class="bb-playerTable">
+			<thead><tr><th>背番号</th><th>選手名</th><th>打率</th><th>試合</th><th>打席</th><th>打数</th><th>安打</th><th>本塁打</th><th>打点</th><th>盗塁</th><th>四球</th><th>三振</th><th>出塁率</th><th>長打率</th><th>OPS</th></tr></thead>
+			<tbody>
+				<tr><td>17</td><td><a href="/npb/player/1000002/top">二刀 流</a></td><td>.300</td><td>50</td><td>200</td><td>180</td><td>54</td><td>15</td><td>40</td><td>5</td><td>20</td><td>50</td><td>.370</td><td>.600</td><td>.970</td></tr>
+				<tr><td>5</td><td><a href="/npb/player/1000003/top">野手 三郎</a></td><td>.250</td><td>100</td><td>400</td><td>360</td><td>90</td><td>10</td><td>50</td><td>3</td><td>30</td><td>80</td><td>.310</td><td>.400</td><td>.710</td></tr>
+			</tbody>
+		</table>`
+	pitchersURL := "https://baseball.yahoo.co.jp/npb/teams/5/memberlist?kind=p"
+	battersURL := "https://baseball.yahoo.co.jp/npb/teams/5/memberlist?kind=b"
+
+	//投手・野手の両方に載っている選手は1人にまとめる
+	t.Run("Get players", func(t *testing.T) {
+		scraper := &mockScraper{pages: map[string]string{pitchersURL: pitchers, battersURL: batters}}
+
+		players, err := (&Yahoo{Scraper: scraper}).Players(context.Background(), "阪神")
+		assert.NoError(t, err)
+		if assert.Len(t, players, 3) {
+			assert.Equal(t, 1000002, players[1].ID)
+			assert.Equal(t, "阪神", players[1].Team)
+			assert.Equal(t, models.PositionPitcher, players[1].Position)
+			assert.Equal(t, 4, players[1].Pitching.Wins)
+			assert.Equal(t, 15, players[1].Batting.HomeRuns)
+			assert.Equal(t, models.PositionBatter, players[2].Position)
+			assert.Nil(t, players[2].Pitching)
+		}
+	})
+
+	t.Run("Unknown team", func(t *testing.T) {
+		_, err := (&Yahoo{Scraper: &mockScraper{}}).Players(context.Background(), "Giants")
+		assert.Error(t, err)
+	})
+
+	t.Run("Parse failure", func(t *testing.T) {
+		scraper := &mockScraper{pages: map[string]string{pitchersURL: pitchers, battersURL: `<table class="bb-playerTable"></table>`}}
+
+		_, err := (&Yahoo{Scraper: scraper}).Players(context.Background(), "阪神")
+		var failure *ParseFailure
+		if assert.ErrorAs(t, err, &failure) {
+			assert.Equal(t, "players", failure.Page)
+			assert.Equal(t, battersURL, failure.URL)
+			assert.Equal(t, "players.header", failure.Field)
+		}
+	})
+}
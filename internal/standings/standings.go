@@ -0,0 +1,226 @@
+package standings
+
+import (
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"baseball_report/internal/teams"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 直近の成績に含める試合数
+const lastGames = 10
+
+// 試合終了を表すイニング表記
+const inningGameEnd = "試合終了"
+
+// 順位表のあるリーグか
+func IsLeague(league string) bool {
+	return len(teams.League(league)) != 0
+}
+
+// 交流戦の日程ページの見出しに含まれる文字列
+const interleague = "交流戦"
+
+// 順位表に含める公式戦の試合か（日程ページの見出し）
+// オープン戦・クライマックスシリーズ・日本シリーズは含めない
+func IsRegularSeason(league string) bool {
+	return IsLeague(league) || strings.Contains(league, interleague)
+}
+
+// IsRegularSeasonと同じ条件で試合結果を取得するための条件
+func RegularSeason() repository.RegularSeason {
+	return repository.RegularSeason{Leagues: teams.Leagues(), Interleague: interleague}
+}
+
+// 順位表のあるリーグ（名前の順）
+func Leagues() []string {
+	return teams.Leagues()
+}
+
+// 終了した試合からホーム・アウェイそれぞれの試合結果を作る
+// チーム名は日程ページの表記に揃える
+func Results(game models.MatchDetail) ([]models.GameResult, error) {
+	home, err := strconv.Atoi(game.HomeScore)
+	if err != nil {
+		return nil, fmt.Errorf("invalid home score %q of match %d", game.HomeScore, game.ID)
+	}
+	away, err := strconv.Atoi(game.AwayScore)
+	if err != nil {
+		return nil, fmt.Errorf("invalid away score %q of match %d", game.AwayScore, game.ID)
+	}
+	date := game.Date
+	//parseTime有効時はRFC3339になる
+	if len(date) > len("2006-01-02") {
+		date = date[:len("2006-01-02")]
+	}
+	homeTeam, awayTeam := teams.Canonical(game.Home), teams.Canonical(game.Away)
+	return []models.GameResult{
+		{MatchID: game.ID, Date: date, Team: homeTeam, Opponent: awayTeam, Home: true, Runs: home, OpponentRuns: away, Result: result(home, away)},
+		{MatchID: game.ID, Date: date, Team: awayTeam, Opponent: homeTeam, Home: false, Runs: away, OpponentRuns: home, Result: result(away, home)},
+	}, nil
+}
+
+// teamから見た試合（teamの試合でない場合はfalse）
+// 得点と勝敗は試合終了でスコアが数字の場合のみ入れる
+func TeamGame(game models.MatchDetail, team string) (models.TeamGame, bool) {
+	home := teams.Canonical(game.Home) == team
+	if !home && teams.Canonical(game.Away) != team {
+		return models.TeamGame{}, false
+	}
+	tg := models.TeamGame{
+		MatchID:   game.ID,
+		Date:      game.Date,
+		StartTime: game.StartTime,
+		Home:      home,
+		League:    game.League,
+		Stadium:   game.Stadium,
+		Inning:    game.Inning,
+	}
+	if len(tg.Date) > len("2006-01-02") {
+		tg.Date = tg.Date[:len("2006-01-02")]
+	}
+	tg.Opponent = teams.Canonical(game.Home)
+	if home {
+		tg.Opponent = teams.Canonical(game.Away)
+	}
+	if game.Inning != inningGameEnd {
+		return tg, true
+	}
+	results, err := Results(game)
+	if err != nil {
+		return tg, true
+	}
+	r := results[1]
+	if home {
+		r = results[0]
+	}
+	tg.Runs, tg.OpponentRuns, tg.Result = &r.Runs, &r.OpponentRuns, &r.Result
+	return tg, true
+}
+
+// 試合結果の合計（勝敗が入っている試合のみ）
+func Total(games []models.TeamGame) models.Record {
+	var rec models.Record
+	for _, g := range games {
+		if g.Result != nil {
+			rec = add(rec, *g.Result)
+		}
+	}
+	return rec
+}
+
+func add(rec models.Record, result string) models.Record {
+	switch result {
+	case models.ResultWin:
+		rec.Wins++
+	case models.ResultLoss:
+		rec.Losses++
+	default:
+		rec.Ties++
+	}
+	return rec
+}
+
+func result(runs, opponentRuns int) string {
+	switch {
+	case runs > opponentRuns:
+		return models.ResultWin
+	case runs < opponentRuns:
+		return models.ResultLoss
+	}
+	return models.ResultTie
+}
+
+// 日付順の試合結果からリーグごとの順位表を作る（leagueが空でなければそのリーグのみ）
+// 勝率（引き分けを除く）の高い順、同率は貯金の多い順、さらに勝利数の多い順。ゲーム差は首位との差
+func Compute(results []models.GameResult, league string) map[string][]models.Standing {
+	rows := map[string]*models.Standing{}
+	recent := map[string][]string{}
+	for _, r := range results {
+		row, ok := rows[r.Team]
+		if !ok {
+			row = &models.Standing{Team: r.Team}
+			rows[r.Team] = row
+		}
+		split := &row.Away
+		if r.Home {
+			split = &row.Home
+		}
+		switch r.Result {
+		case models.ResultWin:
+			row.Wins++
+			split.Wins++
+		case models.ResultLoss:
+			row.Losses++
+			split.Losses++
+		default:
+			row.Ties++
+			split.Ties++
+		}
+		recent[r.Team] = append(recent[r.Team], r.Result)
+	}
+
+	standings := map[string][]models.Standing{}
+	//交流戦の試合も所属リーグの順位表に含める
+	for _, name := range teams.Leagues() {
+		if league != "" && name != league {
+			continue
+		}
+		members := teams.League(name)
+		table := make([]models.Standing, 0, len(members))
+		for _, team := range members {
+			row := models.Standing{Team: team}
+			if r, ok := rows[team]; ok {
+				row = *r
+			}
+			row.Games = row.Wins + row.Losses + row.Ties
+			row.Pct = pct(row.Wins, row.Losses)
+			row.Last10 = record(recent[team])
+			table = append(table, row)
+		}
+		//表示用に丸める前の勝率で比べる
+		sort.SliceStable(table, func(i, j int) bool {
+			a, b := table[i], table[j]
+			if x, y := a.Wins*(b.Wins+b.Losses), b.Wins*(a.Wins+a.Losses); x != y && a.Wins+a.Losses != 0 && b.Wins+b.Losses != 0 {
+				return x > y
+			}
+			if a.Pct != b.Pct {
+				return a.Pct > b.Pct
+			}
+			if a.Wins-a.Losses != b.Wins-b.Losses {
+				return a.Wins-a.Losses > b.Wins-b.Losses
+			}
+			return a.Wins > b.Wins
+		})
+		for i := range table {
+			table[i].Rank = i + 1
+			table[i].GamesBehind = float64((table[0].Wins-table[i].Wins)+(table[i].Losses-table[0].Losses)) / 2
+		}
+		standings[name] = table
+	}
+	return standings
+}
+
+// 勝率（小数第3位まで、試合がない場合は0）
+func pct(wins, losses int) float64 {
+	if wins+losses == 0 {
+		return 0
+	}
+	return math.Round(float64(wins)/float64(wins+losses)*1000) / 1000
+}
+
+// 直近lastGames試合の勝敗数
+func record(results []string) models.Record {
+	if len(results) > lastGames {
+		results = results[len(results)-lastGames:]
+	}
+	var rec models.Record
+	for _, r := range results {
+		rec = add(rec, r)
+	}
+	return rec
+}
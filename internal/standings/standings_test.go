@@ -0,0 +1,262 @@
+package standings
+
+import (
+	"baseball_report/internal/feed"
+	"baseball_report/internal/models"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStore struct {
+	mu      sync.Mutex
+	games   []models.MatchDetail
+	err     error
+	calls   []int
+	results []models.GameResult
+}
+
+func (m *mockStore) UnrecordedGames(matchID int) ([]models.MatchDetail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, matchID)
+	return m.games, m.err
+}
+
+func (m *mockStore) SaveGameResults(results []models.GameResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, results...)
+	return nil
+}
+
+func (m *mockStore) recorded() ([]int, []models.GameResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int(nil), m.calls...), append([]models.GameResult(nil), m.results...)
+}
+
+// 試合結果（勝ち・負け・引き分け）を作る
+func win(date, team, opponent string, home bool) []models.GameResult {
+	return []models.GameResult{
+		{Date: date, Team: team, Opponent: opponent, Home: home, Runs: 3, OpponentRuns: 1, Result: models.ResultWin},
+		{Date: date, Team: opponent, Opponent: team, Home: !home, Runs: 1, OpponentRuns: 3, Result: models.ResultLoss},
+	}
+}
+
+func tie(date, team, opponent string) []models.GameResult {
+	return []models.GameResult{
+		{Date: date, Team: team, Opponent: opponent, Home: true, Runs: 2, OpponentRuns: 2, Result: models.ResultTie},
+		{Date: date, Team: opponent, Opponent: team, Home: false, Runs: 2, OpponentRuns: 2, Result: models.ResultTie},
+	}
+}
+
+func TestResults(t *testing.T) {
+	t.Run("Home win", func(t *testing.T) {
+		results, err := Results(models.MatchDetail{ID: 1, Date: "2025-04-06T00:00:00Z", Home: "巨人", Away: "阪神", HomeScore: "4", AwayScore: "3"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.GameResult{
+			{MatchID: 1, Date: "2025-04-06", Team: "巨人", Opponent: "阪神", Home: true, Runs: 4, OpponentRuns: 3, Result: models.ResultWin},
+			{MatchID: 1, Date: "2025-04-06", Team: "阪神", Opponent: "巨人", Home: false, Runs: 3, OpponentRuns: 4, Result: models.ResultLoss},
+		}, results)
+	})
+
+	t.Run("Tie", func(t *testing.T) {
+		results, err := Results(models.MatchDetail{ID: 2, Date: "2025-04-06", Home: "広島", Away: "DeNA", HomeScore: "2", AwayScore: "2"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ResultTie, results[0].Result)
+		assert.Equal(t, models.ResultTie, results[1].Result)
+	})
+
+	t.Run("Invalid score", func(t *testing.T) {
+		_, err := Results(models.MatchDetail{ID: 3, HomeScore: "-", AwayScore: "0"})
+		assert.Error(t, err)
+	})
+}
+
+// 別表記のチーム名は日程ページの表記に揃える
+func TestResults_Canonical(t *testing.T) {
+	results, err := Results(models.MatchDetail{ID: 4, Date: "2025-04-06", Home: "横浜DeNAベイスターズ", Away: "阪神", HomeScore: "1", AwayScore: "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DeNA", results[0].Team)
+	assert.Equal(t, "DeNA", results[1].Opponent)
+}
+
+func TestTeamGame(t *testing.T) {
+	game := models.MatchDetail{ID: 1, Date: "2025-04-06T00:00:00Z", Home: "ヤクルト", Away: "中日", League: "セ・リーグ", Stadium: "神宮", StartTime: "13:00:00", Inning: "試合終了", HomeScore: "3", AwayScore: "2"}
+
+	t.Run("Away team", func(t *testing.T) {
+		tg, ok := TeamGame(game, "中日")
+		assert.True(t, ok)
+		assert.Equal(t, "2025-04-06", tg.Date)
+		assert.Equal(t, "ヤクルト", tg.Opponent)
+		assert.False(t, tg.Home)
+		assert.Equal(t, 2, *tg.Runs)
+		assert.Equal(t, 3, *tg.OpponentRuns)
+		assert.Equal(t, models.ResultLoss, *tg.Result)
+	})
+
+	//試合終了でなければ得点と勝敗は入れない
+	t.Run("In progress", func(t *testing.T) {
+		live := game
+		live.Inning = "5回表"
+		tg, ok := TeamGame(live, "ヤクルト")
+		assert.True(t, ok)
+		assert.True(t, tg.Home)
+		assert.Nil(t, tg.Runs)
+		assert.Nil(t, tg.Result)
+	})
+
+	t.Run("Other team", func(t *testing.T) {
+		_, ok := TeamGame(game, "阪神")
+		assert.False(t, ok)
+	})
+
+	t.Run("Total", func(t *testing.T) {
+		win, _ := TeamGame(game, "ヤクルト")
+		loss, _ := TeamGame(game, "中日")
+		assert.Equal(t, models.Record{Wins: 1, Losses: 1}, Total([]models.TeamGame{win, loss, {}}))
+	})
+}
+
+func TestCompute(t *testing.T) {
+	var results []models.GameResult
+	results = append(results, win("2025-04-01", "阪神", "巨人", true)...)
+	results = append(results, win("2025-04-02", "阪神", "巨人", true)...)
+	results = append(results, win("2025-04-03", "巨人", "阪神", false)...)
+	results = append(results, tie("2025-04-04", "広島", "DeNA")...)
+	//交流戦は所属リーグの順位表に含める
+	results = append(results, win("2025-06-01", "ソフトバンク", "阪神", true)...)
+
+	t.Run("Central league", func(t *testing.T) {
+		table := Compute(results, "セ・リーグ")
+		assert.Len(t, table, 1)
+		central := table["セ・リーグ"]
+		if !assert.Len(t, central, 6) {
+			return
+		}
+
+		first := central[0]
+		assert.Equal(t, "阪神", first.Team)
+		assert.Equal(t, 1, first.Rank)
+		assert.Equal(t, 4, first.Games)
+		assert.Equal(t, 0.5, first.Pct)
+		assert.Equal(t, 0.0, first.GamesBehind)
+		assert.Equal(t, models.Record{Wins: 2, Losses: 1}, first.Home)
+		assert.Equal(t, models.Record{Losses: 1}, first.Away)
+		assert.Equal(t, models.Record{Wins: 2, Losses: 2}, first.Last10)
+
+		giants := central[1]
+		assert.Equal(t, "巨人", giants.Team)
+		assert.Equal(t, 0.333, giants.Pct)
+		assert.Equal(t, 0.5, giants.GamesBehind)
+
+		var hiroshima models.Standing
+		for _, row := range central {
+			if row.Team == "広島" {
+				hiroshima = row
+			}
+		}
+		assert.Equal(t, 1, hiroshima.Ties)
+		assert.Equal(t, models.Record{Ties: 1}, hiroshima.Home)
+	})
+
+	t.Run("All leagues", func(t *testing.T) {
+		table := Compute(results, "")
+		assert.Len(t, table, 2)
+		assert.Equal(t, "ソフトバンク", table["パ・リーグ"][0].Team)
+		assert.Equal(t, 1.0, table["パ・リーグ"][0].Pct)
+	})
+
+	//同じ勝率では負け越しているチームが下
+	t.Run("Same pct", func(t *testing.T) {
+		table := Compute(win("2025-04-01", "ヤクルト", "中日", true), "セ・リーグ")["セ・リーグ"]
+		assert.Equal(t, "ヤクルト", table[0].Team)
+		assert.Equal(t, "中日", table[5].Team)
+		assert.Equal(t, 0.5, table[1].GamesBehind)
+		assert.Equal(t, 1.0, table[5].GamesBehind)
+	})
+
+	//直近10試合のみ数える
+	t.Run("Last 10", func(t *testing.T) {
+		var results []models.GameResult
+		for i := 0; i < 5; i++ {
+			results = append(results, win("2025-04-01", "巨人", "中日", true)...)
+		}
+		for i := 0; i < 8; i++ {
+			results = append(results, win("2025-04-02", "中日", "巨人", true)...)
+		}
+		table := Compute(results, "セ・リーグ")["セ・リーグ"]
+		assert.Equal(t, "中日", table[0].Team)
+		assert.Equal(t, models.Record{Wins: 8, Losses: 2}, table[0].Last10)
+		assert.Equal(t, 3.0, table[1].GamesBehind)
+	})
+}
+
+func TestUpdater_Handle(t *testing.T) {
+	store := &mockStore{games: []models.MatchDetail{{ID: 5, Date: "2025-04-06", Home: "巨人", Away: "阪神", League: "セ・リーグ", HomeScore: "4", AwayScore: "3"}}}
+	u := &Updater{Store: store}
+
+	//試合終了以外の変化は登録しない
+	u.Handle(feed.ScoreEvent{MatchID: 5, Previous: feed.ScoreState{Inning: "9回表"}, Score: feed.ScoreState{Inning: "9回裏"}})
+	u.Handle(feed.ScoreEvent{MatchID: 5, Previous: feed.ScoreState{Inning: "9回裏"}, Score: feed.ScoreState{Inning: "試合終了"}})
+
+	calls, results := store.recorded()
+	assert.Equal(t, []int{5}, calls)
+	assert.Len(t, results, 2)
+}
+
+// オープン戦・ポストシーズンの試合は順位表に含めない
+func TestUpdater_Record_RegularSeason(t *testing.T) {
+	store := &mockStore{games: []models.MatchDetail{
+		{ID: 1, Date: "2025-03-01", Home: "巨人", Away: "阪神", League: "オープン戦", HomeScore: "4", AwayScore: "3"},
+		{ID: 2, Date: "2025-06-01", Home: "ソフトバンク", Away: "阪神", League: "日本生命セ・パ交流戦", HomeScore: "2", AwayScore: "1"},
+		{ID: 3, Date: "2025-10-25", Home: "ソフトバンク", Away: "阪神", League: "日本シリーズ", HomeScore: "2", AwayScore: "1"},
+	}}
+	u := &Updater{Store: store}
+
+	assert.NoError(t, u.Record(0))
+	_, results := store.recorded()
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, 2, results[0].MatchID)
+	}
+	assert.True(t, IsRegularSeason("パ・リーグ"))
+	assert.False(t, IsRegularSeason("クライマックスシリーズ"))
+}
+
+func TestUpdater_Record_StoreError(t *testing.T) {
+	u := &Updater{Store: &mockStore{err: errors.New("db down")}}
+	assert.Error(t, u.Record(0))
+}
+
+// 起動時に未登録の試合結果をまとめて登録し、以降は試合終了ごとに登録する
+func TestUpdater_Run(t *testing.T) {
+	store := &mockStore{}
+	u := &Updater{Store: store}
+	b := feed.NewBroker(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		u.Run(ctx, b)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		calls, _ := store.recorded()
+		return len(calls) == 1
+	}, time.Second, 5*time.Millisecond)
+	b.Publish(feed.ScoreEvent{MatchID: 7, Previous: feed.ScoreState{Inning: "9回裏"}, Score: feed.ScoreState{Inning: "試合終了"}})
+	assert.Eventually(t, func() bool {
+		calls, _ := store.recorded()
+		return len(calls) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+	calls, _ := store.recorded()
+	assert.Equal(t, []int{0, 7}, calls)
+}
@@ -0,0 +1,127 @@
+package standings
+
+import (
+	db "baseball_report/internal/config"
+	"baseball_report/internal/feed"
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"context"
+	"fmt"
+	"log"
+)
+
+// Store 終了した試合の取得と試合結果の登録
+type Store interface {
+	// matchIDが0の場合はすべての試合
+	UnrecordedGames(matchID int) ([]models.MatchDetail, error)
+	SaveGameResults(results []models.GameResult) error
+}
+
+// DBStore matches・scores・game_resultsテーブルを使用するStore
+type DBStore struct {
+	Connect db.DBHandler
+	Repo    *repository.DefaultRepository
+}
+
+// NewDBStore 既定のDB接続を使用するStoreを生成
+func NewDBStore() *DBStore {
+	return &DBStore{Connect: &db.DBService{}, Repo: &repository.DefaultRepository{}}
+}
+
+func (s *DBStore) UnrecordedGames(matchID int) ([]models.MatchDetail, error) {
+	conn, err := s.Connect.ConnectOnly()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check to connect database: %w", err)
+	}
+	defer conn.Close()
+	return s.Repo.GetUnrecordedGames(conn, matchID, RegularSeason())
+}
+
+func (s *DBStore) SaveGameResults(results []models.GameResult) error {
+	conn, err := s.Connect.ConnectOnly()
+	if err != nil {
+		return fmt.Errorf("failed to check to connect database: %w", err)
+	}
+	defer conn.Close()
+	return s.Repo.SaveGameResults(conn, results)
+}
+
+// Updater 試合終了を購読し、順位表の集計に使う試合結果を登録する
+type Updater struct {
+	Store Store
+}
+
+// NewUpdater 既定のDB接続を使用するUpdaterを生成
+func NewUpdater() *Updater {
+	return &Updater{Store: NewDBStore()}
+}
+
+// Run 未登録の試合結果を登録してから、ctxが終了するまでbの試合終了を購読して登録する
+// 購読が追いつかなかった場合は未登録の試合結果をまとめて登録し直す
+func (u *Updater) Run(ctx context.Context, b *feed.Broker) {
+	for {
+		sub := b.Subscribe(64)
+		if err := u.Record(0); err != nil {
+			log.Println(fmt.Errorf("failed to record game results: %w", err))
+		}
+		if !u.consume(ctx, sub) {
+			b.Unsubscribe(sub)
+			return
+		}
+		log.Println("standings updater fell behind, resubscribing")
+	}
+}
+
+// チャネルが閉じられるまで登録する。ctxが終了した場合はfalseを返す
+func (u *Updater) consume(ctx context.Context, sub *feed.Subscription) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case ev, ok := <-sub.C:
+			if !ok {
+				return true
+			}
+			u.Handle(ev)
+		}
+	}
+}
+
+// Handle 試合終了になった試合の結果を登録する
+func (u *Updater) Handle(ev feed.ScoreEvent) {
+	if ev.Score.Inning != inningGameEnd || ev.Previous.Inning == inningGameEnd {
+		return
+	}
+	if err := u.Record(ev.MatchID); err != nil {
+		log.Println(fmt.Errorf("failed to record result of match %d: %w", ev.MatchID, err))
+	}
+}
+
+// Record 終了した試合のうち結果が未登録の試合を登録する（matchIDが0の場合はすべての試合）
+// 公式戦以外の試合とスコアが不正な試合は飛ばす
+func (u *Updater) Record(matchID int) error {
+	games, err := u.Store.UnrecordedGames(matchID)
+	if err != nil {
+		return err
+	}
+	var results []models.GameResult
+	for _, game := range games {
+		if !IsRegularSeason(game.League) {
+			continue
+		}
+		r, err := Results(game)
+		if err != nil {
+			log.Println(fmt.Errorf("failed to get game result: %w", err))
+			continue
+		}
+		results = append(results, r...)
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	if err := u.Store.SaveGameResults(results); err != nil {
+		return err
+	}
+	log.Println("Recorded game results:", len(results)/2, "games")
+	return nil
+}
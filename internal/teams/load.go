@@ -0,0 +1,32 @@
+package teams
+
+import (
+	db "baseball_report/internal/config"
+	"baseball_report/internal/repository"
+	"fmt"
+)
+
+var repo = &repository.DefaultRepository{}
+
+// LoadFromDB teams・stadiumsテーブルと別表記を読み込み、チームと球場の定義を置き換える
+// テーブルを変更した場合は再起動で反映する
+func LoadFromDB(connect db.DBHandler) error {
+	conn, err := connect.ConnectOnly()
+	if err != nil {
+		return fmt.Errorf("failed to check to connect database: %w", err)
+	}
+	defer conn.Close()
+
+	teams, err := repo.GetTeams(conn)
+	if err != nil {
+		return err
+	}
+	venues, err := repo.GetStadiums(conn)
+	if err != nil {
+		return err
+	}
+	if err := Load(teams, venues); err != nil {
+		return fmt.Errorf("failed to load teams: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package teams
+
+import (
+	"baseball_report/internal/metrics"
+	"baseball_report/internal/models"
+	"log"
+)
+
+// UnknownNames 登録されていないチーム名・球場名を取得した回数（種類・表記ごと）
+var UnknownNames = metrics.NewCounter("baseball_report_unknown_names_total", "Number of scraped team or stadium names not found in the registry.", "kind", "name")
+
+// 日程から取得した試合のチーム名・球場名を日程ページの表記に揃え、IDを設定する
+// 登録されていない表記はそのまま残してIDを空にし、確認用にログとメトリクスに記録する
+func Resolve(match models.Match) models.Match {
+	if t, ok := resolveTeam(match.Home); ok {
+		match.Home, match.HomeTeamID = t.Name, t.ID
+	}
+	if t, ok := resolveTeam(match.Away); ok {
+		match.Away, match.AwayTeamID = t.Name, t.ID
+	}
+	if s, ok := LookupStadium(match.Stadium); ok {
+		match.Stadium, match.StadiumID = s.Name, s.ID
+	} else {
+		unknown("stadium", match.Stadium)
+	}
+	return match
+}
+
+func resolveTeam(name string) (models.Team, bool) {
+	t, ok := Lookup(name)
+	if !ok {
+		unknown("team", name)
+	}
+	return t, ok
+}
+
+func unknown(kind, name string) {
+	log.Printf("unknown %s name %q: add it to the registry if it is an alias", kind, name)
+	UnknownNames.Inc(kind, name)
+}
@@ -0,0 +1,188 @@
+package teams
+
+import (
+	"baseball_report/internal/models"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	Central = "セ・リーグ"
+	Pacific = "パ・リーグ"
+)
+
+// 12球団の初期値（Nameは日程ページの表記、Aliasesは正式名称などの別表記）
+// migrationsでteams・team_aliasesに同じ内容を登録し、起動時にLoadでテーブルの内容に置き換える
+var defaultTeams = []models.Team{
+	{ID: "giants", Name: "巨人", NameEn: "Yomiuri Giants", Abbreviation: "G", League: Central, Aliases: []string{"読売ジャイアンツ", "読売", "ジャイアンツ"}},
+	{ID: "swallows", Name: "ヤクルト", NameEn: "Tokyo Yakult Swallows", Abbreviation: "S", League: Central, Aliases: []string{"東京ヤクルトスワローズ", "東京ヤクルト", "スワローズ"}},
+	{ID: "baystars", Name: "DeNA", NameEn: "Yokohama DeNA BayStars", Abbreviation: "DB", League: Central, Aliases: []string{"横浜DeNAベイスターズ", "横浜DeNA", "横浜", "ベイスターズ"}},
+	{ID: "dragons", Name: "中日", NameEn: "Chunichi Dragons", Abbreviation: "D", League: Central, Aliases: []string{"中日ドラゴンズ", "ドラゴンズ"}},
+	{ID: "tigers", Name: "阪神", NameEn: "Hanshin Tigers", Abbreviation: "T", League: Central, Aliases: []string{"阪神タイガース", "タイガース"}},
+	{ID: "carp", Name: "広島", NameEn: "Hiroshima Toyo Carp", Abbreviation: "C", League: Central, Aliases: []string{"広島東洋カープ", "広島東洋", "カープ"}},
+	{ID: "lions", Name: "西武", NameEn: "Saitama Seibu Lions", Abbreviation: "L", League: Pacific, Aliases: []string{"埼玉西武ライオンズ", "埼玉西武", "ライオンズ"}},
+	{ID: "fighters", Name: "日本ハム", NameEn: "Hokkaido Nippon-Ham Fighters", Abbreviation: "F", League: Pacific, Aliases: []string{"北海道日本ハムファイターズ", "北海道日本ハム", "日ハム", "ファイターズ"}},
+	{ID: "marines", Name: "ロッテ", NameEn: "Chiba Lotte Marines", Abbreviation: "M", League: Pacific, Aliases: []string{"千葉ロッテマリーンズ", "千葉ロッテ", "マリーンズ"}},
+	{ID: "buffaloes", Name: "オリックス", NameEn: "ORIX Buffaloes", Abbreviation: "B", League: Pacific, Aliases: []string{"オリックス・バファローズ", "バファローズ"}},
+	{ID: "hawks", Name: "ソフトバンク", NameEn: "Fukuoka SoftBank Hawks", Abbreviation: "H", League: Pacific, Aliases: []string{"福岡ソフトバンクホークス", "福岡ソフトバンク", "ホークス"}},
+	{ID: "eagles", Name: "楽天", NameEn: "Tohoku Rakuten Golden Eagles", Abbreviation: "E", League: Pacific, Aliases: []string{"東北楽天ゴールデンイーグルス", "東北楽天", "イーグルス"}},
+}
+
+// 12球団の本拠地・準本拠地の球場の初期値（Nameは日程ページの表記）
+// migrationsでstadiums・stadium_aliasesに同じ内容を登録し、起動時にLoadでテーブルの内容に置き換える
+var defaultStadiums = []models.Stadium{
+	{ID: "tokyo-dome", Name: "東京ドーム", NameEn: "Tokyo Dome", TeamID: "giants", Aliases: []string{"東京D"}},
+	{ID: "jingu", Name: "神宮", NameEn: "Meiji Jingu Stadium", TeamID: "swallows", Aliases: []string{"明治神宮野球場", "神宮球場"}},
+	{ID: "yokohama", Name: "横浜", NameEn: "Yokohama Stadium", TeamID: "baystars", Aliases: []string{"横浜スタジアム", "ハマスタ"}},
+	{ID: "vantelin-dome", Name: "バンテリンドーム", NameEn: "Vantelin Dome Nagoya", TeamID: "dragons", Aliases: []string{"バンテリンドームナゴヤ", "ナゴヤドーム"}},
+	{ID: "koshien", Name: "甲子園", NameEn: "Hanshin Koshien Stadium", TeamID: "tigers", Aliases: []string{"阪神甲子園球場", "甲子園球場"}},
+	{ID: "mazda-stadium", Name: "マツダスタジアム", NameEn: "MAZDA Zoom-Zoom Stadium Hiroshima", TeamID: "carp", Aliases: []string{"MAZDA Zoom-Zoom スタジアム広島", "マツダ"}},
+	{ID: "belluna-dome", Name: "ベルーナドーム", NameEn: "Belluna Dome", TeamID: "lions", Aliases: []string{"メットライフドーム", "西武ドーム"}},
+	{ID: "escon-field", Name: "エスコンフィールド", NameEn: "ES CON FIELD HOKKAIDO", TeamID: "fighters", Aliases: []string{"エスコンフィールドHOKKAIDO", "エスコン"}},
+	{ID: "zozo-marine", Name: "ZOZOマリン", NameEn: "ZOZO Marine Stadium", TeamID: "marines", Aliases: []string{"ZOZOマリンスタジアム", "千葉マリン"}},
+	{ID: "kyocera-dome", Name: "京セラD大阪", NameEn: "Kyocera Dome Osaka", TeamID: "buffaloes", Aliases: []string{"京セラドーム大阪", "京セラドーム"}},
+	{ID: "hotto-motto-kobe", Name: "ほっと神戸", NameEn: "Hotto Motto Field Kobe", TeamID: "buffaloes", Aliases: []string{"ほっともっとフィールド神戸"}},
+	{ID: "mizuho-paypay", Name: "みずほPayPay", NameEn: "Mizuho PayPay Dome Fukuoka", TeamID: "hawks", Aliases: []string{"みずほPayPayドーム福岡", "みずほPayPayドーム", "PayPayドーム"}},
+	{ID: "rakuten-mobile", Name: "楽天モバイル", NameEn: "Rakuten Mobile Park Miyagi", TeamID: "eagles", Aliases: []string{"楽天モバイルパーク宮城", "楽天モバイルパーク"}},
+}
+
+var (
+	mu       sync.RWMutex
+	registry []models.Team
+	stadiums []models.Stadium
+	// 正規化した表記からチームへの索引（ID・チーム名・英語名・略称・別表記）
+	index map[string]int
+	// 正規化した表記から球場への索引（ID・球場名・英語名・別表記）
+	stadiumIndex map[string]int
+)
+
+func init() {
+	if err := Load(defaultTeams, defaultStadiums); err != nil {
+		panic(err)
+	}
+}
+
+// Load チームと球場の定義を置き換える
+// 正規化した表記が重複する場合や、球場の本拠地のチームがない場合は置き換えずにエラーを返す
+func Load(teams []models.Team, venues []models.Stadium) error {
+	if len(teams) == 0 {
+		return fmt.Errorf("no teams to load")
+	}
+	teamIndex := map[string]int{}
+	for i, t := range teams {
+		for _, key := range append([]string{t.ID, t.Name, t.NameEn, t.Abbreviation}, t.Aliases...) {
+			if j, ok := teamIndex[normalize(key)]; ok && j != i {
+				return fmt.Errorf("team name %q is used by both %s and %s", key, teams[j].ID, t.ID)
+			}
+			teamIndex[normalize(key)] = i
+		}
+	}
+	venueIndex := map[string]int{}
+	for i, s := range venues {
+		if _, ok := teamIndex[normalize(s.TeamID)]; s.TeamID != "" && !ok {
+			return fmt.Errorf("stadium %s has unknown team %s", s.ID, s.TeamID)
+		}
+		for _, key := range append([]string{s.ID, s.Name, s.NameEn}, s.Aliases...) {
+			if j, ok := venueIndex[normalize(key)]; ok && j != i {
+				return fmt.Errorf("stadium name %q is used by both %s and %s", key, venues[j].ID, s.ID)
+			}
+			venueIndex[normalize(key)] = i
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	registry = append([]models.Team(nil), teams...)
+	stadiums = append([]models.Stadium(nil), venues...)
+	index, stadiumIndex = teamIndex, venueIndex
+	return nil
+}
+
+// 全角英数字を半角に、英字を小文字にし、空白と中黒を除く
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		if unicode.IsSpace(r) || r == '・' || r == '･' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// すべてのチーム（読み込んだ順）
+func All() []models.Team {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]models.Team(nil), registry...)
+}
+
+// ID・チーム名・英語名・略称・別表記からチームを探す（全角・半角、大文字・小文字、空白は区別しない）
+func Lookup(name string) (models.Team, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	i, ok := index[normalize(name)]
+	if !ok {
+		return models.Team{}, false
+	}
+	return registry[i], true
+}
+
+// 日程ページの表記に揃えたチーム名（登録されていない場合はそのまま）
+func Canonical(name string) string {
+	if t, ok := Lookup(name); ok {
+		return t.Name
+	}
+	return name
+}
+
+// すべての球場
+func Stadiums() []models.Stadium {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]models.Stadium(nil), stadiums...)
+}
+
+// ID・球場名・英語名・別表記から球場を探す
+func LookupStadium(name string) (models.Stadium, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	i, ok := stadiumIndex[normalize(name)]
+	if !ok {
+		return models.Stadium{}, false
+	}
+	return stadiums[i], true
+}
+
+// リーグの所属チーム名（リーグがない場合はnil）
+func League(league string) []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for _, t := range registry {
+		if t.League == league {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// リーグ名（名前の順）
+func Leagues() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	seen := map[string]bool{}
+	var leagues []string
+	for _, t := range registry {
+		if !seen[t.League] {
+			seen[t.League] = true
+			leagues = append(leagues, t.League)
+		}
+	}
+	sort.Strings(leagues)
+	return leagues
+}
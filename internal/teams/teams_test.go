@@ -0,0 +1,208 @@
+package teams
+
+import (
+	"baseball_report/internal/migrate"
+	"baseball_report/internal/models"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	//表記ゆれは同じチームになる
+	for _, name := range []string{"baystars", "BayStars", "DeNA", "ＤｅＮＡ", "横浜DeNA", "横浜ＤｅＮＡベイスターズ", "横浜 DeNA ベイスターズ"} {
+		team, ok := Lookup(name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, "baystars", team.ID, name)
+			assert.Equal(t, "DeNA", team.Name, name)
+		}
+	}
+
+	team, ok := Lookup("オリックスバファローズ")
+	assert.True(t, ok)
+	assert.Equal(t, "オリックス", team.Name)
+
+	_, ok = Lookup("レッドソックス")
+	assert.False(t, ok)
+}
+
+func TestCanonical(t *testing.T) {
+	assert.Equal(t, "日本ハム", Canonical("北海道日本ハムファイターズ"))
+	assert.Equal(t, "巨人", Canonical("巨人"))
+	//登録されていないチームはそのまま
+	assert.Equal(t, "全セ", Canonical("全セ"))
+}
+
+func TestLeague(t *testing.T) {
+	assert.Equal(t, []string{"巨人", "ヤクルト", "DeNA", "中日", "阪神", "広島"}, League(Central))
+	assert.Len(t, League(Pacific), 6)
+	assert.Nil(t, League("MLB"))
+	assert.Equal(t, []string{Central, Pacific}, Leagues())
+}
+
+// ID・名前・英語名・略称・別表記は正規化しても重複しない
+func TestRegistry_Unique(t *testing.T) {
+	count := 0
+	for _, team := range All() {
+		count += 4 + len(team.Aliases)
+	}
+	assert.Equal(t, count, len(index))
+
+	count = 0
+	for _, s := range Stadiums() {
+		count += 3 + len(s.Aliases)
+		_, ok := Lookup(s.TeamID)
+		assert.True(t, ok, s.ID)
+	}
+	assert.Equal(t, count, len(stadiumIndex))
+}
+
+func TestLookupStadium(t *testing.T) {
+	for _, name := range []string{"甲子園", "阪神甲子園球場", "koshien", "Hanshin Koshien Stadium"} {
+		s, ok := LookupStadium(name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, "koshien", s.ID, name)
+		}
+	}
+	_, ok := LookupStadium("富山")
+	assert.False(t, ok)
+}
+
+func TestResolve(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	t.Run("Known names", func(t *testing.T) {
+		match := Resolve(models.Match{ID: 1, Home: "横浜DeNAベイスターズ", Away: "阪神", Stadium: "横浜スタジアム"})
+		assert.Equal(t, models.Match{ID: 1, Home: "DeNA", Away: "阪神", Stadium: "横浜", HomeTeamID: "baystars", AwayTeamID: "tigers", StadiumID: "yokohama"}, match)
+		assert.Empty(t, buf.String())
+	})
+
+	//登録されていない表記はそのまま残し、ログとメトリクスに記録する
+	t.Run("Unknown names", func(t *testing.T) {
+		before := UnknownNames.Value("stadium", "富山")
+		match := Resolve(models.Match{Home: "全セ", Away: "巨人", Stadium: "富山"})
+		assert.Equal(t, "全セ", match.Home)
+		assert.Empty(t, match.HomeTeamID)
+		assert.Equal(t, "giants", match.AwayTeamID)
+		assert.Equal(t, "富山", match.Stadium)
+		assert.Empty(t, match.StadiumID)
+		assert.Contains(t, buf.String(), `unknown team name "全セ"`)
+		assert.Contains(t, buf.String(), `unknown stadium name "富山"`)
+		assert.Equal(t, before+1, UnknownNames.Value("stadium", "富山"))
+	})
+}
+
+// マイグレーションで登録するteams・stadiumsと初期値が一致する
+func TestRegistry_Seed(t *testing.T) {
+	migrations, err := migrate.Load()
+	assert.NoError(t, err)
+	var seed string
+	for _, m := range migrations {
+		if m.Name == "create_teams_stadiums" {
+			seed = m.Up
+		}
+	}
+	if !assert.NotEmpty(t, seed) {
+		return
+	}
+
+	rows := regexp.MustCompile(`\('[^)]*'\)`).FindAllString(seed, -1)
+	var want []string
+	for _, team := range defaultTeams {
+		want = append(want, fmt.Sprintf("('%s', '%s', '%s', '%s', '%s')", team.ID, team.Name, team.NameEn, team.Abbreviation, team.League))
+		for _, alias := range team.Aliases {
+			want = append(want, fmt.Sprintf("('%s', '%s')", alias, team.ID))
+		}
+	}
+	for _, s := range defaultStadiums {
+		want = append(want, fmt.Sprintf("('%s', '%s', '%s', '%s')", s.ID, s.Name, s.NameEn, s.TeamID))
+		for _, alias := range s.Aliases {
+			want = append(want, fmt.Sprintf("('%s', '%s')", alias, s.ID))
+		}
+	}
+	assert.ElementsMatch(t, want, rows)
+}
+
+type mockDBHandler struct {
+	db *sql.DB
+}
+
+func (m *mockDBHandler) ConnectOnly() (*sql.DB, error) {
+	return m.db, nil
+}
+
+func TestLoad(t *testing.T) {
+	defer Load(defaultTeams, defaultStadiums)
+
+	//テーブルの内容に置き換える
+	t.Run("Load from database", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM teams ORDER BY league, id")).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "name", "name_en", "abbreviation", "league"}).
+				AddRow("giants", "巨人", "Yomiuri Giants", "G", Central).
+				AddRow("hawks", "ソフトバンク", "Fukuoka SoftBank Hawks", "H", Pacific))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM team_aliases")).WillReturnRows(
+			sqlmock.NewRows([]string{"alias", "team_id"}).AddRow("読売", "giants").AddRow("若鷹", "hawks"))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM stadiums ORDER BY id")).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "name", "name_en", "team_id"}).
+				AddRow("tokyo-dome", "東京ドーム", "Tokyo Dome", "giants").
+				AddRow("toyama", "富山", "Toyama Alpen Stadium", nil))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM stadium_aliases")).WillReturnRows(
+			sqlmock.NewRows([]string{"alias", "stadium_id"}).AddRow("富山アルペンスタジアム", "toyama"))
+
+		assert.NoError(t, LoadFromDB(&mockDBHandler{db: db}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		team, ok := Lookup("若鷹")
+		assert.True(t, ok)
+		assert.Equal(t, "hawks", team.ID)
+		_, ok = Lookup("阪神")
+		assert.False(t, ok)
+		s, ok := LookupStadium("富山アルペンスタジアム")
+		assert.True(t, ok)
+		assert.Equal(t, models.Stadium{ID: "toyama", Name: "富山", NameEn: "Toyama Alpen Stadium", Aliases: []string{"富山アルペンスタジアム"}}, s)
+		assert.Equal(t, []string{"巨人"}, League(Central))
+	})
+
+	//表記が重複する場合は置き換えない
+	t.Run("Duplicate name", func(t *testing.T) {
+		assert.NoError(t, Load(defaultTeams, defaultStadiums))
+		teams := All()
+		teams[1].Aliases = append(teams[1].Aliases, "読売")
+		err := Load(teams, Stadiums())
+		assert.ErrorContains(t, err, `team name "読売" is used by both giants and swallows`)
+		team, ok := Lookup("ヤクルト")
+		assert.True(t, ok)
+		assert.NotContains(t, team.Aliases, "読売")
+	})
+
+	//本拠地のチームがない球場は置き換えない
+	t.Run("Unknown home team", func(t *testing.T) {
+		stadiums := append(Stadiums(), models.Stadium{ID: "taipei-dome", Name: "台北ドーム", TeamID: "brothers"})
+		err := Load(All(), stadiums)
+		assert.ErrorContains(t, err, "stadium taipei-dome has unknown team brothers")
+		_, ok := LookupStadium("台北ドーム")
+		assert.False(t, ok)
+	})
+
+	//テーブルを読めない場合は置き換えない
+	t.Run("Fail to fetch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM teams")).WillReturnError(sql.ErrConnDone)
+
+		err = LoadFromDB(&mockDBHandler{db: db})
+		assert.ErrorContains(t, err, "failed to fetch teams")
+		assert.Len(t, All(), 12)
+	})
+}
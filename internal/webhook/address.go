@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress 通知先がループバック・プライベート・リンクローカルなどの内部アドレス
+var ErrBlockedAddress = errors.New("address is not allowed for webhooks")
+
+// キャリアグレードNATの共有アドレス（100.64.0.0/10）
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// 名前解決（テストで差し替えられるよう変数にする）
+var lookupNetIP = net.DefaultResolver.LookupNetIP
+
+// 内部ネットワーク向けのアドレスか
+func blocked(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
+
+// CheckURL 通知先のホストを名前解決し、内部アドレスが含まれる場合はErrBlockedAddressを返す
+// 登録後に名前解決の結果が変わる場合に備え、送信時も接続先のアドレスを確認する
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	addrs := []netip.Addr{}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, ip)
+	} else if addrs, err = lookupNetIP(ctx, "ip", host); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, ip := range addrs {
+		if blocked(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, ip)
+		}
+	}
+	return nil
+}
+
+// 接続の直前に接続先のアドレスを確認する（net.DialerのControl）
+func dialControl(network, address string, c syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil || blocked(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	return nil
+}
+
+// 内部アドレスへ接続しないHTTPクライアント
+// プロキシ経由では接続先を確認できないため、環境変数のプロキシ設定は使わない
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialControl}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
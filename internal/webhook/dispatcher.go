@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"baseball_report/internal/feed"
+	"baseball_report/internal/metrics"
+	"baseball_report/internal/models"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store 通知先の取得と送信失敗の記録
+type Store interface {
+	ActiveWebhooks() ([]models.Webhook, error)
+	SaveDeadLetter(dl models.DeadLetter) error
+}
+
+// Payload 通知するJSON
+type Payload struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Match      PayloadMatch    `json:"match"`
+	Score      feed.ScoreState `json:"score"`
+	Previous   feed.ScoreState `json:"previous"`
+}
+
+// PayloadMatch 通知対象の試合
+type PayloadMatch struct {
+	ID     int    `json:"id"`
+	League string `json:"league"`
+	Home   string `json:"home"`
+	Away   string `json:"away"`
+}
+
+// Dispatcher 試合進捗の変化を購読し、Webhookに通知する
+type Dispatcher struct {
+	Store       Store
+	Client      *http.Client
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// 同時に送信する数の上限（0以下の場合は1）
+	Workers int
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+// NewDispatcher 既定の設定でDispatcherを生成
+// 1, 2, 4, 8秒の間隔で再送し、5回失敗したらdead letterに記録する
+// 内部アドレスへは送信せず、同時に送信するのは8件まで
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		Store:       store,
+		Client:      newClient(10 * time.Second),
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+		Workers:     8,
+	}
+}
+
+// SkippedEvents 購読が追いつかず、履歴から消えていたため通知できなかった変化の件数
+var SkippedEvents = metrics.NewCounter("baseball_report_webhook_skipped_events_total", "Number of score events not delivered to webhooks because they had left the broker history.")
+
+// Run ctxが終了するまでbの変化を購読して通知する
+func (d *Dispatcher) Run(ctx context.Context, b *feed.Broker) {
+	var lastID uint64
+	for {
+		//取りこぼした場合は保持中の履歴から再開する
+		sub, missed := d.resume(b, lastID)
+		for _, ev := range missed {
+			d.Handle(ctx, ev)
+			lastID = ev.ID
+		}
+
+		if !d.consume(ctx, sub, &lastID) {
+			b.Unsubscribe(sub)
+			return
+		}
+		log.Println("webhook dispatcher fell behind, resubscribing")
+	}
+}
+
+// lastIDより後の変化から購読を再開する
+// 履歴から消えていた変化は通知できないため、件数をログとSkippedEventsに記録する
+func (d *Dispatcher) resume(b *feed.Broker, lastID uint64) (*feed.Subscription, []feed.ScoreEvent) {
+	sub, missed, latest, complete := b.Resume(lastID, 256)
+	if !complete {
+		skipped := latest - lastID - uint64(len(missed))
+		log.Printf("webhook dispatcher skipped %d events after id %d: they had left the history", skipped, lastID)
+		SkippedEvents.Add(skipped)
+	}
+	return sub, missed
+}
+
+// チャネルが閉じられるまで通知する。ctxが終了した場合はfalseを返す
+func (d *Dispatcher) consume(ctx context.Context, sub *feed.Subscription, lastID *uint64) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case ev, ok := <-sub.C:
+			if !ok {
+				return true
+			}
+			d.Handle(ctx, ev)
+			*lastID = ev.ID
+		}
+	}
+}
+
+// Handle 1件の変化を分類し、該当するWebhookへ非同期で通知する
+// 送信中の数がWorkersに達している場合は空くまで待つ（待つ間の変化は購読の履歴から再開する）
+func (d *Dispatcher) Handle(ctx context.Context, ev feed.ScoreEvent) {
+	types := Classify(ev)
+	if len(types) == 0 {
+		return
+	}
+
+	hooks, err := d.Store.ActiveWebhooks()
+	if err != nil {
+		log.Println(fmt.Errorf("failed to get webhooks: %w", err))
+		return
+	}
+
+	for _, eventType := range types {
+		payload := Payload{
+			ID:         fmt.Sprintf("%d-%s", ev.ID, eventType),
+			Type:       eventType,
+			OccurredAt: ev.Time,
+			Match:      PayloadMatch{ID: ev.MatchID, League: ev.League, Home: ev.Home, Away: ev.Away},
+			Score:      ev.Score,
+			Previous:   ev.Previous,
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Println(fmt.Errorf("failed to marshal payload: %w", err))
+			continue
+		}
+		for _, hook := range hooks {
+			if !subscribed(hook, eventType) {
+				continue
+			}
+			if !d.acquire(ctx) {
+				return
+			}
+			go func(hook models.Webhook) {
+				defer d.release()
+				d.Deliver(ctx, hook, payload.ID, eventType, body)
+			}(hook)
+		}
+	}
+}
+
+// 送信の枠を確保する。ctxが終了した場合はfalseを返す
+func (d *Dispatcher) acquire(ctx context.Context) bool {
+	d.once.Do(func() {
+		d.slots = make(chan struct{}, max(d.Workers, 1))
+	})
+	select {
+	case d.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (d *Dispatcher) release() {
+	<-d.slots
+}
+
+// Webhookがイベントを購読しているか判定（未指定は全イベント）
+func subscribed(hook models.Webhook, eventType string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, ev := range hook.Events {
+		if ev == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Deliver 通知を送信し、失敗した場合は指数バックオフで再送する
+// 再送上限に達した、または再送しても成功しない応答の場合はdead letterに記録する
+func (d *Dispatcher) Deliver(ctx context.Context, hook models.Webhook, deliveryID, eventType string, body []byte) error {
+	var lastErr error
+	attempts := 0
+	for attempts < d.MaxAttempts {
+		if attempts > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d.backoff(attempts)):
+			}
+		}
+		attempts++
+
+		retry, err := d.send(ctx, hook, deliveryID, eventType, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		log.Printf("webhook %d delivery %s failed (attempt %d): %v", hook.ID, deliveryID, attempts, err)
+		if !retry {
+			break
+		}
+	}
+
+	dl := models.DeadLetter{
+		WebhookID: hook.ID,
+		Event:     eventType,
+		Payload:   string(body),
+		Attempts:  attempts,
+		LastError: lastErr.Error(),
+	}
+	if err := d.Store.SaveDeadLetter(dl); err != nil {
+		log.Println(fmt.Errorf("failed to save dead letter: %w", err))
+	}
+	return lastErr
+}
+
+// n回目の再送までの待機時間
+func (d *Dispatcher) backoff(n int) time.Duration {
+	delay := d.BaseDelay << (n - 1)
+	if delay > d.MaxDelay || delay <= 0 {
+		delay = d.MaxDelay
+	}
+	return delay
+}
+
+// 1回送信する。再送すべき失敗の場合はretryをtrueで返す
+func (d *Dispatcher) send(ctx context.Context, hook models.Webhook, deliveryID, eventType string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bb_api-webhook/1.0")
+	req.Header.Set("X-Webhook-Id", deliveryID)
+	req.Header.Set("X-Webhook-Event", eventType)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(hook.Secret, timestamp, body))
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		//内部アドレスへの送信は再送しない
+		return !errors.Is(err, ErrBlockedAddress), fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry = res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("unexpected status: %d", res.StatusCode)
+}
+
+// Sign 「タイムスタンプ.本文」をHMAC-SHA256で署名し16進文字列で返す
+// 受信側は同じ計算結果とX-Webhook-Signatureを比較して検証する
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"baseball_report/internal/feed"
+	"strings"
+)
+
+// 通知する試合イベント
+const (
+	EventGameStart    = "game_start"
+	EventScoreChange  = "score_change"
+	EventInningChange = "inning_change"
+	EventGameEnd      = "game_end"
+	EventGameCancel   = "game_cancel"
+)
+
+// Events 通知可能なイベントの一覧
+var Events = []string{EventGameStart, EventScoreChange, EventInningChange, EventGameEnd, EventGameCancel}
+
+// 試合終了・中止を表すイニング表記
+const (
+	inningGameEnd    = "試合終了"
+	inningGameCancel = "試合中止"
+)
+
+// IsEvent 定義済みのイベント名か判定
+func IsEvent(name string) bool {
+	for _, ev := range Events {
+		if ev == name {
+			return true
+		}
+	}
+	return false
+}
+
+// 試合開始前のイニング表記か判定（scoresテーブルの初期値を含む）
+func notStarted(inning string) bool {
+	return inning == "" || inning == "0回表" || strings.HasPrefix(inning, "試合前")
+}
+
+// 試合が終わっている（終了・中止）か判定
+func finished(inning string) bool {
+	return inning == inningGameEnd || inning == inningGameCancel
+}
+
+// スコアの比較用に空文字を0とみなす
+func normalizeScore(score string) string {
+	if score == "" {
+		return "0"
+	}
+	return score
+}
+
+// Classify 試合進捗の変化を通知イベントに分類する
+func Classify(ev feed.ScoreEvent) []string {
+	prev, next := ev.Previous, ev.Score
+	var events []string
+
+	if notStarted(prev.Inning) && !notStarted(next.Inning) && !finished(next.Inning) {
+		events = append(events, EventGameStart)
+	}
+	if normalizeScore(prev.HomeScore) != normalizeScore(next.HomeScore) ||
+		normalizeScore(prev.AwayScore) != normalizeScore(next.AwayScore) {
+		events = append(events, EventScoreChange)
+	}
+	if prev.Inning != next.Inning && !notStarted(prev.Inning) && !notStarted(next.Inning) && !finished(prev.Inning) && !finished(next.Inning) {
+		events = append(events, EventInningChange)
+	}
+	if prev.Inning != next.Inning && next.Inning == inningGameEnd {
+		events = append(events, EventGameEnd)
+	}
+	if prev.Inning != next.Inning && next.Inning == inningGameCancel {
+		events = append(events, EventGameCancel)
+	}
+	return events
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	db "baseball_report/internal/config"
+	"baseball_report/internal/models"
+	"baseball_report/internal/repository"
+	"fmt"
+)
+
+// DBStore webhooks・webhook_dead_lettersテーブルを使用するStore
+type DBStore struct {
+	Connect db.DBHandler
+	Repo    *repository.DefaultRepository
+}
+
+// NewDBStore 既定のDB接続を使用するStoreを生成
+func NewDBStore() *DBStore {
+	return &DBStore{Connect: &db.DBService{}, Repo: &repository.DefaultRepository{}}
+}
+
+func (s *DBStore) ActiveWebhooks() ([]models.Webhook, error) {
+	conn, err := s.Connect.ConnectOnly()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check to connect database: %w", err)
+	}
+	defer conn.Close()
+	return s.Repo.GetWebhooks(conn)
+}
+
+func (s *DBStore) SaveDeadLetter(dl models.DeadLetter) error {
+	conn, err := s.Connect.ConnectOnly()
+	if err != nil {
+		return fmt.Errorf("failed to check to connect database: %w", err)
+	}
+	defer conn.Close()
+	_, err = s.Repo.InsertDeadLetter(conn, dl)
+	return err
+}
@@ -0,0 +1,353 @@
+package webhook
+
+import (
+	"baseball_report/internal/feed"
+	"baseball_report/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStore struct {
+	mu          sync.Mutex
+	hooks       []models.Webhook
+	err         error
+	deadLetters []models.DeadLetter
+}
+
+func (m *mockStore) ActiveWebhooks() ([]models.Webhook, error) {
+	return m.hooks, m.err
+}
+
+func (m *mockStore) SaveDeadLetter(dl models.DeadLetter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deadLetters = append(m.deadLetters, dl)
+	return nil
+}
+
+func (m *mockStore) letters() []models.DeadLetter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.DeadLetter(nil), m.deadLetters...)
+}
+
+// テスト用に待機時間を短くしたDispatcher
+// 送信先がhttptestのローカルのサーバのため、内部アドレスを制限しないクライアントを使う
+func newTestDispatcher(store Store) *Dispatcher {
+	d := NewDispatcher(store)
+	d.Client = &http.Client{Timeout: time.Second}
+	d.BaseDelay = time.Millisecond
+	d.MaxDelay = 5 * time.Millisecond
+	return d
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		prev     feed.ScoreState
+		next     feed.ScoreState
+		expected []string
+	}{
+		{"Game start", feed.ScoreState{Inning: "試合前"}, feed.ScoreState{Inning: "1回表", HomeScore: "0", AwayScore: "0"}, []string{EventGameStart}},
+		{"Game start from default row", feed.ScoreState{Inning: "0回表", HomeScore: "0", AwayScore: "0"}, feed.ScoreState{Inning: "1回表", HomeScore: "0", AwayScore: "0"}, []string{EventGameStart}},
+		{"Score change", feed.ScoreState{Inning: "3回裏", HomeScore: "0", AwayScore: "1"}, feed.ScoreState{Inning: "3回裏", HomeScore: "2", AwayScore: "1"}, []string{EventScoreChange}},
+		{"Inning change", feed.ScoreState{Inning: "3回裏", HomeScore: "0"}, feed.ScoreState{Inning: "4回表", HomeScore: "0"}, []string{EventInningChange}},
+		{"Inning and score change", feed.ScoreState{Inning: "3回裏", HomeScore: "0"}, feed.ScoreState{Inning: "4回表", HomeScore: "1"}, []string{EventScoreChange, EventInningChange}},
+		{"Game end", feed.ScoreState{Inning: "9回裏", HomeScore: "3", AwayScore: "2"}, feed.ScoreState{Inning: "試合終了", HomeScore: "3", AwayScore: "2"}, []string{EventGameEnd}},
+		{"Game cancel", feed.ScoreState{Inning: "試合前"}, feed.ScoreState{Inning: "試合中止"}, []string{EventGameCancel}},
+		{"Batter only", feed.ScoreState{Inning: "5回表", Batter: "山田"}, feed.ScoreState{Inning: "5回表", Batter: "佐藤"}, nil},
+		{"Empty score becomes zero", feed.ScoreState{Inning: "1回表"}, feed.ScoreState{Inning: "1回表", HomeScore: "0", AwayScore: "0"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Classify(feed.ScoreEvent{Previous: tt.prev, Score: tt.next}))
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256("secret", `1700000000.{"a":1}`)
+	assert.Equal(t, "49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686", Sign("secret", "1700000000", []byte(`{"a":1}`)))
+	assert.NotEqual(t, Sign("secret", "1700000000", []byte(`{"a":1}`)), Sign("other", "1700000000", []byte(`{"a":1}`)))
+}
+
+func TestDeliver(t *testing.T) {
+	body := []byte(`{"type":"game_end"}`)
+
+	t.Run("Success with signature", func(t *testing.T) {
+		var header http.Header
+		var received []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Clone()
+			received, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		store := &mockStore{}
+		d := newTestDispatcher(store)
+		err := d.Deliver(context.Background(), models.Webhook{ID: 1, URL: server.URL, Secret: "s3cr3t"}, "10-game_end", EventGameEnd, body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, body, received)
+		assert.Equal(t, "game_end", header.Get("X-Webhook-Event"))
+		assert.Equal(t, "10-game_end", header.Get("X-Webhook-Id"))
+		assert.Equal(t, "sha256="+Sign("s3cr3t", header.Get("X-Webhook-Timestamp"), body), header.Get("X-Webhook-Signature"))
+		assert.Empty(t, store.letters())
+	})
+
+	t.Run("Retry on server error", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&count, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		store := &mockStore{}
+		err := newTestDispatcher(store).Deliver(context.Background(), models.Webhook{ID: 1, URL: server.URL}, "1-game_end", EventGameEnd, body)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+		assert.Empty(t, store.letters())
+	})
+
+	t.Run("Dead letter after max attempts", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&count, 1)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		store := &mockStore{}
+		err := newTestDispatcher(store).Deliver(context.Background(), models.Webhook{ID: 2, URL: server.URL}, "1-game_end", EventGameEnd, body)
+		assert.Error(t, err)
+		assert.Equal(t, int32(5), atomic.LoadInt32(&count))
+
+		letters := store.letters()
+		if assert.Len(t, letters, 1) {
+			assert.Equal(t, 2, letters[0].WebhookID)
+			assert.Equal(t, EventGameEnd, letters[0].Event)
+			assert.Equal(t, string(body), letters[0].Payload)
+			assert.Equal(t, 5, letters[0].Attempts)
+			assert.Contains(t, letters[0].LastError, "429")
+		}
+	})
+
+	t.Run("No retry on client error", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&count, 1)
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer server.Close()
+
+		store := &mockStore{}
+		err := newTestDispatcher(store).Deliver(context.Background(), models.Webhook{ID: 3, URL: server.URL}, "1-game_end", EventGameEnd, body)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+		assert.Len(t, store.letters(), 1)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(&mockStore{})
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 8*time.Second, d.backoff(4))
+	assert.Equal(t, time.Minute, d.backoff(10))
+}
+
+func TestRun(t *testing.T) {
+	received := make(chan Payload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		json.NewDecoder(r.Body).Decode(&p)
+		received <- p
+	}))
+	defer server.Close()
+
+	store := &mockStore{hooks: []models.Webhook{
+		{ID: 1, URL: server.URL, Events: []string{EventGameEnd}},
+	}}
+	b := feed.NewBroker(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	//購読開始前のイベントは通知しない
+	b.Publish(feed.ScoreEvent{MatchID: 9, Previous: feed.ScoreState{Inning: "9回裏"}, Score: feed.ScoreState{Inning: "試合終了"}})
+
+	go newTestDispatcher(store).Run(ctx, b)
+	time.Sleep(20 * time.Millisecond)
+
+	// 購読していないイベント（得点）は通知しない
+	b.Publish(feed.ScoreEvent{MatchID: 1, Previous: feed.ScoreState{Inning: "9回表", HomeScore: "1"}, Score: feed.ScoreState{Inning: "9回表", HomeScore: "2"}})
+	ev := b.Publish(feed.ScoreEvent{MatchID: 1, League: "セ・リーグ", Home: "巨人", Away: "阪神",
+		Previous: feed.ScoreState{Inning: "9回表", HomeScore: "2"}, Score: feed.ScoreState{Inning: "試合終了", HomeScore: "2"}})
+
+	select {
+	case p := <-received:
+		assert.Equal(t, EventGameEnd, p.Type)
+		assert.Equal(t, 1, p.Match.ID)
+		assert.Equal(t, "巨人", p.Match.Home)
+		assert.Equal(t, "試合終了", p.Score.Inning)
+		assert.Contains(t, p.ID, "-game_end")
+		assert.Equal(t, ev.Time.Unix(), p.OccurredAt.Unix())
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	select {
+	case p := <-received:
+		t.Fatalf("unexpected delivery: %+v", p)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// 購読が追いつかず履歴から消えた変化は件数を記録し、保持中の変化から再開する
+func TestResume_Skipped(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	b := feed.NewBroker(2)
+	ev1 := b.Publish(feed.ScoreEvent{MatchID: 1})
+	b.Publish(feed.ScoreEvent{MatchID: 2})
+	ev3 := b.Publish(feed.ScoreEvent{MatchID: 3})
+	ev4 := b.Publish(feed.ScoreEvent{MatchID: 4})
+	d := newTestDispatcher(&mockStore{})
+	before := SkippedEvents.Value()
+
+	sub, missed := d.resume(b, ev1.ID)
+	b.Unsubscribe(sub)
+	assert.Equal(t, []feed.ScoreEvent{ev3, ev4}, missed)
+	assert.Equal(t, before+1, SkippedEvents.Value())
+	assert.Contains(t, buf.String(), "webhook dispatcher skipped 1 events")
+
+	//取りこぼしがない場合は記録しない
+	sub, missed = d.resume(b, ev3.ID)
+	b.Unsubscribe(sub)
+	assert.Equal(t, []feed.ScoreEvent{ev4}, missed)
+	assert.Equal(t, before+1, SkippedEvents.Value())
+}
+
+func TestHandle_StoreError(t *testing.T) {
+	store := &mockStore{err: errors.New("DB接続エラー")}
+	d := newTestDispatcher(store)
+
+	// 取得に失敗しても panic しない
+	d.Handle(context.Background(), feed.ScoreEvent{Previous: feed.ScoreState{Inning: "9回裏"}, Score: feed.ScoreState{Inning: "試合終了"}})
+	assert.Empty(t, store.letters())
+}
+
+// 同時に送信するのはWorkersまで
+func TestHandle_Workers(t *testing.T) {
+	var running, peak int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+	}))
+	defer server.Close()
+
+	var hooks []models.Webhook
+	for i := 1; i <= 5; i++ {
+		hooks = append(hooks, models.Webhook{ID: i, URL: server.URL})
+	}
+	d := newTestDispatcher(&mockStore{hooks: hooks})
+	d.Workers = 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Handle(ctx, feed.ScoreEvent{Previous: feed.ScoreState{Inning: "9回裏"}, Score: feed.ScoreState{Inning: "試合終了"}})
+		close(done)
+	}()
+
+	//枠が空くまで残りの送信は待つ
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&running) == 2 }, time.Second, 5*time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Handle did not wait for a free worker")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	cancel()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
+
+func TestCheckURL(t *testing.T) {
+	lookupNetIP = func(ctx context.Context, network, host string) ([]netip.Addr, error) {
+		switch host {
+		case "hooks.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "internal.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	defer func() { lookupNetIP = net.DefaultResolver.LookupNetIP }()
+
+	assert.NoError(t, CheckURL(context.Background(), "https://hooks.example.com/bb"))
+	for _, u := range []string{
+		"http://127.0.0.1:9000/hook",
+		"http://localhost.:9000/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.0.1]/hook",
+		"https://internal.example.com/hook",
+	} {
+		err := CheckURL(context.Background(), u)
+		if u == "http://localhost.:9000/hook" {
+			//名前解決できない場合もエラー
+			assert.Error(t, err, u)
+			continue
+		}
+		assert.ErrorIs(t, err, ErrBlockedAddress, u)
+	}
+}
+
+// 既定のクライアントは名前解決の結果が内部アドレスの場合も接続せず、再送しない
+func TestDeliver_BlockedAddress(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+	}))
+	defer server.Close()
+
+	store := &mockStore{}
+	d := NewDispatcher(store)
+	d.BaseDelay = time.Millisecond
+	err := d.Deliver(context.Background(), models.Webhook{ID: 1, URL: server.URL}, "1-game_end", EventGameEnd, []byte(`{}`))
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count))
+	if letters := store.letters(); assert.Len(t, letters, 1) {
+		assert.Equal(t, 1, letters[0].Attempts)
+	}
+}
@@ -4,25 +4,21 @@ import (
 	"fmt"
 )
 
-func ConvertToJSON(data []map[string]interface{}, headerKey string) (map[string][]map[string]interface{}, error) {
+// dataをheaderKeyが返す値ごとにまとめる（順序は元のスライスの順）
+func ConvertToJSON[T any](data []T, headerKey func(T) string) (map[string][]T, error) {
 	if len(data) == 0 {
 		return nil, fmt.Errorf("data is empty")
 	}
 
-	result := make(map[string][]map[string]interface{})
+	result := make(map[string][]T)
 
 	for _, row := range data {
-		// Check if headerKey exists
-		if headerValue, ok := row[headerKey]; ok {
-			headerStr, valid := headerValue.(string)
-			if !valid {
-				return nil, fmt.Errorf("header key '%s' is not a string", headerKey)
-			}
-			// Append the row to the appropriate group
-			result[headerStr] = append(result[headerStr], row)
-		} else {
-			return nil, fmt.Errorf("header key '%s' not found in row", headerKey)
+		header := headerKey(row)
+		if header == "" {
+			return nil, fmt.Errorf("header key is empty: %+v", row)
 		}
+		// Append the row to the appropriate group
+		result[header] = append(result[header], row)
 	}
 
 	return result, nil
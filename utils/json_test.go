@@ -1,159 +1,69 @@
 package utils
 
 import (
+	"baseball_report/internal/models"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func byLeague(m models.Match) string { return m.League }
+
 func TestConvertToJSON(t *testing.T) {
 	t.Run("1league2games", func(t *testing.T) {
 		//引数を定義
-		testdata := []map[string]interface{}{
-			{
-				"id":        1,
-				"date":      "2025-03-28",
-				"home":      "Yankees",
-				"away":      "Red Sox",
-				"league":    "セ・リーグ",
-				"stadium":   "Yankee Stadium",
-				"starttime": "19:00",
-			},
-			{
-				"id":        2,
-				"date":      "2025-03-28",
-				"home":      "Dodgers",
-				"away":      "Giants",
-				"league":    "セ・リーグ",
-				"stadium":   "Dodger Stadium",
-				"starttime": "18:30",
-			},
+		testdata := []models.Match{
+			{ID: 1, Date: "2025-03-28", Home: "Yankees", Away: "Red Sox", League: "セ・リーグ", Stadium: "Yankee Stadium", StartTime: "19:00"},
+			{ID: 2, Date: "2025-03-28", Home: "Dodgers", Away: "Giants", League: "セ・リーグ", Stadium: "Dodger Stadium", StartTime: "18:30"},
 		}
 
 		// 期待値を設定
-		expected := map[string][]map[string]interface{}{
+		expected := map[string][]models.Match{
 			"セ・リーグ": {
-				{
-					"id":        1,
-					"date":      "2025-03-28",
-					"home":      "Yankees",
-					"away":      "Red Sox",
-					"league":    "セ・リーグ",
-					"stadium":   "Yankee Stadium",
-					"starttime": "19:00",
-				},
-				{
-					"id":        2,
-					"date":      "2025-03-28",
-					"home":      "Dodgers",
-					"away":      "Giants",
-					"league":    "セ・リーグ",
-					"stadium":   "Dodger Stadium",
-					"starttime": "18:30",
-				},
+				{ID: 1, Date: "2025-03-28", Home: "Yankees", Away: "Red Sox", League: "セ・リーグ", Stadium: "Yankee Stadium", StartTime: "19:00"},
+				{ID: 2, Date: "2025-03-28", Home: "Dodgers", Away: "Giants", League: "セ・リーグ", Stadium: "Dodger Stadium", StartTime: "18:30"},
 			},
 		}
 
 		//テスト実施
-		result, err := ConvertToJSON(testdata, "league")
+		result, err := ConvertToJSON(testdata, byLeague)
 		assert.NoError(t, err)
 
-		//期待値と結果が一致していること（フィールドの順序は問わない）
+		//期待値と結果が一致していること
 		assert.Equal(t, expected, result)
 	})
 	t.Run("2leagues4games", func(t *testing.T) {
 		// 引数を定義
-		testdata := []map[string]interface{}{
+		testdata := []models.Match{
 			// セ・リーグの試合
-			{
-				"id":        1,
-				"date":      "2025-03-28",
-				"home":      "Yankees",
-				"away":      "Red Sox",
-				"league":    "セ・リーグ",
-				"stadium":   "Yankee Stadium",
-				"starttime": "19:00",
-			},
-			{
-				"id":        2,
-				"date":      "2025-03-28",
-				"home":      "Dodgers",
-				"away":      "Giants",
-				"league":    "セ・リーグ",
-				"stadium":   "Dodger Stadium",
-				"starttime": "18:30",
-			},
+			{ID: 1, Date: "2025-03-28", Home: "Yankees", Away: "Red Sox", League: "セ・リーグ", Stadium: "Yankee Stadium", StartTime: "19:00"},
+			{ID: 2, Date: "2025-03-28", Home: "Dodgers", Away: "Giants", League: "セ・リーグ", Stadium: "Dodger Stadium", StartTime: "18:30"},
 			// パ・リーグの試合
-			{
-				"id":        3,
-				"date":      "2025-03-29",
-				"home":      "SoftBank Hawks",
-				"away":      "Lions",
-				"league":    "パ・リーグ",
-				"stadium":   "PayPay Dome",
-				"starttime": "14:00",
-			},
-			{
-				"id":        4,
-				"date":      "2025-03-29",
-				"home":      "Eagles",
-				"away":      "Marines",
-				"league":    "パ・リーグ",
-				"stadium":   "Rakuten Seimei Park",
-				"starttime": "13:00",
-			},
+			{ID: 3, Date: "2025-03-29", Home: "SoftBank Hawks", Away: "Lions", League: "パ・リーグ", Stadium: "PayPay Dome", StartTime: "14:00"},
+			{ID: 4, Date: "2025-03-29", Home: "Eagles", Away: "Marines", League: "パ・リーグ", Stadium: "Rakuten Seimei Park", StartTime: "13:00"},
 		}
 
 		// 期待値を設定
-		expected := map[string][]map[string]interface{}{
-			"セ・リーグ": {
-				{
-					"id":        1,
-					"date":      "2025-03-28",
-					"home":      "Yankees",
-					"away":      "Red Sox",
-					"league":    "セ・リーグ",
-					"stadium":   "Yankee Stadium",
-					"starttime": "19:00",
-				},
-				{
-					"id":        2,
-					"date":      "2025-03-28",
-					"home":      "Dodgers",
-					"away":      "Giants",
-					"league":    "セ・リーグ",
-					"stadium":   "Dodger Stadium",
-					"starttime": "18:30",
-				},
-			},
-			"パ・リーグ": {
-				{
-					"id":        3,
-					"date":      "2025-03-29",
-					"home":      "SoftBank Hawks",
-					"away":      "Lions",
-					"league":    "パ・リーグ",
-					"stadium":   "PayPay Dome",
-					"starttime": "14:00",
-				},
-				{
-					"id":        4,
-					"date":      "2025-03-29",
-					"home":      "Eagles",
-					"away":      "Marines",
-					"league":    "パ・リーグ",
-					"stadium":   "Rakuten Seimei Park",
-					"starttime": "13:00",
-				},
-			},
+		expected := map[string][]models.Match{
+			"セ・リーグ": {testdata[0], testdata[1]},
+			"パ・リーグ": {testdata[2], testdata[3]},
 		}
 
 		// テスト実施
-		result, err := ConvertToJSON(testdata, "league")
+		result, err := ConvertToJSON(testdata, byLeague)
 		assert.NoError(t, err)
 
-		// 期待値と結果が一致していること（フィールドの順序は問わない）
+		// 期待値と結果が一致していること
 		assert.Equal(t, expected, result)
 	})
-
+	t.Run("Empty data", func(t *testing.T) {
+		result, err := ConvertToJSON([]models.Match{}, byLeague)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+	t.Run("Empty header", func(t *testing.T) {
+		result, err := ConvertToJSON([]models.Match{{ID: 1}}, byLeague)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
 }
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// 同一ホストへのリクエスト間隔の既定値
+const defaultHostInterval = 1 * time.Second
+
+// DefaultHostLimiter スクレイピング全体で共有するホストごとの流量制限
+// 間隔は環境変数SCRAPE_HOST_INTERVAL（例: 500ms, 2s）で変更できる
+var DefaultHostLimiter = NewHostLimiter(durationFromEnv("SCRAPE_HOST_INTERVAL", defaultHostInterval))
+
+// HostLimiter ホストごとにリクエストの間隔を空ける
+type HostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+func NewHostLimiter(interval time.Duration) *HostLimiter {
+	return &HostLimiter{interval: interval, next: map[string]time.Time{}}
+}
+
+// rawURLのホストへのリクエストが許可されるまで待つ
+// 待機中にctxが終了した場合はctxのエラーを返す
+func (l *HostLimiter) Wait(ctx context.Context, rawURL string) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	// 次に空いている時刻を予約する
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := slot.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// 環境変数から時間を読み取る（未設定・不正な値の場合は既定値）
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Printf("invalid %s=%q, using %s", key, v, def)
+		return def
+	}
+	return d
+}
@@ -1,10 +1,15 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -12,24 +17,173 @@ import (
 // URLHandler インターフェースで関数を抽象化
 type URLHandler interface {
 	GetURL(url string) (*http.Response, error)
+	GetURLContext(ctx context.Context, url string) (*http.Response, error)
 	GetBody(res *http.Response) (*goquery.Document, error)
 }
 
+// 既定のUser-Agent
+const DefaultUserAgent = "baseball_report/1.0"
+
+// 既定値（URLServiceの各項目がゼロ値の場合に使用）
+const (
+	defaultTimeout    = 15 * time.Second
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+)
+
+var defaultClient = &http.Client{Timeout: defaultTimeout}
+
 // URLService デフォルトの実装
-type URLService struct{}
+// 各項目がゼロ値の場合は既定値を使う。Limiterが未設定の場合はDefaultHostLimiterで流量を制限する
+type URLService struct {
+	Client     *http.Client
+	Transport  http.RoundTripper // Clientが未設定の場合に使う通信処理（キャッシュなど）
+	Limiter    *HostLimiter
+	UserAgent  string
+	MaxRetries int           // 再試行の回数（負の値で再試行しない）
+	BaseDelay  time.Duration // 1回目の再試行までの待ち時間（以降は倍々）
+	MaxDelay   time.Duration // 待ち時間の上限（Retry-Afterがこれを超える場合は再試行しない）
+}
+
+// HTTPStatusError 2xx以外のレスポンス
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.URL)
+}
+
+// 再試行の対象か（5xxと429）
+func (e *HTTPStatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
 
 // サイトのURLからHTTPレスポンスを取得
 func (u *URLService) GetURL(url string) (*http.Response, error) {
+	return u.GetURLContext(context.Background(), url)
+}
+
+// サイトのURLからHTTPレスポンスを取得（ctxの終了で中断）
+// 通信エラー・5xx・429は指数バックオフ（ジッター付き）で再試行し、Retry-Afterがあればその時間待つ
+// 2xx以外で終わった場合は*HTTPStatusErrorを返す
+func (u *URLService) GetURLContext(ctx context.Context, url string) (*http.Response, error) {
 	//URLが空の場合はエラーを返す
 	if url == "" {
 		return nil, fmt.Errorf("response body is empty")
 	}
 
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get URL: %w", err)
+	client, limiter, userAgent := u.Client, u.Limiter, u.UserAgent
+	if client == nil {
+		client = defaultClient
+		if u.Transport != nil {
+			client = &http.Client{Timeout: defaultTimeout, Transport: u.Transport}
+		}
+	}
+	if limiter == nil {
+		limiter = DefaultHostLimiter
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	maxRetries, baseDelay, maxDelay := u.MaxRetries, u.BaseDelay, u.MaxDelay
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	if baseDelay <= 0 {
+		baseDelay = defaultBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := limiter.Wait(ctx, url); err != nil {
+			return nil, fmt.Errorf("failed to wait rate limit: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept-Language", "ja")
+
+		var lastErr error
+		var retryAfter time.Duration
+		res, err := client.Do(req)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to get URL: %w", ctx.Err())
+			}
+			lastErr = fmt.Errorf("failed to get URL: %w", err)
+		case res.StatusCode >= 200 && res.StatusCode < 300:
+			return res, nil
+		default:
+			// エラーページは読み捨てる
+			io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+			statusErr := &HTTPStatusError{URL: url, StatusCode: res.StatusCode, RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now())}
+			if !statusErr.Temporary() {
+				return nil, statusErr
+			}
+			lastErr = statusErr
+			retryAfter = statusErr.RetryAfter
+		}
+
+		if attempt >= maxRetries {
+			return nil, lastErr
+		}
+		delay := backoff(attempt, baseDelay, maxDelay)
+		if retryAfter > 0 {
+			if retryAfter > maxDelay {
+				return nil, lastErr
+			}
+			delay = retryAfter
+		}
+		log.Printf("retrying %s in %s (attempt %d): %v", url, delay, attempt+1, lastErr)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("failed to get URL: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt回目の再試行までの待ち時間（base * 2^attempt、上限max）の半分〜全体をランダムに選ぶ
+func backoff(attempt int, base, maxDelay time.Duration) time.Duration {
+	d := base << attempt
+	if d > maxDelay || d <= 0 {
+		d = maxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// Retry-Afterヘッダー（秒数またはHTTP日付）を待ち時間に変換する
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil {
+		if sec < 0 {
+			return 0
+		}
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
 	}
-	return res, nil
+	return 0
 }
 
 func (u *URLService) GetBody(res *http.Response) (*goquery.Document, error) {
@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/assert"
@@ -27,7 +30,7 @@ const mockHTML = `
 
 // GetURL のテスト
 func TestGetURL(t *testing.T) {
-	scraper := URLService{}
+	scraper := URLService{Limiter: NewHostLimiter(0), BaseDelay: time.Millisecond}
 
 	// モックサーバーを作成して、HTTPレスポンスをシミュレート
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -56,6 +59,138 @@ func TestGetURL(t *testing.T) {
 	})
 }
 
+// 再試行・タイムアウト・ステータスコードのテスト
+func TestGetURLContext(t *testing.T) {
+	newScraper := func() *URLService {
+		return &URLService{Limiter: NewHostLimiter(0), MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	}
+
+	t.Run("Retry on 5xx then success", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			//User-Agentが設定されている
+			assert.Equal(t, DefaultUserAgent, r.Header.Get("User-Agent"))
+			if atomic.AddInt32(&count, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("メンテナンス中"))
+				return
+			}
+			w.Write([]byte("<html></html>"))
+		}))
+		defer server.Close()
+
+		res, err := newScraper().GetURLContext(context.Background(), server.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+		res.Body.Close()
+	})
+
+	t.Run("Give up after max retries", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&count, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		_, err := newScraper().GetURLContext(context.Background(), server.URL)
+		var statusErr *HTTPStatusError
+		if assert.ErrorAs(t, err, &statusErr) {
+			assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+		}
+		//初回 + 再試行3回
+		assert.Equal(t, int32(4), atomic.LoadInt32(&count))
+	})
+
+	t.Run("No retry on 4xx", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&count, 1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := newScraper().GetURLContext(context.Background(), server.URL)
+		var statusErr *HTTPStatusError
+		if assert.ErrorAs(t, err, &statusErr) {
+			assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+	})
+
+	t.Run("Honor Retry-After on 429", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&count, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		res, err := newScraper().GetURLContext(context.Background(), server.URL)
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+	})
+
+	t.Run("Retry-After longer than max delay", func(t *testing.T) {
+		var count int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&count, 1)
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		_, err := newScraper().GetURLContext(context.Background(), server.URL)
+		var statusErr *HTTPStatusError
+		if assert.ErrorAs(t, err, &statusErr) {
+			assert.Equal(t, 120*time.Second, statusErr.RetryAfter)
+		}
+		//待たずに諦める
+		assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := newScraper().GetURLContext(ctx, server.URL)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 4, 6, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("Sun, 06 Apr 2025 12:00:30 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Sun, 06 Apr 2025 11:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoff(attempt, 100*time.Millisecond, time.Second)
+		want := min(100*time.Millisecond<<attempt, time.Second)
+		//上限の半分〜上限の範囲
+		assert.GreaterOrEqual(t, d, want/2)
+		assert.LessOrEqual(t, d, want)
+	}
+}
+
 type badReader struct{}
 
 func (b *badReader) Read(p []byte) (n int, err error) {
@@ -188,3 +323,36 @@ func TestGetText(t *testing.T) {
 		assert.Equal(t, text, "")
 	})
 }
+
+// 同一ホストへのリクエストは間隔を空ける
+func TestHostLimiter(t *testing.T) {
+	t.Run("Same host waits", func(t *testing.T) {
+		limiter := NewHostLimiter(50 * time.Millisecond)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, limiter.Wait(context.Background(), "https://baseball.yahoo.co.jp/npb/game/1/score"))
+		}
+		//1回目は即時、2回目・3回目はそれぞれ間隔を空ける
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("Different hosts do not wait", func(t *testing.T) {
+		limiter := NewHostLimiter(time.Second)
+
+		start := time.Now()
+		assert.NoError(t, limiter.Wait(context.Background(), "https://a.example.com/1"))
+		assert.NoError(t, limiter.Wait(context.Background(), "https://b.example.com/1"))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		limiter := NewHostLimiter(time.Second)
+		assert.NoError(t, limiter.Wait(context.Background(), "https://a.example.com/1"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := limiter.Wait(ctx, "https://a.example.com/2")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}